@@ -3,19 +3,22 @@ package bootstrap
 
 // 引入项目内部及外部的必要依赖
 import (
-	"RESTful-API/internal/constants" // 常量定义
-	"RESTful-API/internal/model"     // 数据库 ORM 模型
-	"RESTful-API/utils/config"       // 配置管理
-	"RESTful-API/utils/logs"         // 日志工具
-	"fmt"                            // 标准库: 格式化输入输出
-	"gorm.io/driver/mysql"           // GORM 的 MySQL 适配器
-	"gorm.io/gorm"                   // GORM ORM 框架
-	"gorm.io/gorm/logger"            // GORM 日志
-	"log"                            // 标准库: 日志
-	"net/url"                        // 标准库: 处理 URL 相关操作
-	"os"                             // 标准库: 操作系统相关
-	"sync"                           // 标准库: 并发同步
-	"time"                           // 标准库: 时间处理
+	_ "RESTful-API/internal/chain/evm"    // 注册 EVM 链客户端
+	_ "RESTful-API/internal/chain/solana" // 注册 Solana 链客户端
+	_ "RESTful-API/internal/chain/ton"    // 注册 TON 链客户端
+	"RESTful-API/internal/constants"      // 常量定义
+	"RESTful-API/internal/metrics"        // 监控指标
+	"RESTful-API/internal/model"          // 数据库 ORM 模型
+	"RESTful-API/internal/tracing"        // 链路追踪
+	"RESTful-API/utils/config"            // 配置管理
+	"RESTful-API/utils/logs"              // 日志工具
+	"fmt"                                 // 标准库: 格式化输入输出
+	"gorm.io/driver/mysql"                // GORM 的 MySQL 适配器
+	"gorm.io/gorm"                        // GORM ORM 框架
+	"net/url"                             // 标准库: 处理 URL 相关操作
+	"strings"                             // 标准库: 字符串处理
+	"sync"                                // 标准库: 并发同步
+	"time"                                // 标准库: 时间处理
 )
 
 // 定义 `once` 变量，确保 `init()` 只执行一次（单例模式）
@@ -27,6 +30,7 @@ var (
 func init() {
 	once.Do(func() { // `sync.Once` 确保 `initDB()` 仅运行一次
 		initLog()
+		initTrace()
 		initDB()
 	})
 }
@@ -36,6 +40,13 @@ func initLog() {
 	logs.InitLog()
 }
 
+// 初始化链路追踪，失败时只记录日志，不影响服务启动
+func initTrace() {
+	if err := tracing.Init(); err != nil {
+		logs.Error("init tracing error: %v", err)
+	}
+}
+
 // 初始化数据库连接
 func initDB() {
 	// 从配置文件读取数据库启用状态，如果 `db.enable` 为 false，则直接返回
@@ -89,19 +100,9 @@ func initDB() {
 			dsn = dsn + "&loc=" + url.QueryEscape(timezone)
 		}
 
-		// 定义 GORM 日志对象
-		var newLogger logger.Interface
-		// 如果启用 debug 模式，则启用 SQL 语句日志
-		if debug {
-			newLogger = logger.New(
-				log.New(os.Stdout, "\r\n", log.LstdFlags), // 日志输出到标准输出（控制台）
-				logger.Config{
-					SlowThreshold: 200 * time.Millisecond, // 慢查询阈值（200ms）
-					LogLevel:      logger.Info,            // 日志级别
-					Colorful:      true,                   // 彩色日志
-				},
-			)
-		}
+		// 定义 GORM 日志对象，SQL 日志统一写入 logs，并带上请求的 request_id
+		// 开启 debug 模式时输出全部 SQL，否则只输出慢查询（200ms）和错误
+		newLogger := logs.NewGormLogger(debug, 200*time.Millisecond)
 
 		// 连接数据库，使用 GORM + MySQL 驱动
 		db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: newLogger})
@@ -115,6 +116,18 @@ func initDB() {
 			panic(err)
 		}
 
+		// 注册监控指标：每次 gorm 操作的耗时和连接池状态
+		if err = db.Use(metrics.NewGormPlugin(alias)); err != nil {
+			panic(err)
+		}
+		if err = metrics.RegisterDB(alias, sqlDB); err != nil {
+			panic(err)
+		}
+		// 链路追踪：每次 gorm 操作创建一个 span
+		if err = db.Use(tracing.NewGormPlugin(alias)); err != nil {
+			panic(err)
+		}
+
 		// 配置数据库连接池参数
 		sqlDB.SetConnMaxLifetime(maxLifeDuration) // 连接最大存活时间
 		sqlDB.SetMaxOpenConns(maxConn)            // 最大连接数
@@ -123,9 +136,10 @@ func initDB() {
 		// 将数据库连接存入全局映射 `OrmMap`
 		model.OrmMap[alias] = db
 
-		// 记录数据库启动完成日志
-		logs.Info("DB Finished Start %v", dsn)
-		fmt.Println("Start dsn", dsn)
+		// 记录数据库启动完成日志，DSN 中的密码替换掉，日志可能发送到远程收集端
+		maskedDSN := strings.Replace(dsn, user+":"+pwd+"@", user+":******@", 1)
+		logs.Info("DB Finished Start %v", maskedDSN)
+		fmt.Println("Start dsn", maskedDSN)
 
 		// 数据库自动迁移
 		db.AutoMigrate()
@@ -1,7 +1,14 @@
 package cmd
 
-import "fmt"
+import (
+	"RESTful-API/internal/tracing"
+	"RESTful-API/utils/logs"
+	"fmt"
+)
 
 func Clean() {
 	fmt.Println("============================server end=====================================")
+	// 导出剩余的 span，发送完远程日志队列中剩余的日志
+	tracing.Shutdown()
+	logs.Close()
 }
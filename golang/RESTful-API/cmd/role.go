@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"RESTful-API/internal/rbac"
+	"RESTful-API/internal/service"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+const roleUsage = `usage:
+  role grant <user_id> <role>   为用户分配角色，可选角色: user/creator/moderator/admin
+  role revoke <user_id>         收回用户角色，恢复为普通用户`
+
+// Role 管理员命令行分配、收回角色，例如 ./RESTful-API role grant 1 admin
+func Role(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf(roleUsage)
+	}
+	userID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid user id %q\n%s", args[1], roleUsage)
+	}
+
+	switch args[0] {
+	case "grant":
+		if len(args) < 3 {
+			return fmt.Errorf(roleUsage)
+		}
+		role, ok := rbac.ParseRole(args[2])
+		if !ok {
+			return fmt.Errorf("unknown role %q\n%s", args[2], roleUsage)
+		}
+		if e := service.GrantRole(context.Background(), userID, role); e != nil {
+			return fmt.Errorf("grant role failed: %s", e)
+		}
+		fmt.Printf("user %d is now %s\n", userID, role)
+	case "revoke":
+		if e := service.RevokeRole(context.Background(), userID); e != nil {
+			return fmt.Errorf("revoke role failed: %s", e)
+		}
+		fmt.Printf("user %d is now %s\n", userID, rbac.RoleUser)
+	default:
+		return fmt.Errorf(roleUsage)
+	}
+	return nil
+}
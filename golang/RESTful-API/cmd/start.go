@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"RESTful-API/internal/session"
+	"errors"
 	"fmt"
 	"github.com/spf13/viper"
 )
 
 func Start() {
 	fmt.Println("============================server start=====================================")
+	viper.AddConfigPath("./configs/")
 	viper.AddConfigPath("./golang/RESTful-API/configs/")
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -15,4 +18,15 @@ func Start() {
 	if err != nil {
 		panic(fmt.Sprintf("配置文件错误 %s", err.Error()))
 	}
+
+	// 密钥等敏感配置放在不入库的 secret.yaml 中，存在时覆盖 config.yaml 的同名配置
+	viper.SetConfigName("secret")
+	if err = viper.MergeInConfig(); err != nil && !errors.As(err, &viper.ConfigFileNotFoundError{}) {
+		panic(fmt.Sprintf("配置文件错误 %s", err.Error()))
+	}
+
+	// 没有签名密钥时无法登录，启动时直接失败（dev 模式下会使用临时密钥）
+	if _, err = session.Default(); err != nil {
+		panic(fmt.Sprintf("token 配置错误 %s", err.Error()))
+	}
 }
@@ -0,0 +1,58 @@
+// Package auth 请求范围内的当前登录用户
+package auth
+
+import (
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/rbac"
+	"context"
+)
+
+type ctxKey struct{}
+
+// CurrentUser 当前请求的登录用户
+type CurrentUser struct {
+	User    *model.UsersModel         `json:"user"`
+	Wallets []*model.UserWalletsModel `json:"wallets"`
+}
+
+// ID 返回当前用户 id
+func (u *CurrentUser) ID() int64 {
+	return u.User.ID
+}
+
+// Role 返回当前用户角色，未知角色按普通用户处理
+func (u *CurrentUser) Role() rbac.Role {
+	if role, ok := rbac.ParseRole(u.User.AuthGroup); ok {
+		return role
+	}
+	return rbac.RoleUser
+}
+
+// Can 判断当前用户是否拥有指定权限，被禁用的账号没有任何权限
+func (u *CurrentUser) Can(perms ...rbac.Permission) bool {
+	if u.User.Status == rbac.StatusDisabled {
+		return false
+	}
+	return rbac.Can(u.Role(), perms...)
+}
+
+// Wallet 返回用户在指定链上绑定的钱包，未绑定时返回 nil
+func (u *CurrentUser) Wallet(chain string) *model.UserWalletsModel {
+	for _, w := range u.Wallets {
+		if w.Chain == chain {
+			return w
+		}
+	}
+	return nil
+}
+
+// WithUser 将当前用户写入 context
+func WithUser(ctx context.Context, user *CurrentUser) context.Context {
+	return context.WithValue(ctx, ctxKey{}, user)
+}
+
+// FromContext 从 context 中取出当前用户，匿名访问时返回 nil, false
+func FromContext(ctx context.Context) (*CurrentUser, bool) {
+	user, ok := ctx.Value(ctxKey{}).(*CurrentUser)
+	return user, ok && user != nil
+}
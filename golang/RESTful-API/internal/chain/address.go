@@ -0,0 +1,134 @@
+package chain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/mr-tron/base58"
+	"github.com/xssnick/tonutils-go/address"
+	"golang.org/x/crypto/sha3"
+	"strings"
+)
+
+var (
+	ErrUnknownChain    = errors.New("unknown chain")
+	ErrInvalidAddress  = errors.New("invalid address")
+	ErrAddressChecksum = errors.New("address checksum mismatch")
+)
+
+// NormalizeAddress 校验地址并返回规范形式，数据库中只保存规范形式：
+//   - EVM：EIP-55 校验和格式，全小写或全大写的输入不校验大小写，混合大小写的输入必须通过校验
+//   - Solana：base58 编码的 32 字节公钥
+//   - TON：原始格式 workchain:hex（小写），同时接受 user-friendly 格式（base64 / base64url）
+func NormalizeAddress(c Chain, addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	switch c {
+	case EVM:
+		return normalizeEVM(addr)
+	case Solana:
+		return normalizeSolana(addr)
+	case TON:
+		return normalizeTON(addr)
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownChain, c)
+}
+
+// SameAddress 判断两个地址是否为同一个地址，任意一个不合法时返回 false
+func SameAddress(c Chain, a, b string) bool {
+	na, err := NormalizeAddress(c, a)
+	if err != nil {
+		return false
+	}
+	nb, err := NormalizeAddress(c, b)
+	return err == nil && na == nb
+}
+
+// normalizeEVM 校验 0x 开头的 20 字节地址，返回 EIP-55 格式
+func normalizeEVM(addr string) (string, error) {
+	if len(addr) != 42 || !(strings.HasPrefix(addr, "0x") || strings.HasPrefix(addr, "0X")) {
+		return "", ErrInvalidAddress
+	}
+	body := addr[2:]
+	if _, err := hex.DecodeString(body); err != nil {
+		return "", ErrInvalidAddress
+	}
+	checksummed := ChecksumEVM(body)
+	lower, upper := strings.ToLower(body), strings.ToUpper(body)
+	if body != lower && body != upper && "0x"+body != checksummed {
+		return "", ErrAddressChecksum
+	}
+	return checksummed, nil
+}
+
+// ChecksumEVM 按 EIP-55 计算地址的大小写，hexAddr 为不带 0x 的 40 位 hex
+func ChecksumEVM(hexAddr string) string {
+	lower := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(hexAddr, "0x"), "0X"))
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := h.Sum(nil)
+
+	result := []byte(lower)
+	for i, ch := range result {
+		if ch < 'a' || ch > 'f' {
+			continue
+		}
+		// 地址第 i 位对应哈希的第 i 个半字节，>= 8 时大写
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			result[i] = ch - 'a' + 'A'
+		}
+	}
+	return "0x" + string(result)
+}
+
+// normalizeSolana 校验 base58 编码的 32 字节公钥
+func normalizeSolana(addr string) (string, error) {
+	data, err := base58.Decode(addr)
+	if err != nil || len(data) != 32 {
+		return "", ErrInvalidAddress
+	}
+	return base58.Encode(data), nil
+}
+
+// normalizeTON 解析原始格式或 user-friendly 格式，返回原始格式
+func normalizeTON(addr string) (string, error) {
+	a, err := ParseTON(addr)
+	if err != nil {
+		return "", err
+	}
+	return a.StringRaw(), nil
+}
+
+// ParseTON 解析 TON 地址，支持 0:hex 原始格式和 48 位 user-friendly 格式
+func ParseTON(addr string) (*address.Address, error) {
+	var (
+		a   *address.Address
+		err error
+	)
+	if strings.Contains(addr, ":") {
+		a, err = address.ParseRawAddr(addr)
+		if err == nil && a.Workchain() != 0 && a.Workchain() != -1 {
+			err = ErrInvalidAddress // 只支持 basechain 和 masterchain
+		}
+	} else {
+		// user-friendly 格式可能是标准 base64，统一转为 base64url
+		addr = strings.NewReplacer("+", "-", "/", "_").Replace(strings.TrimRight(addr, "="))
+		a, err = address.ParseAddr(addr)
+	}
+	if err != nil {
+		return nil, ErrInvalidAddress
+	}
+	return a, nil
+}
+
+// FriendlyTON 原始格式转为 bounceable 的 user-friendly 格式，用于展示
+func FriendlyTON(addr string, testnet bool) (string, error) {
+	a, err := ParseTON(addr)
+	if err != nil {
+		return "", err
+	}
+	return a.Bounce(true).Testnet(testnet).String(), nil
+}
@@ -0,0 +1,37 @@
+// Package chain 多链支持：链枚举、地址校验与规范化，以及访问各链节点的统一 Client 接口和注册表
+package chain
+
+import "RESTful-API/internal/constants"
+
+// Chain 区块链，取值与数据库中的 ENUM('EVM', 'TON', 'Solana') 一致
+type Chain string
+
+const (
+	EVM    Chain = constants.ChainEVM
+	TON    Chain = constants.ChainTON
+	Solana Chain = constants.ChainSolana
+)
+
+// All 支持的全部链
+func All() []Chain {
+	return []Chain{EVM, TON, Solana}
+}
+
+// Parse 解析链名称，大小写必须与枚举一致
+func Parse(s string) (Chain, bool) {
+	c := Chain(s)
+	return c, c.Valid()
+}
+
+// Valid 是否为支持的链
+func (c Chain) Valid() bool {
+	switch c {
+	case EVM, TON, Solana:
+		return true
+	}
+	return false
+}
+
+func (c Chain) String() string {
+	return string(c)
+}
@@ -0,0 +1,111 @@
+package chain_test
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/chaintest"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	alice = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	bob   = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tonRaw := "0:" + strings.Repeat("ab", 32)
+	tests := []struct {
+		chain chain.Chain
+		addr  string
+		want  string
+		err   error
+	}{
+		{chain.EVM, strings.ToLower(alice), alice, nil},
+		{chain.EVM, "0X" + strings.ToUpper(alice[2:]), alice, nil},
+		{chain.EVM, " " + alice + " ", alice, nil},
+		{chain.EVM, "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "", chain.ErrAddressChecksum},
+		{chain.EVM, alice[:41], "", chain.ErrInvalidAddress},
+		{chain.Solana, "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM", "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM", nil},
+		{chain.Solana, "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWW0", "", chain.ErrInvalidAddress},
+		{chain.TON, strings.ToUpper(tonRaw), tonRaw, nil},
+		{chain.TON, "5:" + strings.Repeat("ab", 32), "", chain.ErrInvalidAddress},
+		{chain.Chain("BTC"), alice, "", chain.ErrUnknownChain},
+	}
+	for _, tt := range tests {
+		got, err := chain.NormalizeAddress(tt.chain, tt.addr)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("NormalizeAddress(%s, %q) = %q, %v; want %q, %v", tt.chain, tt.addr, got, err, tt.want, tt.err)
+		}
+	}
+
+	// user-friendly 格式与原始格式是同一个地址
+	friendly, err := chain.FriendlyTON(tonRaw, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !chain.SameAddress(chain.TON, friendly, tonRaw) {
+		t.Fatalf("%s and %s should be the same address", friendly, tonRaw)
+	}
+}
+
+func TestRegisterFake(t *testing.T) {
+	fake := chaintest.NewFake(chain.EVM)
+	chain.Register(fake)
+	client, err := chain.Get(chain.EVM)
+	if err != nil || client != chain.Client(fake) {
+		t.Fatalf("Get = %v, %v", client, err)
+	}
+
+	ctx := context.Background()
+	fake.SetHeight(10)
+	fake.AddTransaction(chain.Transaction{Hash: "0x01", BlockNumber: 8, Success: true})
+	if tx, err := client.Transaction(ctx, "0x01"); err != nil || tx.Confirmations != 3 {
+		t.Fatalf("Transaction = %+v, %v", tx, err)
+	}
+	// 地址大小写不同也能查到
+	fake.SetBalance(strings.ToLower(alice), "", big.NewInt(5))
+	if b, err := client.TokenBalance(ctx, alice, ""); err != nil || b.Int64() != 5 {
+		t.Fatalf("TokenBalance = %v, %v", b, err)
+	}
+	fake.SetOwner(bob, "1", strings.ToLower(alice))
+	if owner, err := client.NFTOwner(ctx, bob, "1"); err != nil || owner != alice {
+		t.Fatalf("NFTOwner = %q, %v", owner, err)
+	}
+	if _, err := client.NFTOwner(ctx, bob, "2"); !errors.Is(err, chain.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+}
+
+func TestFakeSubscribe(t *testing.T) {
+	fake := chaintest.NewFake(chain.EVM)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fake.Emit(chain.Event{Address: alice, BlockNumber: 1})
+	fake.Emit(chain.Event{Address: alice, BlockNumber: 2, TxHash: "past"})
+	sub, err := fake.Subscribe(ctx, chain.EventFilter{Addresses: []string{strings.ToLower(alice)}, FromBlock: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	go func() {
+		fake.Emit(chain.Event{Address: bob, BlockNumber: 3})
+		fake.Emit(chain.Event{Address: alice, BlockNumber: 4, TxHash: "new"})
+	}()
+
+	// 先回放 FromBlock 之后的历史事件，再推送新事件，其他地址的事件被过滤
+	for _, want := range []string{"past", "new"} {
+		select {
+		case e := <-sub.Events():
+			if e.TxHash != want || e.Chain != chain.EVM {
+				t.Fatalf("got %+v, want %s", e, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("%s event not received", want)
+		}
+	}
+}
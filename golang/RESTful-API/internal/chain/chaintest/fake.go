@@ -0,0 +1,209 @@
+// Package chaintest 测试用的内存链客户端
+package chaintest
+
+import (
+	"RESTful-API/internal/chain"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Fake 内存中的 chain.Client，业务逻辑的测试通过 chain.Register(chaintest.NewFake(chain.EVM)) 替换真实节点。
+// 各链实现另有本地节点替身（evmtest.Node、solanatest.Node、tontest.API）用于测试协议层
+type Fake struct {
+	chain chain.Chain
+
+	mu       sync.Mutex
+	height   uint64
+	txs      map[string]chain.Transaction
+	balances map[string]*big.Int
+	owners   map[string]string
+	events   []chain.Event
+	subs     []*fakeSub
+}
+
+type fakeSub struct {
+	filter chain.EventFilter
+	ch     chan chain.Event
+	done   chan struct{}
+}
+
+var _ chain.Client = (*Fake)(nil)
+
+// NewFake 创建链 c 的内存客户端
+func NewFake(c chain.Chain) *Fake {
+	return &Fake{
+		chain:    c,
+		txs:      make(map[string]chain.Transaction),
+		balances: make(map[string]*big.Int),
+		owners:   make(map[string]string),
+	}
+}
+
+func (f *Fake) Chain() chain.Chain {
+	return f.chain
+}
+
+// SetHeight 设置当前高度
+func (f *Fake) SetHeight(height uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.height = height
+}
+
+// AddTransaction 添加交易，Confirmations 在查询时按当前高度计算
+func (f *Fake) AddTransaction(tx chain.Transaction) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.txs[tx.Hash] = tx
+}
+
+// SetBalance 设置余额，token 为空表示原生币
+func (f *Fake) SetBalance(owner, token string, balance *big.Int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.balances[f.key(owner, token)] = balance
+}
+
+// SetOwner 设置 NFT 拥有者，owner 为空表示 NFT 不存在
+func (f *Fake) SetOwner(contract, tokenID, owner string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.owners[f.key(contract, tokenID)] = owner
+}
+
+// Emit 产生一个事件，推送给匹配的订阅
+func (f *Fake) Emit(e chain.Event) {
+	f.mu.Lock()
+	e.Chain = f.chain
+	f.events = append(f.events, e)
+	subs := append([]*fakeSub(nil), f.subs...)
+	f.mu.Unlock()
+	for _, s := range subs {
+		if match(s.filter, e) {
+			select {
+			case s.ch <- e:
+			case <-s.done:
+			}
+		}
+	}
+}
+
+func (f *Fake) BlockHeight(ctx context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.height, nil
+}
+
+func (f *Fake) Transaction(ctx context.Context, hash string) (*chain.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tx, ok := f.txs[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", chain.ErrTxNotFound, hash)
+	}
+	if tx.BlockNumber > 0 && f.height >= tx.BlockNumber {
+		tx.Confirmations = f.height - tx.BlockNumber + 1
+	}
+	return &tx, nil
+}
+
+func (f *Fake) TokenBalance(ctx context.Context, owner, token string) (*big.Int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if b, ok := f.balances[f.key(owner, token)]; ok {
+		return new(big.Int).Set(b), nil
+	}
+	return new(big.Int), nil
+}
+
+func (f *Fake) NFTOwner(ctx context.Context, contract, tokenID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	owner := f.owners[f.key(contract, tokenID)]
+	if owner == "" {
+		return "", fmt.Errorf("%w: %s #%s", chain.ErrNotFound, contract, tokenID)
+	}
+	return chain.NormalizeAddress(f.chain, owner)
+}
+
+// Subscribe 先回放已产生的匹配事件，之后推送 Emit 的新事件
+func (f *Fake) Subscribe(ctx context.Context, filter chain.EventFilter) (*chain.Subscription, error) {
+	// 同步登记订阅，保证 Subscribe 返回后 Emit 的事件不会丢失
+	sub := &fakeSub{filter: filter, ch: make(chan chain.Event), done: make(chan struct{})}
+	f.mu.Lock()
+	past := append([]chain.Event(nil), f.events...)
+	f.subs = append(f.subs, sub)
+	f.mu.Unlock()
+
+	return chain.NewSubscription(ctx, func(ctx context.Context, send func(chain.Event) bool, fail func(error)) {
+		defer f.unsubscribe(sub)
+		defer close(sub.done)
+		for _, e := range past {
+			if match(filter, e) && !send(e) {
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-sub.ch:
+				if !send(e) {
+					return
+				}
+			}
+		}
+	}), nil
+}
+
+func (f *Fake) unsubscribe(sub *fakeSub) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, s := range f.subs {
+		if s == sub {
+			f.subs = append(f.subs[:i], f.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// key 地址按规范形式比较，非法地址原样使用
+func (f *Fake) key(addr, suffix string) string {
+	if n, err := chain.NormalizeAddress(f.chain, addr); err == nil {
+		addr = n
+	}
+	return addr + "/" + suffix
+}
+
+// match 事件是否满足订阅条件
+func match(filter chain.EventFilter, e chain.Event) bool {
+	if e.BlockNumber < filter.FromBlock {
+		return false
+	}
+	if len(filter.Addresses) > 0 {
+		found := false
+		for _, a := range filter.Addresses {
+			if a == e.Address || chain.SameAddress(e.Chain, a, e.Address) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(filter.Topics) > 0 {
+		if len(e.Topics) == 0 {
+			return false
+		}
+		for _, t := range filter.Topics {
+			if t == e.Topics[0] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrNotConfigured = errors.New("chain client not configured")
+	ErrTxNotFound    = errors.New("transaction not found")
+	ErrNotFound      = errors.New("not found")
+)
+
+// Client 与一条链交互的统一接口，业务代码只依赖该接口，不直接引用各链的 SDK
+type Client interface {
+	// Chain 所属的链
+	Chain() Chain
+	// BlockHeight 最新高度：EVM 为区块号，Solana 为 slot，TON 为 masterchain seqno
+	BlockHeight(ctx context.Context) (uint64, error)
+	// Transaction 按哈希查询交易，不存在时返回 ErrTxNotFound
+	Transaction(ctx context.Context, hash string) (*Transaction, error)
+	// TokenBalance 查询 owner 持有的代币数量（最小单位），token 为空时查询原生币
+	TokenBalance(ctx context.Context, owner, token string) (*big.Int, error)
+	// NFTOwner 查询 NFT 当前拥有者的规范地址，不存在时返回 ErrNotFound
+	NFTOwner(ctx context.Context, contract, tokenID string) (string, error)
+	// Subscribe 订阅合约或账户上的事件，ctx 结束或调用 Close 后停止
+	Subscribe(ctx context.Context, filter EventFilter) (*Subscription, error)
+}
+
+// Transaction 链上交易
+type Transaction struct {
+	Hash          string
+	From          string
+	To            string
+	Value         *big.Int // 转账的原生币数量，最小单位
+	BlockNumber   uint64
+	BlockHash     string
+	Success       bool
+	Confirmations uint64 // 当前高度与交易所在高度之差 + 1
+	Time          time.Time
+}
+
+// EventFilter 事件订阅条件
+type EventFilter struct {
+	Addresses []string // 合约、程序或账户地址
+	Topics    []string // EVM 的 topic0，为空时不过滤
+	FromBlock uint64   // 起始高度，0 表示从当前高度开始；TON 为逻辑时间 lt
+}
+
+// Event 链上事件，各链的原始数据放在 Raw 中
+type Event struct {
+	Chain       Chain
+	Address     string // 产生事件的合约或账户
+	TxHash      string
+	BlockNumber uint64 // EVM 区块号、Solana slot、TON 逻辑时间 lt
+	BlockHash   string
+	LogIndex    uint
+	Topics      []string
+	Data        []byte
+	Raw         interface{}
+}
+
+// Endpoint 节点配置
+type Endpoint struct {
+	URL           string
+	APIKey        string
+	ChainID       int64  // 仅 EVM 使用
+	WETH          string // 仅 EVM 使用，WETH 合约地址，成交中买方支付的 WETH 按 ETH 计价
+	Confirmations uint64 // 达到该确认数后视为不可回滚，索引器只处理到 最新高度 - Confirmations
+	Timeout       time.Duration
+	PollInterval  time.Duration // 事件订阅的轮询间隔
+}
@@ -0,0 +1,338 @@
+// Package evm EVM 链的 chain.Client 实现，通过标准以太坊 JSON-RPC 访问节点
+package evm
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/jsonrpc"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	// selectorOwnerOf ownerOf(uint256)
+	selectorOwnerOf = "0x6352211e"
+	// selectorBalanceOf balanceOf(address)
+	selectorBalanceOf = "0x70a08231"
+	// selectorDecimals decimals()
+	selectorDecimals = "0x313ce567"
+	// maxBlockRange 单次 eth_getLogs 查询的最大区块数，多数节点限制在 1000 到 10000 之间
+	maxBlockRange = 1000
+)
+
+// ErrChainIDMismatch 节点返回的 chainId 与配置不一致，通常是节点地址配成了其他网络
+var ErrChainIDMismatch = errors.New("evm: node chainId does not match config")
+
+func init() {
+	chain.RegisterFactory(chain.EVM, func(endpoint chain.Endpoint) (chain.Client, error) {
+		c := New(endpoint)
+		// 连错网络时交易校验和懒铸造签名都会出错，创建客户端时校验一次；
+		// 校验失败不缓存客户端，下次 chain.Get 时重新校验
+		if err := c.VerifyChainID(context.Background()); err != nil {
+			return nil, err
+		}
+		return c, nil
+	})
+}
+
+// Client EVM 节点客户端
+type Client struct {
+	rpc          *jsonrpc.Client
+	chainID      int64
+	weth         string
+	pollInterval time.Duration
+}
+
+var _ chain.Client = (*Client)(nil)
+
+// New 创建客户端，APIKey 不为空时作为 Authorization: Bearer 发送
+func New(endpoint chain.Endpoint) *Client {
+	var headers map[string]string
+	if endpoint.APIKey != "" {
+		headers = map[string]string{"Authorization": "Bearer " + endpoint.APIKey}
+	}
+	// 地址格式不对时视为未配置
+	weth, _ := chain.NormalizeAddress(chain.EVM, endpoint.WETH)
+	return &Client{
+		rpc:          jsonrpc.New(endpoint.URL, "evm-rpc", endpoint.Timeout, headers),
+		chainID:      endpoint.ChainID,
+		weth:         weth,
+		pollInterval: endpoint.PollInterval,
+	}
+}
+
+func (c *Client) Chain() chain.Chain {
+	return chain.EVM
+}
+
+// WETH 配置的 WETH 合约地址（EIP-55），未配置时为空
+func (c *Client) WETH() string {
+	return c.weth
+}
+
+// ChainID 节点返回的 chainId，用于校验节点与配置是否一致
+func (c *Client) ChainID(ctx context.Context) (int64, error) {
+	var q quantity
+	if err := c.rpc.Call(ctx, &q, "eth_chainId"); err != nil {
+		return 0, err
+	}
+	n, err := q.Uint64()
+	return int64(n), err
+}
+
+// VerifyChainID 校验节点返回的 chainId 与配置的一致
+func (c *Client) VerifyChainID(ctx context.Context) error {
+	id, err := c.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if id != c.chainID {
+		return fmt.Errorf("%w: node %d, chain.EVM.chainId %d", ErrChainIDMismatch, id, c.chainID)
+	}
+	return nil
+}
+
+func (c *Client) BlockHeight(ctx context.Context) (uint64, error) {
+	var q quantity
+	if err := c.rpc.Call(ctx, &q, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return q.Uint64()
+}
+
+// Header 区块头中索引需要的字段
+type Header struct {
+	Number     uint64
+	Hash       string
+	ParentHash string
+	Time       time.Time
+}
+
+type rpcHeader struct {
+	Number     quantity `json:"number"`
+	Hash       string   `json:"hash"`
+	ParentHash string   `json:"parentHash"`
+	Timestamp  quantity `json:"timestamp"`
+}
+
+// HeaderByNumber 查询区块头，区块不存在时返回 chain.ErrNotFound
+func (c *Client) HeaderByNumber(ctx context.Context, number uint64) (*Header, error) {
+	var h *rpcHeader
+	if err := c.rpc.Call(ctx, &h, "eth_getBlockByNumber", encodeUint64(number), false); err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, fmt.Errorf("%w: block %d", chain.ErrNotFound, number)
+	}
+	n, err := h.Number.Uint64()
+	if err != nil {
+		return nil, err
+	}
+	ts, err := h.Timestamp.Uint64()
+	if err != nil {
+		return nil, err
+	}
+	return &Header{Number: n, Hash: h.Hash, ParentHash: h.ParentHash, Time: time.Unix(int64(ts), 0)}, nil
+}
+
+type rpcTransaction struct {
+	Hash        string   `json:"hash"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Value       quantity `json:"value"`
+	BlockNumber *string  `json:"blockNumber"`
+}
+
+type rpcReceipt struct {
+	Status      quantity `json:"status"`
+	BlockNumber quantity `json:"blockNumber"`
+	BlockHash   string   `json:"blockHash"`
+	Logs        []rpcLog `json:"logs"`
+}
+
+// Transaction 查询交易及回执；尚未打包的交易 BlockNumber 为 0、Confirmations 为 0
+func (c *Client) Transaction(ctx context.Context, hash string) (*chain.Transaction, error) {
+	if !isHash(hash) {
+		return nil, fmt.Errorf("%w: %s", chain.ErrTxNotFound, hash)
+	}
+	var tx *rpcTransaction
+	if err := c.rpc.Call(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("%w: %s", chain.ErrTxNotFound, hash)
+	}
+	value, err := tx.Value.Big()
+	if err != nil {
+		return nil, err
+	}
+	result := &chain.Transaction{Hash: strings.ToLower(tx.Hash), Value: value}
+	if result.From, err = chain.NormalizeAddress(chain.EVM, tx.From); err != nil {
+		return nil, err
+	}
+	// 创建合约的交易没有 to
+	if tx.To != "" {
+		if result.To, err = chain.NormalizeAddress(chain.EVM, tx.To); err != nil {
+			return nil, err
+		}
+	}
+	if tx.BlockNumber == nil {
+		return result, nil
+	}
+
+	var receipt *rpcReceipt
+	if err = c.rpc.Call(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return result, nil
+	}
+	if result.BlockNumber, err = receipt.BlockNumber.Uint64(); err != nil {
+		return nil, err
+	}
+	result.BlockHash = receipt.BlockHash
+	result.Success = receipt.Status == "0x1"
+
+	head, err := c.BlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head >= result.BlockNumber {
+		result.Confirmations = head - result.BlockNumber + 1
+	}
+	header, err := c.HeaderByNumber(ctx, result.BlockNumber)
+	if err != nil && !errors.Is(err, chain.ErrNotFound) {
+		return nil, err
+	}
+	if header != nil {
+		result.Time = header.Time
+	}
+	return result, nil
+}
+
+// TransactionLogs 查询交易回执中的日志，用于解码交易内容
+func (c *Client) TransactionLogs(ctx context.Context, hash string) ([]Log, error) {
+	var receipt *rpcReceipt
+	if err := c.rpc.Call(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, fmt.Errorf("%w: %s", chain.ErrTxNotFound, hash)
+	}
+	return convertLogs(receipt.Logs)
+}
+
+// TokenBalance token 为空时查询 ETH 余额，否则查询 ERC-20 / ERC-721 的 balanceOf
+func (c *Client) TokenBalance(ctx context.Context, owner, token string) (*big.Int, error) {
+	owner, err := chain.NormalizeAddress(chain.EVM, owner)
+	if err != nil {
+		return nil, err
+	}
+	var q quantity
+	if token == "" {
+		if err = c.rpc.Call(ctx, &q, "eth_getBalance", owner, "latest"); err != nil {
+			return nil, err
+		}
+		return q.Big()
+	}
+	if token, err = chain.NormalizeAddress(chain.EVM, token); err != nil {
+		return nil, err
+	}
+	out, err := c.call(ctx, token, selectorBalanceOf+addressWord(owner))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("balanceOf: short output")
+	}
+	return new(big.Int).SetBytes(out[:32]), nil
+}
+
+// Decimals 调用 ERC-20 的 decimals，返回代币精度
+func (c *Client) Decimals(ctx context.Context, token string) (int32, error) {
+	token, err := chain.NormalizeAddress(chain.EVM, token)
+	if err != nil {
+		return 0, err
+	}
+	out, err := c.call(ctx, token, selectorDecimals)
+	if err != nil {
+		return 0, err
+	}
+	if len(out) < 32 {
+		return 0, fmt.Errorf("decimals: short output")
+	}
+	d := new(big.Int).SetBytes(out[:32])
+	if !d.IsUint64() || d.Uint64() > 77 { // uint256 最多 78 位十进制
+		return 0, fmt.Errorf("decimals: invalid value %s", d)
+	}
+	return int32(d.Uint64()), nil
+}
+
+// NFTOwner 调用 ERC-721 的 ownerOf，合约 revert（token 不存在）时返回 chain.ErrNotFound
+func (c *Client) NFTOwner(ctx context.Context, contract, tokenID string) (string, error) {
+	contract, err := chain.NormalizeAddress(chain.EVM, contract)
+	if err != nil {
+		return "", err
+	}
+	id, err := ParseTokenID(tokenID)
+	if err != nil {
+		return "", err
+	}
+	out, err := c.call(ctx, contract, selectorOwnerOf+word(id))
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.Code == 3 {
+		return "", fmt.Errorf("%w: %s #%s", chain.ErrNotFound, contract, tokenID)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(out) < 32 {
+		return "", fmt.Errorf("%w: %s #%s", chain.ErrNotFound, contract, tokenID)
+	}
+	return chain.ChecksumEVM(fmt.Sprintf("%x", out[12:32])), nil
+}
+
+// call eth_call 只读调用
+func (c *Client) call(ctx context.Context, to, data string) ([]byte, error) {
+	var out string
+	msg := map[string]string{"to": to, "data": data}
+	if err := c.rpc.Call(ctx, &out, "eth_call", msg, "latest"); err != nil {
+		return nil, err
+	}
+	return decodeHex(out)
+}
+
+// Subscribe 轮询 eth_getLogs，每轮最多查询 maxBlockRange 个区块
+func (c *Client) Subscribe(ctx context.Context, filter chain.EventFilter) (*chain.Subscription, error) {
+	next := filter.FromBlock
+	if next == 0 {
+		head, err := c.BlockHeight(ctx)
+		if err != nil {
+			return nil, err
+		}
+		next = head + 1
+	}
+	return chain.Poll(ctx, c.pollInterval, func(ctx context.Context) ([]chain.Event, error) {
+		head, err := c.BlockHeight(ctx)
+		if err != nil || head < next {
+			return nil, err
+		}
+		to := head
+		if to-next+1 > maxBlockRange {
+			to = next + maxBlockRange - 1
+		}
+		logs, err := c.Logs(ctx, LogQuery{FromBlock: next, ToBlock: to, Addresses: filter.Addresses, Topics: filter.Topics})
+		if err != nil {
+			return nil, err
+		}
+		next = to + 1
+		events := make([]chain.Event, 0, len(logs))
+		for _, l := range logs {
+			events = append(events, l.Event())
+		}
+		return events, nil
+	}), nil
+}
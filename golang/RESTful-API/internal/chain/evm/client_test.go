@@ -0,0 +1,155 @@
+package evm_test
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/evm/evmtest"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	alice    = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	bob      = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+	contract = "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"
+)
+
+// transferTopic ERC-721 / ERC-20 Transfer(address,address,uint256) 的 topic0
+const transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+func TestClientTransaction(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx := context.Background()
+	client := node.Client()
+
+	node.Mine(1)
+	hash := node.AddTransaction(strings.ToLower(alice), strings.ToLower(bob), big.NewInt(1e18), true)
+	failed := node.AddTransaction(alice, bob, nil, false)
+	node.Mine(4)
+
+	tx, err := client.Transaction(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 地址转为 EIP-55 形式，确认数为最新高度与所在区块之差 + 1
+	if tx.From != alice || tx.To != bob || tx.Value.Cmp(big.NewInt(1e18)) != 0 ||
+		!tx.Success || tx.BlockNumber != 1 || tx.Confirmations != 5 || tx.BlockHash != node.Header(1).Hash {
+		t.Fatalf("unexpected transaction %+v", tx)
+	}
+	if tx.Time.IsZero() {
+		t.Fatal("transaction time not set")
+	}
+
+	if tx, err = client.Transaction(ctx, failed); err != nil || tx.Success {
+		t.Fatalf("failed transaction: %+v, %v", tx, err)
+	}
+	for _, h := range []string{"0x" + strings.Repeat("ab", 32), "not-a-hash"} {
+		if _, err = client.Transaction(ctx, h); !errors.Is(err, chain.ErrTxNotFound) {
+			t.Fatalf("%s: got %v, want ErrTxNotFound", h, err)
+		}
+	}
+}
+
+func TestClientContractCalls(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx := context.Background()
+	client := node.Client()
+
+	node.SetOwner(contract, "42", strings.ToLower(alice))
+	owner, err := client.NFTOwner(ctx, contract, "0x2a")
+	if err != nil || owner != alice {
+		t.Fatalf("NFTOwner = %q, %v", owner, err)
+	}
+	if _, err = client.NFTOwner(ctx, contract, "43"); !errors.Is(err, chain.ErrNotFound) {
+		t.Fatalf("missing token: got %v, want ErrNotFound", err)
+	}
+
+	node.SetBalance("", alice, big.NewInt(7))
+	node.SetBalance(contract, bob, big.NewInt(1234))
+	node.SetDecimals(contract, 6)
+	if b, err := client.TokenBalance(ctx, alice, ""); err != nil || b.Int64() != 7 {
+		t.Fatalf("ETH balance = %v, %v", b, err)
+	}
+	if b, err := client.TokenBalance(ctx, bob, contract); err != nil || b.Int64() != 1234 {
+		t.Fatalf("token balance = %v, %v", b, err)
+	}
+	if d, err := client.Decimals(ctx, contract); err != nil || d != 6 {
+		t.Fatalf("decimals = %d, %v", d, err)
+	}
+	if id, err := client.ChainID(ctx); err != nil || id != 1 {
+		t.Fatalf("chain id = %d, %v", id, err)
+	}
+}
+
+func TestClientVerifyChainID(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx := context.Background()
+
+	if err := node.Client().VerifyChainID(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 配置的是 Sepolia，节点却是主网
+	sepolia := evm.New(chain.Endpoint{URL: node.URL(), ChainID: 11155111})
+	if err := sepolia.VerifyChainID(ctx); !errors.Is(err, evm.ErrChainIDMismatch) {
+		t.Fatalf("got %v, want ErrChainIDMismatch", err)
+	}
+	node.Close()
+	if err := node.Client().VerifyChainID(ctx); err == nil || errors.Is(err, evm.ErrChainIDMismatch) {
+		t.Fatalf("unreachable node: got %v", err)
+	}
+}
+
+func TestClientLogs(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx := context.Background()
+	client := node.Client()
+
+	node.Mine(2)
+	node.AddLog(evm.Log{Address: contract, Topics: []string{transferTopic}, Data: []byte{1}})
+	node.AddLog(evm.Log{Address: bob, Topics: []string{transferTopic}})
+	node.Mine(1)
+
+	logs, err := client.Logs(ctx, evm.LogQuery{FromBlock: 0, ToBlock: 3, Addresses: []string{contract}, Topics: []string{transferTopic}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].BlockNumber != 2 || logs[0].BlockHash != node.Header(2).Hash || logs[0].Data[0] != 1 {
+		t.Fatalf("unexpected logs %+v", logs)
+	}
+	if _, err = client.Logs(ctx, evm.LogQuery{FromBlock: 0, ToBlock: evmtest.MaxBlockRange}); err == nil {
+		t.Fatal("range larger than the node limit should fail")
+	}
+}
+
+func TestClientSubscribe(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	node.Mine(1)
+	node.AddLog(evm.Log{Address: contract, Topics: []string{transferTopic}})
+	node.AddLog(evm.Log{Address: bob, Topics: []string{transferTopic}})
+	sub, err := node.Client().Subscribe(ctx, chain.EventFilter{Addresses: []string{contract}, FromBlock: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	select {
+	case e := <-sub.Events():
+		if e.Chain != chain.EVM || e.BlockNumber != 1 || e.Address != contract {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case <-ctx.Done():
+		t.Fatal("no event received")
+	}
+}
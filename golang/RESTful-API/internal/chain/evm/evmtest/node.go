@@ -0,0 +1,469 @@
+// Package evmtest 测试用的本地 EVM 节点替身，按以太坊 JSON-RPC 的线上格式应答，不依赖 evm 包的内部类型
+package evmtest
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/jsonrpc"
+	"RESTful-API/internal/chain/jsonrpc/jsonrpctest"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/crypto/sha3"
+	"math/big"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxBlockRange 单次 eth_getLogs 允许的最大区块数，与常见节点的限制一致
+const MaxBlockRange = 1000
+
+var (
+	selectorOwnerOf   = selector("ownerOf(uint256)")
+	selectorBalanceOf = selector("balanceOf(address)")
+	selectorDecimals  = selector("decimals()")
+)
+
+// Node 本地 EVM 节点替身，在内存中维护区块、交易、日志和合约状态
+type Node struct {
+	server *httptest.Server
+	rpc    *jsonrpctest.Server
+
+	mu       sync.Mutex
+	chainID  int64
+	fork     int // 每次 Reorg 后递增，使重新出块的区块哈希不同
+	blocks   []evm.Header
+	txs      map[string]nodeTx
+	logs     []evm.Log
+	owners   map[string]string   // contract/tokenId -> owner
+	balances map[string]*big.Int // token/owner -> balance，token 为空时为 ETH
+	decimals map[string]int32    // token -> decimals
+}
+
+type nodeTx struct {
+	tx      rpcTransaction
+	block   uint64
+	success bool
+	pending bool
+}
+
+// 以下为 JSON-RPC 的线上格式，数值均为 0x 开头的十六进制
+type rpcHeader struct {
+	Number     string `json:"number"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+	Timestamp  string `json:"timestamp"`
+}
+
+type rpcTransaction struct {
+	Hash        string  `json:"hash"`
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Value       string  `json:"value"`
+	BlockNumber *string `json:"blockNumber"`
+}
+
+type rpcReceipt struct {
+	Status      string   `json:"status"`
+	BlockNumber string   `json:"blockNumber"`
+	BlockHash   string   `json:"blockHash"`
+	Logs        []rpcLog `json:"logs"`
+}
+
+type rpcLog struct {
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+	BlockNumber string   `json:"blockNumber"`
+	BlockHash   string   `json:"blockHash"`
+	TxHash      string   `json:"transactionHash"`
+	LogIndex    string   `json:"logIndex"`
+	Removed     bool     `json:"removed"`
+}
+
+// NewNode 启动替身节点，初始只有创世区块
+func NewNode(chainID int64) *Node {
+	n := &Node{
+		rpc:      jsonrpctest.NewServer(),
+		chainID:  chainID,
+		txs:      make(map[string]nodeTx),
+		owners:   make(map[string]string),
+		balances: make(map[string]*big.Int),
+		decimals: make(map[string]int32),
+	}
+	n.blocks = []evm.Header{n.newHeader(0, "0x"+strings.Repeat("0", 64))}
+	n.routes()
+	n.server = httptest.NewServer(n.rpc)
+	return n
+}
+
+// URL 节点地址
+func (n *Node) URL() string {
+	return n.server.URL
+}
+
+// Client 连接到替身节点的客户端
+func (n *Node) Client() *evm.Client {
+	return evm.New(chain.Endpoint{URL: n.URL(), ChainID: n.chainID, PollInterval: 10 * time.Millisecond})
+}
+
+// Calls 返回 JSON-RPC 方法被调用的次数
+func (n *Node) Calls(method string) int {
+	return n.rpc.Calls(method)
+}
+
+// Close 关闭节点
+func (n *Node) Close() {
+	n.server.Close()
+}
+
+// Mine 出 count 个新区块，返回最新高度
+func (n *Node) Mine(count int) uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i := 0; i < count; i++ {
+		parent := n.blocks[len(n.blocks)-1]
+		n.blocks = append(n.blocks, n.newHeader(parent.Number+1, parent.Hash))
+	}
+	return n.blocks[len(n.blocks)-1].Number
+}
+
+// Reorg 丢弃 from 及之后的区块和日志，之后出的区块哈希与原链不同
+func (n *Node) Reorg(from uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if from == 0 || from >= uint64(len(n.blocks)) {
+		return
+	}
+	n.fork++
+	n.blocks = n.blocks[:from]
+	kept := n.logs[:0]
+	for _, l := range n.logs {
+		if l.BlockNumber < from {
+			kept = append(kept, l)
+		}
+	}
+	n.logs = kept
+	for hash, tx := range n.txs {
+		if tx.block >= from {
+			delete(n.txs, hash)
+		}
+	}
+}
+
+// Header 返回当前链上的区块头
+func (n *Node) Header(number uint64) evm.Header {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.blocks[number]
+}
+
+// AddTransaction 在最新区块中加入一笔交易，返回交易哈希
+func (n *Node) AddTransaction(from, to string, value *big.Int, success bool, logs ...evm.Log) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	head := n.blocks[len(n.blocks)-1]
+	hash := "0x" + hex.EncodeToString(keccak([]byte(fmt.Sprintf("tx/%d/%d/%d", head.Number, n.fork, len(n.txs)))))
+	if value == nil {
+		value = new(big.Int)
+	}
+	blockNumber := encodeUint64(head.Number)
+	n.txs[hash] = nodeTx{
+		tx:      rpcTransaction{Hash: hash, From: from, To: to, Value: encodeBig(value), BlockNumber: &blockNumber},
+		block:   head.Number,
+		success: success,
+	}
+	for _, l := range logs {
+		n.appendLog(l, head, hash)
+	}
+	return hash
+}
+
+// AddPendingTransaction 加入一笔还在交易池中的交易，没有所在区块和回执，返回交易哈希
+func (n *Node) AddPendingTransaction(from, to string, value *big.Int) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	hash := "0x" + hex.EncodeToString(keccak([]byte(fmt.Sprintf("pending/%d/%d", n.fork, len(n.txs)))))
+	if value == nil {
+		value = new(big.Int)
+	}
+	n.txs[hash] = nodeTx{tx: rpcTransaction{Hash: hash, From: from, To: to, Value: encodeBig(value)}, pending: true}
+	return hash
+}
+
+// AddLog 在最新区块中加入一条日志，交易哈希为空时自动生成
+func (n *Node) AddLog(l evm.Log) evm.Log {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	head := n.blocks[len(n.blocks)-1]
+	if l.TxHash == "" {
+		l.TxHash = "0x" + hex.EncodeToString(keccak([]byte(fmt.Sprintf("log/%d/%d/%d", head.Number, n.fork, len(n.logs)))))
+	}
+	return n.appendLog(l, head, l.TxHash)
+}
+
+func (n *Node) appendLog(l evm.Log, head evm.Header, txHash string) evm.Log {
+	l.BlockNumber = head.Number
+	l.BlockHash = head.Hash
+	l.TxHash = txHash
+	l.LogIndex = uint(len(n.logs))
+	n.logs = append(n.logs, l)
+	return l
+}
+
+// SetOwner 设置 ERC-721 ownerOf 的返回值，owner 为空表示 token 不存在
+func (n *Node) SetOwner(contract, tokenID, owner string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	id, _ := evm.ParseTokenID(tokenID)
+	n.owners[strings.ToLower(contract)+"/"+id.String()] = owner
+}
+
+// SetBalance 设置余额，token 为空时设置 ETH 余额
+func (n *Node) SetBalance(token, owner string, balance *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.balances[strings.ToLower(token)+"/"+strings.ToLower(owner)] = balance
+}
+
+// SetDecimals 设置 ERC-20 代币的精度
+func (n *Node) SetDecimals(token string, decimals int32) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.decimals[strings.ToLower(token)] = decimals
+}
+
+func (n *Node) newHeader(number uint64, parent string) evm.Header {
+	hash := keccak([]byte(fmt.Sprintf("block/%d/%d", number, n.fork)))
+	return evm.Header{Number: number, Hash: "0x" + hex.EncodeToString(hash), ParentHash: parent, Time: time.Unix(1700000000+int64(number)*12, 0)}
+}
+
+func (n *Node) routes() {
+	n.rpc.Handle("eth_chainId", func([]json.RawMessage) (interface{}, *jsonrpc.Error) {
+		return encodeUint64(uint64(n.chainID)), nil
+	})
+	n.rpc.Handle("eth_blockNumber", func([]json.RawMessage) (interface{}, *jsonrpc.Error) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return encodeUint64(n.blocks[len(n.blocks)-1].Number), nil
+	})
+	n.rpc.Handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		number, rpcErr := paramQuantity(params, 0)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if number >= uint64(len(n.blocks)) {
+			return nil, nil
+		}
+		h := n.blocks[number]
+		return rpcHeader{
+			Number:     encodeUint64(h.Number),
+			Hash:       h.Hash,
+			ParentHash: h.ParentHash,
+			Timestamp:  encodeUint64(uint64(h.Time.Unix())),
+		}, nil
+	})
+	n.rpc.Handle("eth_getTransactionByHash", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		tx, ok := n.lookupTx(params)
+		if !ok {
+			return nil, nil
+		}
+		return tx.tx, nil
+	})
+	n.rpc.Handle("eth_getTransactionReceipt", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		tx, ok := n.lookupTx(params)
+		if !ok || tx.pending {
+			return nil, nil
+		}
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		status := "0x0"
+		if tx.success {
+			status = "0x1"
+		}
+		receipt := rpcReceipt{Status: status, BlockNumber: encodeUint64(tx.block), BlockHash: n.blocks[tx.block].Hash, Logs: []rpcLog{}}
+		for _, l := range n.logs {
+			if l.TxHash == tx.tx.Hash {
+				receipt.Logs = append(receipt.Logs, toRPCLog(l))
+			}
+		}
+		return receipt, nil
+	})
+	n.rpc.Handle("eth_getBalance", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		var owner string
+		if len(params) < 1 || json.Unmarshal(params[0], &owner) != nil {
+			return nil, &jsonrpc.Error{Code: -32602, Message: "invalid params"}
+		}
+		return encodeBig(n.balance("", owner)), nil
+	})
+	n.rpc.Handle("eth_call", n.handleCall)
+	n.rpc.Handle("eth_getLogs", n.handleGetLogs)
+}
+
+func (n *Node) lookupTx(params []json.RawMessage) (nodeTx, bool) {
+	var hash string
+	if len(params) < 1 || json.Unmarshal(params[0], &hash) != nil {
+		return nodeTx{}, false
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	tx, ok := n.txs[strings.ToLower(hash)]
+	return tx, ok
+}
+
+func (n *Node) balance(token, owner string) *big.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if b, ok := n.balances[strings.ToLower(token)+"/"+strings.ToLower(owner)]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+
+func (n *Node) handleCall(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var msg struct {
+		To   string `json:"to"`
+		Data string `json:"data"`
+	}
+	if len(params) < 1 || json.Unmarshal(params[0], &msg) != nil || len(msg.Data) < 10 {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "invalid params"}
+	}
+	if msg.Data == selectorDecimals {
+		n.mu.Lock()
+		decimals, ok := n.decimals[strings.ToLower(msg.To)]
+		n.mu.Unlock()
+		if !ok {
+			return nil, &jsonrpc.Error{Code: 3, Message: "execution reverted"}
+		}
+		return "0x" + word(big.NewInt(int64(decimals))), nil
+	}
+	if len(msg.Data) < 10+64 {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "invalid params"}
+	}
+	arg, _ := new(big.Int).SetString(msg.Data[10:74], 16)
+	switch msg.Data[:10] {
+	case selectorOwnerOf:
+		n.mu.Lock()
+		owner := n.owners[strings.ToLower(msg.To)+"/"+arg.String()]
+		n.mu.Unlock()
+		if owner == "" {
+			return nil, &jsonrpc.Error{Code: 3, Message: "execution reverted: invalid token ID"}
+		}
+		return "0x" + addressWord(owner), nil
+	case selectorBalanceOf:
+		owner := "0x" + msg.Data[34:74]
+		return "0x" + word(n.balance(msg.To, owner)), nil
+	}
+	return nil, &jsonrpc.Error{Code: 3, Message: "execution reverted"}
+}
+
+func (n *Node) handleGetLogs(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var q struct {
+		FromBlock string          `json:"fromBlock"`
+		ToBlock   string          `json:"toBlock"`
+		Address   []string        `json:"address"`
+		Topics    [][]interface{} `json:"topics"`
+	}
+	if len(params) < 1 || json.Unmarshal(params[0], &q) != nil {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "invalid params"}
+	}
+	from, err1 := decodeUint64(q.FromBlock)
+	to, err2 := decodeUint64(q.ToBlock)
+	if err1 != nil || err2 != nil || to < from {
+		return nil, &jsonrpc.Error{Code: -32602, Message: "invalid block range"}
+	}
+	if to-from+1 > MaxBlockRange {
+		return nil, &jsonrpc.Error{Code: -32005, Message: "block range too large"}
+	}
+	addresses := make(map[string]bool, len(q.Address))
+	for _, a := range q.Address {
+		addresses[strings.ToLower(a)] = true
+	}
+	topics := make(map[string]bool)
+	if len(q.Topics) > 0 {
+		for _, t := range q.Topics[0] {
+			if s, ok := t.(string); ok {
+				topics[strings.ToLower(s)] = true
+			}
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	result := []rpcLog{}
+	for _, l := range n.logs {
+		if l.BlockNumber < from || l.BlockNumber > to {
+			continue
+		}
+		if len(addresses) > 0 && !addresses[strings.ToLower(l.Address)] {
+			continue
+		}
+		if len(topics) > 0 && (len(l.Topics) == 0 || !topics[strings.ToLower(l.Topics[0])]) {
+			continue
+		}
+		result = append(result, toRPCLog(l))
+	}
+	return result, nil
+}
+
+func toRPCLog(l evm.Log) rpcLog {
+	return rpcLog{
+		Address:     l.Address,
+		Topics:      l.Topics,
+		Data:        "0x" + hex.EncodeToString(l.Data),
+		BlockNumber: encodeUint64(l.BlockNumber),
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TxHash,
+		LogIndex:    encodeUint64(uint64(l.LogIndex)),
+	}
+}
+
+func paramQuantity(params []json.RawMessage, i int) (uint64, *jsonrpc.Error) {
+	var q string
+	if len(params) <= i || json.Unmarshal(params[i], &q) != nil {
+		return 0, &jsonrpc.Error{Code: -32602, Message: "invalid params"}
+	}
+	n, err := decodeUint64(q)
+	if err != nil {
+		return 0, &jsonrpc.Error{Code: -32602, Message: "invalid quantity"}
+	}
+	return n, nil
+}
+
+func keccak(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// selector 函数签名的 4 字节选择器
+func selector(signature string) string {
+	return "0x" + hex.EncodeToString(keccak([]byte(signature))[:4])
+}
+
+func encodeUint64(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+func encodeBig(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
+
+func decodeUint64(s string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+}
+
+// word 把整数编码为 32 字节的 ABI 参数
+func word(n *big.Int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+// addressWord 把地址编码为 32 字节的 ABI 参数
+func addressWord(addr string) string {
+	return strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(addr, "0x"))
+}
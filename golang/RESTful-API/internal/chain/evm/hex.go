@@ -0,0 +1,77 @@
+package evm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// quantity JSON-RPC 中 0x 开头的十六进制整数
+type quantity string
+
+func (q quantity) Uint64() (uint64, error) {
+	s := strings.TrimPrefix(string(q), "0x")
+	if s == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+	return strconv.ParseUint(s, 16, 64)
+}
+
+func (q quantity) Big() (*big.Int, error) {
+	s := strings.TrimPrefix(string(q), "0x")
+	if s == "" {
+		return new(big.Int), nil
+	}
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", q)
+	}
+	return n, nil
+}
+
+func encodeUint64(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+func encodeBig(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
+
+// decodeHex 解码 0x 开头的十六进制数据
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(s, "0x")
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	return hex.DecodeString(s)
+}
+
+// isHash 是否为 0x 开头的 32 字节哈希
+func isHash(s string) bool {
+	if len(s) != 66 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
+
+// word 把整数编码为 32 字节的 ABI 参数
+func word(n *big.Int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+// addressWord 把地址编码为 32 字节的 ABI 参数
+func addressWord(addr string) string {
+	return strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(addr, "0x"))
+}
+
+// ParseTokenID 解析十进制或 0x 开头的十六进制 tokenId
+func ParseTokenID(tokenID string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(tokenID, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid token id %q", tokenID)
+	}
+	return n, nil
+}
@@ -0,0 +1,104 @@
+package evm
+
+import (
+	"RESTful-API/internal/chain"
+	"context"
+)
+
+// LogQuery eth_getLogs 查询条件，Topics 为 topic0 的候选值
+type LogQuery struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Addresses []string
+	Topics    []string
+}
+
+// Log 合约日志
+type Log struct {
+	Address     string
+	Topics      []string
+	Data        []byte
+	BlockNumber uint64
+	BlockHash   string
+	TxHash      string
+	LogIndex    uint
+	Removed     bool
+}
+
+// Event 转换为通用的链上事件
+func (l Log) Event() chain.Event {
+	return chain.Event{
+		Chain:       chain.EVM,
+		Address:     l.Address,
+		TxHash:      l.TxHash,
+		BlockNumber: l.BlockNumber,
+		BlockHash:   l.BlockHash,
+		LogIndex:    l.LogIndex,
+		Topics:      l.Topics,
+		Data:        l.Data,
+		Raw:         l,
+	}
+}
+
+type rpcLog struct {
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+	BlockNumber quantity `json:"blockNumber"`
+	BlockHash   string   `json:"blockHash"`
+	TxHash      string   `json:"transactionHash"`
+	LogIndex    quantity `json:"logIndex"`
+	Removed     bool     `json:"removed"`
+}
+
+// Logs 查询区块范围内的日志
+func (c *Client) Logs(ctx context.Context, q LogQuery) ([]Log, error) {
+	params := map[string]interface{}{
+		"fromBlock": encodeUint64(q.FromBlock),
+		"toBlock":   encodeUint64(q.ToBlock),
+	}
+	if len(q.Addresses) > 0 {
+		params["address"] = q.Addresses
+	}
+	if len(q.Topics) > 0 {
+		params["topics"] = []interface{}{q.Topics}
+	}
+	var raw []rpcLog
+	if err := c.rpc.Call(ctx, &raw, "eth_getLogs", params); err != nil {
+		return nil, err
+	}
+	return convertLogs(raw)
+}
+
+func convertLogs(raw []rpcLog) ([]Log, error) {
+	logs := make([]Log, 0, len(raw))
+	for _, r := range raw {
+		addr, err := chain.NormalizeAddress(chain.EVM, r.Address)
+		if err != nil {
+			return nil, err
+		}
+		data, err := decodeHex(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		number, err := r.BlockNumber.Uint64()
+		if err != nil {
+			return nil, err
+		}
+		index, err := r.LogIndex.Uint64()
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, Log{
+			Address:     addr,
+			Topics:      r.Topics,
+			Data:        data,
+			BlockNumber: number,
+			BlockHash:   r.BlockHash,
+			TxHash:      r.TxHash,
+			LogIndex:    uint(index),
+			Removed:     r.Removed,
+		})
+	}
+	return logs, nil
+}
@@ -0,0 +1,111 @@
+// Package jsonrpc 链节点使用的 JSON-RPC 2.0 HTTP 客户端，EVM 与 Solana 共用
+package jsonrpc
+
+import (
+	"RESTful-API/internal/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// maxResponseSize 单次响应的最大字节数，防止异常节点返回超大数据
+const maxResponseSize = 32 << 20
+
+// Error 节点返回的 JSON-RPC 错误
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// Client JSON-RPC 客户端
+type Client struct {
+	url     string
+	headers map[string]string
+	http    *http.Client
+	id      atomic.Uint64
+}
+
+// New 创建客户端，system 用于 tracing 标记调用的外部系统，例如 evm-rpc
+func New(url, system string, timeout time.Duration, headers map[string]string) *Client {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Client{
+		url:     url,
+		headers: headers,
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: tracing.NewTransport(nil, system),
+		},
+	}
+}
+
+type request struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Call 调用 method 并把 result 解析到 result 中；result 为 JSON null 时 result 保持不变
+func (c *Client) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(request{JSONRPC: "2.0", ID: c.id.Add(1), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: http status %d", method, resp.StatusCode)
+	}
+
+	var r response
+	if err = json.Unmarshal(data, &r); err != nil {
+		return fmt.Errorf("%s: decode response: %w", method, err)
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+	if result == nil || len(r.Result) == 0 || string(r.Result) == "null" {
+		return nil
+	}
+	if err = json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("%s: decode result: %w", method, err)
+	}
+	return nil
+}
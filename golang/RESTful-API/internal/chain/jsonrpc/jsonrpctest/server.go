@@ -0,0 +1,76 @@
+// Package jsonrpctest 测试用的本地 JSON-RPC 节点替身
+package jsonrpctest
+
+import (
+	"RESTful-API/internal/chain/jsonrpc"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Handler 处理一个 JSON-RPC 方法，返回的 *Error 会原样写回客户端
+type Handler func(params []json.RawMessage) (interface{}, *jsonrpc.Error)
+
+// Server 本地 JSON-RPC 节点替身，各链的测试节点基于它实现
+type Server struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	calls    map[string]int
+}
+
+// NewServer 创建空的替身节点，通过 Handle 注册方法
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]Handler), calls: make(map[string]int)}
+}
+
+// Handle 注册方法
+func (s *Server) Handle(method string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = h
+}
+
+// Calls 返回方法被调用的次数
+func (s *Server) Calls(method string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.calls[method]
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	resp := map[string]interface{}{"jsonrpc": "2.0"}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp["id"] = nil
+		resp["error"] = &jsonrpc.Error{Code: -32700, Message: "parse error"}
+		writeJSON(w, resp)
+		return
+	}
+	resp["id"] = req.ID
+
+	s.mu.Lock()
+	h, ok := s.handlers[req.Method]
+	s.calls[req.Method]++
+	s.mu.Unlock()
+	if !ok {
+		resp["error"] = &jsonrpc.Error{Code: -32601, Message: "method not found"}
+		writeJSON(w, resp)
+		return
+	}
+	result, rpcErr := h(req.Params)
+	if rpcErr != nil {
+		resp["error"] = rpcErr
+	} else {
+		resp["result"] = result
+	}
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,75 @@
+package chain
+
+import (
+	"RESTful-API/utils/config"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Factory 按节点配置创建客户端，各链实现在 init 中通过 RegisterFactory 注册
+type Factory func(endpoint Endpoint) (Client, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[Chain]Factory)
+	clients   = make(map[Chain]Client)
+)
+
+// RegisterFactory 注册链的客户端实现
+func RegisterFactory(c Chain, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[c] = f
+}
+
+// Register 直接注册客户端，测试中用于替换为 fake
+func Register(client Client) {
+	mu.Lock()
+	defer mu.Unlock()
+	clients[client.Chain()] = client
+}
+
+// Get 返回链的客户端，首次调用时按 chain.ini 创建；未配置节点时返回 ErrNotConfigured
+func Get(c Chain) (Client, error) {
+	mu.RLock()
+	client, ok := clients[c]
+	mu.RUnlock()
+	if ok {
+		return client, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if client, ok = clients[c]; ok {
+		return client, nil
+	}
+	f, ok := factories[c]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no implementation", ErrNotConfigured, c)
+	}
+	endpoint := EndpointFromConfig(c)
+	if endpoint.URL == "" {
+		return nil, fmt.Errorf("%w: chain.%s.url is empty", ErrNotConfigured, c)
+	}
+	client, err := f(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	clients[c] = client
+	return client, nil
+}
+
+// EndpointFromConfig 读取 chain.ini 中的节点配置
+func EndpointFromConfig(c Chain) Endpoint {
+	key := "chain." + string(c) + "."
+	return Endpoint{
+		URL:           config.GetConfig(key + "url").String(),
+		APIKey:        config.GetConfig(key + "apiKey").String(),
+		ChainID:       config.GetConfig(key + "chainId").MustInt64(0),
+		WETH:          config.GetConfig(key + "weth").String(),
+		Confirmations: config.GetConfig(key + "confirmations").MustUint64(0),
+		Timeout:       time.Duration(config.GetConfig("chain.timeout").MustInt(10000)) * time.Millisecond,
+		PollInterval:  time.Duration(config.GetConfig("chain.pollInterval").MustInt(5000)) * time.Millisecond,
+	}
+}
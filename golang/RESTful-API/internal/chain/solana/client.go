@@ -0,0 +1,320 @@
+// Package solana Solana 的 chain.Client 实现，通过 Solana JSON-RPC 访问节点
+package solana
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/jsonrpc"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Commitment 查询使用的确认级别
+const Commitment = "confirmed"
+
+// maxSignatures getSignaturesForAddress 单次返回的最大条数
+const maxSignatures = 1000
+
+func init() {
+	chain.RegisterFactory(chain.Solana, func(endpoint chain.Endpoint) (chain.Client, error) {
+		return New(endpoint), nil
+	})
+}
+
+// Client Solana 节点客户端
+type Client struct {
+	rpc          *jsonrpc.Client
+	pollInterval time.Duration
+}
+
+var _ chain.Client = (*Client)(nil)
+
+// New 创建客户端，APIKey 不为空时作为 Authorization: Bearer 发送
+func New(endpoint chain.Endpoint) *Client {
+	var headers map[string]string
+	if endpoint.APIKey != "" {
+		headers = map[string]string{"Authorization": "Bearer " + endpoint.APIKey}
+	}
+	return &Client{
+		rpc:          jsonrpc.New(endpoint.URL, "solana-rpc", endpoint.Timeout, headers),
+		pollInterval: endpoint.PollInterval,
+	}
+}
+
+func (c *Client) Chain() chain.Chain {
+	return chain.Solana
+}
+
+// BlockHeight 返回当前 slot
+func (c *Client) BlockHeight(ctx context.Context) (uint64, error) {
+	var slot uint64
+	err := c.rpc.Call(ctx, &slot, "getSlot", map[string]string{"commitment": Commitment})
+	return slot, err
+}
+
+// Transaction 查询交易，From 为手续费支付者，Value 为支付者转出的 lamports（不含手续费）
+func (c *Client) Transaction(ctx context.Context, signature string) (*chain.Transaction, error) {
+	tx, err := c.RawTransaction(ctx, signature)
+	if err != nil {
+		return nil, err
+	}
+	result := &chain.Transaction{
+		Hash:        signature,
+		BlockNumber: tx.Slot,
+		Success:     !tx.Failed(),
+		Value:       new(big.Int),
+	}
+	if tx.BlockTime != nil {
+		result.Time = time.Unix(*tx.BlockTime, 0)
+	}
+	keys := tx.Transaction.Message.AccountKeys
+	if len(keys) > 0 {
+		result.From = keys[0].Pubkey
+	}
+	if len(keys) > 1 {
+		result.To = keys[1].Pubkey
+	}
+	if m := tx.Meta; m != nil && len(m.PreBalances) > 0 && len(m.PostBalances) > 0 {
+		spent := int64(m.PreBalances[0]) - int64(m.PostBalances[0]) - int64(m.Fee)
+		if spent > 0 {
+			result.Value.SetInt64(spent)
+		}
+	}
+
+	head, err := c.BlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head >= tx.Slot {
+		result.Confirmations = head - tx.Slot + 1
+	}
+	return result, nil
+}
+
+// RawTransaction 查询 jsonParsed 格式的交易，不存在时返回 chain.ErrTxNotFound
+func (c *Client) RawTransaction(ctx context.Context, signature string) (*Transaction, error) {
+	var tx *Transaction
+	opts := map[string]interface{}{
+		"encoding":                       "jsonParsed",
+		"commitment":                     Commitment,
+		"maxSupportedTransactionVersion": 0,
+	}
+	if err := c.rpc.Call(ctx, &tx, "getTransaction", signature, opts); err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("%w: %s", chain.ErrTxNotFound, signature)
+	}
+	return tx, nil
+}
+
+// TokenBalance token 为空时查询 SOL 余额（lamports），否则汇总 owner 持有该 mint 的全部 token 账户
+func (c *Client) TokenBalance(ctx context.Context, owner, mint string) (*big.Int, error) {
+	if _, err := chain.NormalizeAddress(chain.Solana, owner); err != nil {
+		return nil, err
+	}
+	if mint == "" {
+		var balance struct {
+			Value uint64 `json:"value"`
+		}
+		if err := c.rpc.Call(ctx, &balance, "getBalance", owner, map[string]string{"commitment": Commitment}); err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetUint64(balance.Value), nil
+	}
+
+	var accounts struct {
+		Value []struct {
+			Pubkey  string        `json:"pubkey"`
+			Account parsedAccount `json:"account"`
+		} `json:"value"`
+	}
+	err := c.rpc.Call(ctx, &accounts, "getTokenAccountsByOwner", owner,
+		map[string]string{"mint": mint},
+		map[string]string{"encoding": "jsonParsed", "commitment": Commitment})
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for _, a := range accounts.Value {
+		amount, ok := new(big.Int).SetString(a.Account.Data.Parsed.Info.TokenAmount.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid token amount in %s", a.Pubkey)
+		}
+		total.Add(total, amount)
+	}
+	return total, nil
+}
+
+// NFTOwner Solana 上一个 NFT 就是一个供应量为 1 的 mint，tokenID 为 mint 地址，contract（collection）不参与查询。
+// 查找余额为 1 的 token 账户，返回该账户的所有者
+func (c *Client) NFTOwner(ctx context.Context, contract, mint string) (string, error) {
+	if _, err := chain.NormalizeAddress(chain.Solana, mint); err != nil {
+		return "", err
+	}
+	largest, err := c.largestAccounts(ctx, mint)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range largest {
+		if a.Amount != "1" {
+			continue
+		}
+		account, err := c.TokenAccount(ctx, a.Address)
+		if err != nil {
+			return "", err
+		}
+		return account.Owner, nil
+	}
+	return "", fmt.Errorf("%w: mint %s", chain.ErrNotFound, mint)
+}
+
+// MintTokenAccounts 返回持有 mint 的 token 账户地址，包括余额为 0 的账户。
+// getTokenLargestAccounts 最多返回 20 个账户，已关闭的账户不在其中
+func (c *Client) MintTokenAccounts(ctx context.Context, mint string) ([]string, error) {
+	largest, err := c.largestAccounts(ctx, mint)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(largest))
+	for _, a := range largest {
+		addresses = append(addresses, a.Address)
+	}
+	return addresses, nil
+}
+
+type largestAccount struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+}
+
+func (c *Client) largestAccounts(ctx context.Context, mint string) ([]largestAccount, error) {
+	var largest struct {
+		Value []largestAccount `json:"value"`
+	}
+	if err := c.rpc.Call(ctx, &largest, "getTokenLargestAccounts", mint, map[string]string{"commitment": Commitment}); err != nil {
+		return nil, err
+	}
+	return largest.Value, nil
+}
+
+// TokenAccount 查询 SPL token 账户
+func (c *Client) TokenAccount(ctx context.Context, address string) (*TokenAccount, error) {
+	var info struct {
+		Value *parsedAccount `json:"value"`
+	}
+	err := c.rpc.Call(ctx, &info, "getAccountInfo", address,
+		map[string]string{"encoding": "jsonParsed", "commitment": Commitment})
+	if err != nil {
+		return nil, err
+	}
+	if info.Value == nil || info.Value.Data.Parsed.Type != "account" {
+		return nil, fmt.Errorf("%w: token account %s", chain.ErrNotFound, address)
+	}
+	parsed := info.Value.Data.Parsed.Info
+	return &TokenAccount{Address: address, Mint: parsed.Mint, Owner: parsed.Owner, Amount: parsed.TokenAmount.Amount}, nil
+}
+
+// AccountData 查询账户的原始数据（base64 解码后），账户不存在时返回 chain.ErrNotFound
+func (c *Client) AccountData(ctx context.Context, address string) ([]byte, error) {
+	var info struct {
+		Value *struct {
+			Data []string `json:"data"`
+		} `json:"value"`
+	}
+	err := c.rpc.Call(ctx, &info, "getAccountInfo", address,
+		map[string]string{"encoding": "base64", "commitment": Commitment})
+	if err != nil {
+		return nil, err
+	}
+	if info.Value == nil || len(info.Value.Data) == 0 {
+		return nil, fmt.Errorf("%w: account %s", chain.ErrNotFound, address)
+	}
+	return decodeBase64(info.Value.Data[0])
+}
+
+// SignaturesForAddress 按时间倒序返回涉及 address 的交易签名，until 为上次处理到的签名（不含），before 用于向前翻页
+func (c *Client) SignaturesForAddress(ctx context.Context, address, before, until string, limit int) ([]Signature, error) {
+	if limit <= 0 || limit > maxSignatures {
+		limit = maxSignatures
+	}
+	opts := map[string]interface{}{"limit": limit, "commitment": Commitment}
+	if before != "" {
+		opts["before"] = before
+	}
+	if until != "" {
+		opts["until"] = until
+	}
+	var sigs []Signature
+	if err := c.rpc.Call(ctx, &sigs, "getSignaturesForAddress", address, opts); err != nil {
+		return nil, err
+	}
+	return sigs, nil
+}
+
+// Subscribe 轮询 getSignaturesForAddress，每个签名产生一个事件，按 slot 从旧到新发送。
+// FromBlock 为 0 时只关注订阅之后的新交易
+func (c *Client) Subscribe(ctx context.Context, filter chain.EventFilter) (*chain.Subscription, error) {
+	last := make(map[string]string, len(filter.Addresses))
+	if filter.FromBlock == 0 {
+		for _, addr := range filter.Addresses {
+			sigs, err := c.SignaturesForAddress(ctx, addr, "", "", 1)
+			if err != nil {
+				return nil, err
+			}
+			if len(sigs) > 0 {
+				last[addr] = sigs[0].Signature
+			}
+		}
+	}
+	return chain.Poll(ctx, c.pollInterval, func(ctx context.Context) ([]chain.Event, error) {
+		var events []chain.Event
+		for _, addr := range filter.Addresses {
+			sigs, err := c.SignaturesForAddress(ctx, addr, "", last[addr], 0)
+			if err != nil {
+				return events, err
+			}
+			if len(sigs) == 0 {
+				continue
+			}
+			last[addr] = sigs[0].Signature
+			for i := len(sigs) - 1; i >= 0; i-- {
+				s := sigs[i]
+				if s.Slot < filter.FromBlock {
+					continue
+				}
+				events = append(events, chain.Event{
+					Chain:       chain.Solana,
+					Address:     addr,
+					TxHash:      s.Signature,
+					BlockNumber: s.Slot,
+					Raw:         s,
+				})
+			}
+		}
+		return events, nil
+	}), nil
+}
+
+// Signature getSignaturesForAddress 的返回项
+type Signature struct {
+	Signature string          `json:"signature"`
+	Slot      uint64          `json:"slot"`
+	Err       json.RawMessage `json:"err"`
+	BlockTime *int64          `json:"blockTime"`
+}
+
+// Failed 交易是否执行失败
+func (s Signature) Failed() bool {
+	return len(s.Err) > 0 && string(s.Err) != "null"
+}
+
+// TokenAccount SPL token 账户
+type TokenAccount struct {
+	Address string
+	Mint    string
+	Owner   string
+	Amount  string
+}
@@ -0,0 +1,112 @@
+package solana_test
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/solana"
+	"RESTful-API/internal/chain/solana/solanatest"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	payer     = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	recipient = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	mint      = "So11111111111111111111111111111111111111112"
+)
+
+func transfer(pre, post, fee uint64, failed bool) *solana.Transaction {
+	tx := &solana.Transaction{Meta: &solana.TransactionMeta{
+		Err:          json.RawMessage("null"),
+		Fee:          fee,
+		PreBalances:  []uint64{pre, 0},
+		PostBalances: []uint64{post, pre - post - fee},
+	}}
+	if failed {
+		tx.Meta.Err = json.RawMessage(`{"InstructionError":[0,"Custom"]}`)
+	}
+	tx.Transaction.Message.AccountKeys = []solana.AccountKey{{Pubkey: payer, Signer: true}, {Pubkey: recipient}}
+	return tx
+}
+
+func TestClientTransaction(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	ctx := context.Background()
+	client := node.Client()
+
+	node.AddTransaction("sig-ok", transfer(1_000_000, 400_000, 5_000, false), payer)
+	node.AddTransaction("sig-failed", transfer(1_000_000, 995_000, 5_000, true), payer)
+	node.Advance(9)
+
+	tx, err := client.Transaction(ctx, "sig-ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Value 为支付者转出的 lamports，不含手续费
+	if tx.From != payer || tx.To != recipient || tx.Value.Int64() != 595_000 ||
+		!tx.Success || tx.BlockNumber != 1 || tx.Confirmations != 10 {
+		t.Fatalf("unexpected transaction %+v", tx)
+	}
+	if tx, err = client.Transaction(ctx, "sig-failed"); err != nil || tx.Success {
+		t.Fatalf("failed transaction: %+v, %v", tx, err)
+	}
+	if _, err = client.Transaction(ctx, "missing"); !errors.Is(err, chain.ErrTxNotFound) {
+		t.Fatalf("got %v, want ErrTxNotFound", err)
+	}
+}
+
+func TestClientBalancesAndOwner(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	ctx := context.Background()
+	client := node.Client()
+
+	node.SetLamports(payer, 42)
+	node.SetTokenAccount(solana.TokenAccount{Address: "acc-1", Mint: mint, Owner: payer, Amount: "3"})
+	node.SetTokenAccount(solana.TokenAccount{Address: "acc-2", Mint: mint, Owner: payer, Amount: "4"})
+	if b, err := client.TokenBalance(ctx, payer, ""); err != nil || b.Int64() != 42 {
+		t.Fatalf("SOL balance = %v, %v", b, err)
+	}
+	// 同一个 mint 的多个 token 账户合计
+	if b, err := client.TokenBalance(ctx, payer, mint); err != nil || b.Int64() != 7 {
+		t.Fatalf("token balance = %v, %v", b, err)
+	}
+
+	if _, err := client.NFTOwner(ctx, "", recipient); !errors.Is(err, chain.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+	node.SetTokenAccount(solana.TokenAccount{Address: "nft-acc", Mint: recipient, Owner: payer, Amount: "1"})
+	if owner, err := client.NFTOwner(ctx, "", recipient); err != nil || owner != payer {
+		t.Fatalf("NFTOwner = %q, %v", owner, err)
+	}
+}
+
+func TestClientSubscribe(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := node.Client()
+
+	node.AddTransaction("sig-old", transfer(10, 5, 1, false), payer)
+	sub, err := client.Subscribe(ctx, chain.EventFilter{Addresses: []string{payer}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	// FromBlock 为 0 时订阅前的交易不推送
+	node.Advance(1)
+	node.AddTransaction("sig-new", transfer(10, 5, 1, false), payer)
+	select {
+	case e := <-sub.Events():
+		if e.Chain != chain.Solana || e.TxHash != "sig-new" || e.BlockNumber != 2 {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case <-ctx.Done():
+		t.Fatal("no event received")
+	}
+}
@@ -0,0 +1,180 @@
+package solana
+
+import (
+	"RESTful-API/internal/chain"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"filippo.io/edwards25519"
+	"github.com/mr-tron/base58"
+	"strings"
+)
+
+// MetadataProgramID Metaplex Token Metadata 程序地址
+const MetadataProgramID = "metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s"
+
+var (
+	ErrNoPDA             = errors.New("unable to find a viable program address")
+	ErrMetadataMalformed = errors.New("malformed metaplex metadata account")
+)
+
+// Metadata Metaplex metadata 账户中索引需要的字段
+type Metadata struct {
+	UpdateAuthority    string
+	Mint               string
+	Name               string
+	Symbol             string
+	URI                string
+	Collection         string // 所属 collection 的 mint，没有时为空
+	CollectionVerified bool
+}
+
+// FindProgramAddress 按 Solana 规则推导 PDA：从 bump 255 开始，取第一个不在 ed25519 曲线上的哈希
+func FindProgramAddress(seeds [][]byte, programID string) (string, uint8, error) {
+	program, err := base58.Decode(programID)
+	if err != nil || len(program) != 32 {
+		return "", 0, chain.ErrInvalidAddress
+	}
+	for bump := 255; bump >= 0; bump-- {
+		h := sha256.New()
+		for _, s := range seeds {
+			h.Write(s)
+		}
+		h.Write([]byte{byte(bump)})
+		h.Write(program)
+		h.Write([]byte("ProgramDerivedAddress"))
+		sum := h.Sum(nil)
+		if _, err := new(edwards25519.Point).SetBytes(sum); err != nil {
+			return base58.Encode(sum), uint8(bump), nil
+		}
+	}
+	return "", 0, ErrNoPDA
+}
+
+// MetadataAddress mint 对应的 metadata 账户地址
+func MetadataAddress(mint string) (string, error) {
+	mintKey, err := base58.Decode(mint)
+	if err != nil || len(mintKey) != 32 {
+		return "", chain.ErrInvalidAddress
+	}
+	program, _ := base58.Decode(MetadataProgramID)
+	addr, _, err := FindProgramAddress([][]byte{[]byte("metadata"), program, mintKey}, MetadataProgramID)
+	return addr, err
+}
+
+// Metadata 查询 mint 的 Metaplex metadata，不存在时返回 chain.ErrNotFound
+func (c *Client) Metadata(ctx context.Context, mint string) (*Metadata, error) {
+	addr, err := MetadataAddress(mint)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.AccountData(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMetadata(data)
+}
+
+// ParseMetadata 解析 metadata 账户数据（Borsh 编码）：
+// key u8, update_authority [32], mint [32], name/symbol/uri string, seller_fee_basis_points u16,
+// creators Option<Vec<Creator>>, primary_sale_happened bool, is_mutable bool,
+// edition_nonce Option<u8>, token_standard Option<u8>, collection Option<{verified bool, key [32]}>
+func ParseMetadata(data []byte) (*Metadata, error) {
+	r := &borshReader{data: data}
+	if key := r.u8(); key != 4 { // 4 = MetadataV1
+		return nil, ErrMetadataMalformed
+	}
+	m := &Metadata{
+		UpdateAuthority: r.pubkey(),
+		Mint:            r.pubkey(),
+		Name:            trimPadding(r.str()),
+		Symbol:          trimPadding(r.str()),
+		URI:             trimPadding(r.str()),
+	}
+	r.skip(2) // seller_fee_basis_points
+	if r.u8() == 1 {
+		n := r.u32()
+		if n > 5 { // Metaplex 限制最多 5 个 creator
+			return nil, ErrMetadataMalformed
+		}
+		r.skip(int(n) * 34) // address [32], verified bool, share u8
+	}
+	if r.err != nil {
+		return nil, ErrMetadataMalformed
+	}
+	// 以下字段在旧版本的账户中可能不存在，读取失败时忽略
+	r.skip(2) // primary_sale_happened, is_mutable
+	if r.u8() == 1 {
+		r.skip(1) // edition_nonce
+	}
+	if r.u8() == 1 {
+		r.skip(1) // token_standard
+	}
+	if r.u8() == 1 {
+		verified := r.u8() == 1
+		collection := r.pubkey()
+		if r.err == nil {
+			m.Collection, m.CollectionVerified = collection, verified
+		}
+	}
+	return m, nil
+}
+
+// trimPadding metadata 中的字符串按固定长度用 \x00 填充
+func trimPadding(s string) string {
+	return strings.TrimRight(s, "\x00")
+}
+
+type borshReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *borshReader) take(n int) []byte {
+	if r.err != nil || n < 0 || r.pos+n > len(r.data) {
+		r.err = ErrMetadataMalformed
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *borshReader) skip(n int) {
+	r.take(n)
+}
+
+func (r *borshReader) u8() uint8 {
+	b := r.take(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *borshReader) u32() uint32 {
+	b := r.take(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r *borshReader) pubkey() string {
+	b := r.take(32)
+	if b == nil {
+		return ""
+	}
+	return base58.Encode(b)
+}
+
+func (r *borshReader) str() string {
+	n := r.u32()
+	if n > 1024 {
+		r.err = ErrMetadataMalformed
+		return ""
+	}
+	return string(r.take(int(n)))
+}
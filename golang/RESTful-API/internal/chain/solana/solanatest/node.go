@@ -0,0 +1,260 @@
+// Package solanatest 测试用的本地 Solana 节点替身
+package solanatest
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/jsonrpc"
+	"RESTful-API/internal/chain/jsonrpc/jsonrpctest"
+	"RESTful-API/internal/chain/solana"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// maxSignatures getSignaturesForAddress 单次最多返回的签名数，与节点的限制一致
+const maxSignatures = 1000
+
+// Node 本地 Solana 节点替身，在内存中维护 slot、交易、签名列表和账户
+type Node struct {
+	server *httptest.Server
+	rpc    *jsonrpctest.Server
+
+	mu         sync.Mutex
+	slot       uint64
+	txs        map[string]*solana.Transaction
+	signatures map[string][]solana.Signature // address -> 签名，按时间倒序
+	lamports   map[string]uint64
+	tokens     map[string]solana.TokenAccount // token 账户地址 -> 账户
+	data       map[string][]byte              // 账户原始数据，例如 Metaplex metadata 账户
+}
+
+// NewNode 启动替身节点
+func NewNode() *Node {
+	n := &Node{
+		rpc:        jsonrpctest.NewServer(),
+		slot:       1,
+		txs:        make(map[string]*solana.Transaction),
+		signatures: make(map[string][]solana.Signature),
+		lamports:   make(map[string]uint64),
+		tokens:     make(map[string]solana.TokenAccount),
+		data:       make(map[string][]byte),
+	}
+	n.routes()
+	n.server = httptest.NewServer(n.rpc)
+	return n
+}
+
+// URL 节点地址
+func (n *Node) URL() string {
+	return n.server.URL
+}
+
+// Client 连接到替身节点的客户端
+func (n *Node) Client() *solana.Client {
+	return solana.New(chain.Endpoint{URL: n.URL(), PollInterval: 10 * time.Millisecond})
+}
+
+// Calls 返回 JSON-RPC 方法被调用的次数
+func (n *Node) Calls(method string) int {
+	return n.rpc.Calls(method)
+}
+
+// Close 关闭节点
+func (n *Node) Close() {
+	n.server.Close()
+}
+
+// Advance 推进 count 个 slot，返回当前 slot
+func (n *Node) Advance(count uint64) uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.slot += count
+	return n.slot
+}
+
+// AddTransaction 把交易放入当前 slot，并登记到 addresses 的签名列表中
+func (n *Node) AddTransaction(signature string, tx *solana.Transaction, addresses ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	tx.Slot = n.slot
+	if len(tx.Transaction.Signatures) == 0 {
+		tx.Transaction.Signatures = []string{signature}
+	}
+	if tx.Meta == nil {
+		tx.Meta = &solana.TransactionMeta{Err: json.RawMessage("null")}
+	}
+	n.txs[signature] = tx
+	sig := solana.Signature{Signature: signature, Slot: n.slot, Err: tx.Meta.Err, BlockTime: tx.BlockTime}
+	for _, addr := range addresses {
+		n.signatures[addr] = append([]solana.Signature{sig}, n.signatures[addr]...)
+	}
+}
+
+// SetLamports 设置 SOL 余额
+func (n *Node) SetLamports(owner string, lamports uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lamports[owner] = lamports
+}
+
+// SetTokenAccount 设置 SPL token 账户
+func (n *Node) SetTokenAccount(account solana.TokenAccount) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.tokens[account.Address] = account
+}
+
+// SetAccountData 设置账户的原始数据
+func (n *Node) SetAccountData(address string, data []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.data[address] = data
+}
+
+func (n *Node) routes() {
+	n.rpc.Handle("getSlot", func([]json.RawMessage) (interface{}, *jsonrpc.Error) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return n.slot, nil
+	})
+	n.rpc.Handle("getTransaction", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		sig, rpcErr := paramString(params, 0)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if tx, ok := n.txs[sig]; ok {
+			return tx, nil
+		}
+		return nil, nil
+	})
+	n.rpc.Handle("getBalance", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		owner, rpcErr := paramString(params, 0)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return map[string]interface{}{"context": map[string]uint64{"slot": n.slot}, "value": n.lamports[owner]}, nil
+	})
+	n.rpc.Handle("getTokenAccountsByOwner", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		owner, rpcErr := paramString(params, 0)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		var filter struct {
+			Mint string `json:"mint"`
+		}
+		if len(params) > 1 {
+			_ = json.Unmarshal(params[1], &filter)
+		}
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		value := []map[string]interface{}{}
+		for _, a := range n.tokens {
+			if a.Owner == owner && (filter.Mint == "" || a.Mint == filter.Mint) {
+				value = append(value, map[string]interface{}{"pubkey": a.Address, "account": parsedTokenAccount(a)})
+			}
+		}
+		return map[string]interface{}{"context": map[string]uint64{"slot": n.slot}, "value": value}, nil
+	})
+	n.rpc.Handle("getTokenLargestAccounts", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		mint, rpcErr := paramString(params, 0)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		value := []map[string]string{}
+		for _, a := range n.tokens {
+			if a.Mint == mint {
+				value = append(value, map[string]string{"address": a.Address, "amount": a.Amount})
+			}
+		}
+		return map[string]interface{}{"context": map[string]uint64{"slot": n.slot}, "value": value}, nil
+	})
+	n.rpc.Handle("getAccountInfo", func(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+		addr, rpcErr := paramString(params, 0)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		var opts struct {
+			Encoding string `json:"encoding"`
+		}
+		if len(params) > 1 {
+			_ = json.Unmarshal(params[1], &opts)
+		}
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		result := map[string]interface{}{"context": map[string]uint64{"slot": n.slot}, "value": nil}
+		if a, ok := n.tokens[addr]; ok && opts.Encoding == "jsonParsed" {
+			result["value"] = parsedTokenAccount(a)
+		} else if data, ok := n.data[addr]; ok {
+			result["value"] = map[string]interface{}{"data": []string{base64.StdEncoding.EncodeToString(data), "base64"}}
+		}
+		return result, nil
+	})
+	n.rpc.Handle("getSignaturesForAddress", n.handleSignatures)
+}
+
+func (n *Node) handleSignatures(params []json.RawMessage) (interface{}, *jsonrpc.Error) {
+	addr, rpcErr := paramString(params, 0)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	var opts struct {
+		Limit  int    `json:"limit"`
+		Before string `json:"before"`
+		Until  string `json:"until"`
+	}
+	if len(params) > 1 {
+		_ = json.Unmarshal(params[1], &opts)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = maxSignatures
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	result := []solana.Signature{}
+	started := opts.Before == ""
+	for _, s := range n.signatures[addr] {
+		if !started {
+			started = s.Signature == opts.Before
+			continue
+		}
+		if s.Signature == opts.Until || len(result) >= opts.Limit {
+			break
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+func parsedTokenAccount(a solana.TokenAccount) map[string]interface{} {
+	return map[string]interface{}{
+		"owner": solana.TokenProgramID,
+		"data": map[string]interface{}{
+			"program": "spl-token",
+			"parsed": map[string]interface{}{
+				"type": "account",
+				"info": map[string]interface{}{
+					"mint":        a.Mint,
+					"owner":       a.Owner,
+					"tokenAmount": map[string]interface{}{"amount": a.Amount, "decimals": 0},
+				},
+			},
+		},
+	}
+}
+
+func paramString(params []json.RawMessage, i int) (string, *jsonrpc.Error) {
+	var s string
+	if len(params) <= i || json.Unmarshal(params[i], &s) != nil {
+		return "", &jsonrpc.Error{Code: -32602, Message: "invalid params"}
+	}
+	return s, nil
+}
@@ -0,0 +1,95 @@
+package solana
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Transaction getTransaction 以 jsonParsed 编码返回的交易
+type Transaction struct {
+	Slot        uint64           `json:"slot"`
+	BlockTime   *int64           `json:"blockTime"`
+	Meta        *TransactionMeta `json:"meta"`
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			AccountKeys  []AccountKey  `json:"accountKeys"`
+			Instructions []Instruction `json:"instructions"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+// Failed 交易是否执行失败
+func (t *Transaction) Failed() bool {
+	return t.Meta == nil || len(t.Meta.Err) > 0 && string(t.Meta.Err) != "null"
+}
+
+// TransactionMeta 交易执行结果
+type TransactionMeta struct {
+	Err               json.RawMessage    `json:"err"`
+	Fee               uint64             `json:"fee"`
+	PreBalances       []uint64           `json:"preBalances"`
+	PostBalances      []uint64           `json:"postBalances"`
+	PreTokenBalances  []TokenBalance     `json:"preTokenBalances"`
+	PostTokenBalances []TokenBalance     `json:"postTokenBalances"`
+	InnerInstructions []InnerInstruction `json:"innerInstructions"`
+}
+
+// AccountKey 交易涉及的账户
+type AccountKey struct {
+	Pubkey   string `json:"pubkey"`
+	Signer   bool   `json:"signer"`
+	Writable bool   `json:"writable"`
+}
+
+// Instruction jsonParsed 编码的指令，能被节点解析的指令（如 spl-token）带有 Parsed
+type Instruction struct {
+	Program   string          `json:"program"`
+	ProgramID string          `json:"programId"`
+	Parsed    json.RawMessage `json:"parsed"`
+	Accounts  []string        `json:"accounts"`
+	Data      string          `json:"data"`
+}
+
+// InnerInstruction 跨程序调用产生的内部指令
+type InnerInstruction struct {
+	Index        int           `json:"index"`
+	Instructions []Instruction `json:"instructions"`
+}
+
+// TokenBalance 交易前后的 token 余额
+type TokenBalance struct {
+	AccountIndex  int    `json:"accountIndex"`
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UITokenAmount struct {
+		Amount   string `json:"amount"`
+		Decimals int    `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+// parsedAccount jsonParsed 编码的 SPL token 账户
+type parsedAccount struct {
+	Owner string `json:"owner"` // 账户所属程序
+	Data  struct {
+		Program string `json:"program"`
+		Parsed  struct {
+			Type string `json:"type"`
+			Info struct {
+				Mint        string `json:"mint"`
+				Owner       string `json:"owner"`
+				TokenAmount struct {
+					Amount   string `json:"amount"`
+					Decimals int    `json:"decimals"`
+				} `json:"tokenAmount"`
+			} `json:"info"`
+		} `json:"parsed"`
+	} `json:"data"`
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// TokenProgramID SPL Token 程序地址
+const TokenProgramID = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
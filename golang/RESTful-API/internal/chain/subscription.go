@@ -0,0 +1,89 @@
+package chain
+
+import (
+	"context"
+	"time"
+)
+
+// Subscription 事件订阅，通过 Events 读取事件，Err 读取轮询中的错误
+type Subscription struct {
+	events chan Event
+	errs   chan error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSubscription 创建订阅，run 在独立的协程中执行，通过 send 发送事件，返回时订阅结束
+func NewSubscription(ctx context.Context, run func(ctx context.Context, send func(Event) bool, fail func(error))) *Subscription {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Subscription{
+		events: make(chan Event, 64),
+		errs:   make(chan error, 1),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	send := func(e Event) bool {
+		select {
+		case s.events <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	// 只保留最近一次错误，不阻塞轮询
+	fail := func(err error) {
+		select {
+		case s.errs <- err:
+		default:
+		}
+	}
+	go func() {
+		defer close(s.done)
+		defer close(s.events)
+		run(ctx, send, fail)
+	}()
+	return s
+}
+
+// Poll 按固定间隔调用 fetch 拉取新事件，fetch 出错时等待下一轮重试
+func Poll(ctx context.Context, interval time.Duration, fetch func(ctx context.Context) ([]Event, error)) *Subscription {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return NewSubscription(ctx, func(ctx context.Context, send func(Event) bool, fail func(error)) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			events, err := fetch(ctx)
+			if err != nil {
+				fail(err)
+			}
+			for _, e := range events {
+				if !send(e) {
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Events 事件通道，订阅结束后关闭
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err 轮询错误通道，订阅不会因为错误而结束
+func (s *Subscription) Err() <-chan error {
+	return s.errs
+}
+
+// Close 结束订阅并等待后台协程退出
+func (s *Subscription) Close() {
+	s.cancel()
+	<-s.done
+}
@@ -0,0 +1,344 @@
+// Package ton TON 的 chain.Client 实现，通过 toncenter v3 兼容的 HTTP API 访问
+package ton
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxResponseSize 单次响应的最大字节数
+	maxResponseSize = 32 << 20
+	// maxTransactions 单次查询的最大交易数
+	maxTransactions = 256
+)
+
+func init() {
+	chain.RegisterFactory(chain.TON, func(endpoint chain.Endpoint) (chain.Client, error) {
+		return New(endpoint), nil
+	})
+}
+
+// Client toncenter API 客户端，URL 为 API 根地址，例如 https://toncenter.com/api/v3
+type Client struct {
+	base         string
+	apiKey       string
+	http         *http.Client
+	pollInterval time.Duration
+}
+
+var _ chain.Client = (*Client)(nil)
+
+// New 创建客户端，APIKey 不为空时通过 X-API-Key 发送
+func New(endpoint chain.Endpoint) *Client {
+	timeout := endpoint.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Client{
+		base:   strings.TrimRight(endpoint.URL, "/"),
+		apiKey: endpoint.APIKey,
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: tracing.NewTransport(nil, "toncenter"),
+		},
+		pollInterval: endpoint.PollInterval,
+	}
+}
+
+func (c *Client) Chain() chain.Chain {
+	return chain.TON
+}
+
+// APIError toncenter 返回的错误
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("toncenter: http %d: %s", e.Status, e.Message)
+}
+
+// get 请求 path 并解析 JSON 响应
+func (c *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	u := c.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(data, &body)
+		return &APIError{Status: resp.StatusCode, Message: body.Error}
+	}
+	if err = json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("%s: decode response: %w", path, err)
+	}
+	return nil
+}
+
+// BlockHeight 返回最新 masterchain 区块的 seqno
+func (c *Client) BlockHeight(ctx context.Context) (uint64, error) {
+	var info struct {
+		Last struct {
+			Seqno uint64 `json:"seqno"`
+		} `json:"last"`
+	}
+	if err := c.get(ctx, "/masterchainInfo", nil, &info); err != nil {
+		return 0, err
+	}
+	return info.Last.Seqno, nil
+}
+
+// Transaction 按哈希（hex 或 base64）查询交易，From/To/Value 取自入站消息
+func (c *Client) Transaction(ctx context.Context, hash string) (*chain.Transaction, error) {
+	var resp transactionsResponse
+	if err := c.get(ctx, "/transactions", url.Values{"hash": {hash}}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Transactions) == 0 {
+		return nil, fmt.Errorf("%w: %s", chain.ErrTxNotFound, hash)
+	}
+	tx := resp.Transactions[0]
+	result := &chain.Transaction{
+		Hash:        tx.Hash,
+		BlockNumber: tx.McBlockSeqno,
+		Success:     tx.Success(),
+		Time:        time.Unix(tx.Now, 0),
+		Value:       new(big.Int),
+	}
+	if tx.InMsg != nil {
+		result.From = normalize(tx.InMsg.Source)
+		result.To = normalize(tx.InMsg.Destination)
+		if v, ok := new(big.Int).SetString(tx.InMsg.Value, 10); ok {
+			result.Value = v
+		}
+	}
+	if result.To == "" {
+		result.To = normalize(tx.Account)
+	}
+
+	head, err := c.BlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tx.McBlockSeqno > 0 && head >= tx.McBlockSeqno {
+		result.Confirmations = head - tx.McBlockSeqno + 1
+	}
+	return result, nil
+}
+
+// Transactions 按逻辑时间升序返回 account 在 startLt 之后（含）的交易
+func (c *Client) Transactions(ctx context.Context, account string, startLt uint64, limit int) ([]Transaction, error) {
+	if limit <= 0 || limit > maxTransactions {
+		limit = maxTransactions
+	}
+	query := url.Values{
+		"account": {account},
+		"sort":    {"asc"},
+		"limit":   {strconv.Itoa(limit)},
+	}
+	if startLt > 0 {
+		query.Set("start_lt", strconv.FormatUint(startLt, 10))
+	}
+	var resp transactionsResponse
+	if err := c.get(ctx, "/transactions", query, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Transactions, nil
+}
+
+// Trace 返回与 hash 属于同一个 trace 的全部交易，按执行顺序排列
+func (c *Client) Trace(ctx context.Context, hash string) ([]Transaction, error) {
+	var resp struct {
+		Traces []struct {
+			TraceID           string                 `json:"trace_id"`
+			Transactions      map[string]Transaction `json:"transactions"`
+			TransactionsOrder []string               `json:"transactions_order"`
+		} `json:"traces"`
+	}
+	if err := c.get(ctx, "/traces", url.Values{"tx_hash": {hash}}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Traces) == 0 {
+		return nil, fmt.Errorf("%w: %s", chain.ErrTxNotFound, hash)
+	}
+	trace := resp.Traces[0]
+	txs := make([]Transaction, 0, len(trace.TransactionsOrder))
+	for _, h := range trace.TransactionsOrder {
+		if tx, ok := trace.Transactions[h]; ok {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+// TokenBalance token 为空时查询 TON 余额（nanoton），否则查询 owner 持有的 jetton 数量
+func (c *Client) TokenBalance(ctx context.Context, owner, jetton string) (*big.Int, error) {
+	owner, err := chain.NormalizeAddress(chain.TON, owner)
+	if err != nil {
+		return nil, err
+	}
+	if jetton == "" {
+		var account struct {
+			Balance string `json:"balance"`
+		}
+		if err = c.get(ctx, "/account", url.Values{"address": {owner}}, &account); err != nil {
+			return nil, err
+		}
+		return parseAmount(account.Balance)
+	}
+	if jetton, err = chain.NormalizeAddress(chain.TON, jetton); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		JettonWallets []struct {
+			Balance string `json:"balance"`
+		} `json:"jetton_wallets"`
+	}
+	query := url.Values{"owner_address": {owner}, "jetton_address": {jetton}}
+	if err = c.get(ctx, "/jetton/wallets", query, &resp); err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for _, w := range resp.JettonWallets {
+		amount, err := parseAmount(w.Balance)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, amount)
+	}
+	return total, nil
+}
+
+// NFTOwner tokenID 为 item 在 collection 中的序号，或 item 自身的地址
+func (c *Client) NFTOwner(ctx context.Context, collection, tokenID string) (string, error) {
+	item, err := c.NFTItem(ctx, collection, tokenID)
+	if err != nil {
+		return "", err
+	}
+	return item.Owner, nil
+}
+
+// NFTItem 查询 NFT item，不存在时返回 chain.ErrNotFound
+func (c *Client) NFTItem(ctx context.Context, collection, tokenID string) (*NFTItem, error) {
+	query := url.Values{}
+	if _, err := strconv.ParseUint(tokenID, 10, 64); err == nil {
+		collection, err := chain.NormalizeAddress(chain.TON, collection)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("collection_address", collection)
+		query.Set("index", tokenID)
+	} else {
+		addr, err := chain.NormalizeAddress(chain.TON, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("address", addr)
+	}
+	var resp struct {
+		NFTItems []rawNFTItem `json:"nft_items"`
+	}
+	if err := c.get(ctx, "/nft/items", query, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.NFTItems) == 0 {
+		return nil, fmt.Errorf("%w: nft %s #%s", chain.ErrNotFound, collection, tokenID)
+	}
+	return resp.NFTItems[0].convert(), nil
+}
+
+// Subscribe 轮询各账户的交易，FromBlock 为起始逻辑时间 lt，为 0 时从订阅时刻之后开始
+func (c *Client) Subscribe(ctx context.Context, filter chain.EventFilter) (*chain.Subscription, error) {
+	next := make(map[string]uint64, len(filter.Addresses))
+	for _, addr := range filter.Addresses {
+		next[addr] = filter.FromBlock
+		if filter.FromBlock > 0 {
+			continue
+		}
+		var resp transactionsResponse
+		query := url.Values{"account": {addr}, "sort": {"desc"}, "limit": {"1"}}
+		if err := c.get(ctx, "/transactions", query, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.Transactions) > 0 {
+			next[addr] = resp.Transactions[0].LT() + 1
+		}
+	}
+	return chain.Poll(ctx, c.pollInterval, func(ctx context.Context) ([]chain.Event, error) {
+		var events []chain.Event
+		for _, addr := range filter.Addresses {
+			txs, err := c.Transactions(ctx, addr, next[addr], 0)
+			if err != nil {
+				return events, err
+			}
+			for _, tx := range txs {
+				lt := tx.LT()
+				if lt < next[addr] {
+					continue
+				}
+				next[addr] = lt + 1
+				events = append(events, chain.Event{
+					Chain:       chain.TON,
+					Address:     addr,
+					TxHash:      tx.Hash,
+					BlockNumber: lt,
+					Raw:         tx,
+				})
+			}
+		}
+		return events, nil
+	}), nil
+}
+
+// normalize toncenter 返回原始格式的大写地址，转换为规范形式；无法解析时原样返回
+func normalize(addr string) string {
+	if addr == "" {
+		return ""
+	}
+	if n, err := chain.NormalizeAddress(chain.TON, addr); err == nil {
+		return n
+	}
+	return addr
+}
+
+func parseAmount(s string) (*big.Int, error) {
+	if s == "" {
+		return new(big.Int), nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return n, nil
+}
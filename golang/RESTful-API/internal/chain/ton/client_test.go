@@ -0,0 +1,132 @@
+package ton_test
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/ton"
+	"RESTful-API/internal/chain/ton/tontest"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	wallet     = "0:" + strings.Repeat("a1", 32)
+	sender     = "0:" + strings.Repeat("b2", 32)
+	collection = "0:" + strings.Repeat("c3", 32)
+	item       = "0:" + strings.Repeat("d4", 32)
+	jetton     = "0:" + strings.Repeat("e5", 32)
+)
+
+func TestClientTransaction(t *testing.T) {
+	api := tontest.NewAPI()
+	defer api.Close()
+	ctx := context.Background()
+	client := api.Client()
+
+	// toncenter 返回大写的原始地址
+	tx := api.AddTransaction(ton.Transaction{
+		Account: strings.ToUpper(wallet),
+		InMsg:   &ton.Message{Source: strings.ToUpper(sender), Destination: strings.ToUpper(wallet), Value: "1500000000"},
+	})
+	aborted := ton.Transaction{Account: wallet}
+	aborted.Description.Aborted = true
+	aborted = api.AddTransaction(aborted)
+	api.Advance(2)
+
+	got, err := client.Transaction(ctx, tx.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.From != sender || got.To != wallet || got.Value.String() != "1500000000" ||
+		!got.Success || got.BlockNumber != 1 || got.Confirmations != 3 {
+		t.Fatalf("unexpected transaction %+v", got)
+	}
+	// 没有入站消息时 To 为交易所在账户
+	if got, err = client.Transaction(ctx, aborted.Hash); err != nil || got.Success || got.To != wallet {
+		t.Fatalf("aborted transaction: %+v, %v", got, err)
+	}
+	if _, err = client.Transaction(ctx, "missing"); !errors.Is(err, chain.ErrTxNotFound) {
+		t.Fatalf("got %v, want ErrTxNotFound", err)
+	}
+}
+
+func TestClientTransactionsAndTrace(t *testing.T) {
+	api := tontest.NewAPI()
+	defer api.Close()
+	ctx := context.Background()
+	client := api.Client()
+
+	first := api.AddTransaction(ton.Transaction{Account: wallet})
+	second := api.AddTransaction(ton.Transaction{Account: sender, TraceID: first.TraceID})
+	third := api.AddTransaction(ton.Transaction{Account: wallet})
+
+	txs, err := client.Transactions(ctx, wallet, first.LT()+1, 0)
+	if err != nil || len(txs) != 1 || txs[0].Hash != third.Hash {
+		t.Fatalf("Transactions = %+v, %v", txs, err)
+	}
+	trace, err := client.Trace(ctx, second.Hash)
+	if err != nil || len(trace) != 2 || trace[0].Hash != first.Hash || trace[1].Hash != second.Hash {
+		t.Fatalf("Trace = %+v, %v", trace, err)
+	}
+	if _, err = client.Trace(ctx, "missing"); !errors.Is(err, chain.ErrTxNotFound) {
+		t.Fatalf("got %v, want ErrTxNotFound", err)
+	}
+}
+
+func TestClientBalancesAndNFT(t *testing.T) {
+	api := tontest.NewAPI()
+	defer api.Close()
+	ctx := context.Background()
+	client := api.Client()
+
+	api.SetBalance(wallet, "42")
+	api.SetJettonBalance(wallet, jetton, "7")
+	if b, err := client.TokenBalance(ctx, wallet, ""); err != nil || b.Int64() != 42 {
+		t.Fatalf("TON balance = %v, %v", b, err)
+	}
+	if b, err := client.TokenBalance(ctx, wallet, jetton); err != nil || b.Int64() != 7 {
+		t.Fatalf("jetton balance = %v, %v", b, err)
+	}
+	if _, err := client.TokenBalance(ctx, "not-an-address", ""); !errors.Is(err, chain.ErrInvalidAddress) {
+		t.Fatalf("got %v, want ErrInvalidAddress", err)
+	}
+
+	api.SetNFTItem(ton.NFTItem{Address: item, Collection: collection, Index: "3", Owner: wallet, ContentURI: "3.json"})
+	// 按序号或 item 地址查询
+	for _, id := range []string{"3", item} {
+		got, err := client.NFTItem(ctx, collection, id)
+		if err != nil || got.Address != item || got.Owner != wallet || got.ContentURI != "3.json" {
+			t.Fatalf("NFTItem(%s) = %+v, %v", id, got, err)
+		}
+	}
+	if _, err := client.NFTOwner(ctx, collection, "4"); !errors.Is(err, chain.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientSubscribe(t *testing.T) {
+	api := tontest.NewAPI()
+	defer api.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	api.AddTransaction(ton.Transaction{Account: wallet})
+	sub, err := api.Client().Subscribe(ctx, chain.EventFilter{Addresses: []string{wallet}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	// FromBlock 为 0 时订阅前的交易不推送
+	tx := api.AddTransaction(ton.Transaction{Account: wallet})
+	select {
+	case e := <-sub.Events():
+		if e.Chain != chain.TON || e.TxHash != tx.Hash || e.BlockNumber != tx.LT() {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case <-ctx.Done():
+		t.Fatal("no event received")
+	}
+}
@@ -0,0 +1,192 @@
+package ton
+
+import (
+	"RESTful-API/internal/chain"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// TEP-62 消息的 op
+const (
+	OpNFTTransfer       uint32 = 0x5fcc3d14 // transfer：拥有者发给 item，转移所有权
+	OpOwnershipAssigned uint32 = 0x05138d91 // ownership_assigned：item 通知新的拥有者
+)
+
+// maxNFTItems /nft/items 单页的最大数量
+const maxNFTItems = 1000
+
+// ErrBodyMalformed 消息体不是预期的 TEP-62 格式
+var ErrBodyMalformed = errors.New("ton message body malformed")
+
+// NFTCollection TEP-62 NFT collection
+type NFTCollection struct {
+	Address       string
+	Owner         string
+	NextItemIndex string
+	ContentURI    string
+}
+
+type rawNFTCollection struct {
+	Address           string `json:"address"`
+	OwnerAddress      string `json:"owner_address"`
+	NextItemIndex     string `json:"next_item_index"`
+	CollectionContent struct {
+		URI string `json:"uri"`
+	} `json:"collection_content"`
+}
+
+// NFTCollection 查询 collection，不存在时返回 chain.ErrNotFound
+func (c *Client) NFTCollection(ctx context.Context, collection string) (*NFTCollection, error) {
+	collection, err := chain.NormalizeAddress(chain.TON, collection)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		NFTCollections []rawNFTCollection `json:"nft_collections"`
+	}
+	if err = c.get(ctx, "/nft/collections", url.Values{"collection_address": {collection}}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.NFTCollections) == 0 {
+		return nil, fmt.Errorf("%w: collection %s", chain.ErrNotFound, collection)
+	}
+	raw := resp.NFTCollections[0]
+	return &NFTCollection{
+		Address:       normalize(raw.Address),
+		Owner:         normalize(raw.OwnerAddress),
+		NextItemIndex: raw.NextItemIndex,
+		ContentURI:    raw.CollectionContent.URI,
+	}, nil
+}
+
+// NFTItems 分页返回 collection 中的 item，按 index 升序
+func (c *Client) NFTItems(ctx context.Context, collection string, offset, limit int) ([]NFTItem, error) {
+	collection, err := chain.NormalizeAddress(chain.TON, collection)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxNFTItems {
+		limit = maxNFTItems
+	}
+	query := url.Values{
+		"collection_address": {collection},
+		"offset":             {strconv.Itoa(offset)},
+		"limit":              {strconv.Itoa(limit)},
+	}
+	var resp struct {
+		NFTItems []rawNFTItem `json:"nft_items"`
+	}
+	if err = c.get(ctx, "/nft/items", query, &resp); err != nil {
+		return nil, err
+	}
+	items := make([]NFTItem, 0, len(resp.NFTItems))
+	for _, raw := range resp.NFTItems {
+		items = append(items, *raw.convert())
+	}
+	return items, nil
+}
+
+// DecodeItemTransfer 解析 NFT item 上的一笔交易，返回所有权的变化：
+//   - collection 部署 item：入站消息体为初始拥有者，from 为空
+//   - transfer：新的拥有者取自消息体，原拥有者为发送方；
+//     item 发出 ownership_assigned 时以其中的 prev_owner 和目标地址为准
+//
+// 交易失败或不涉及所有权变化时 ok 为 false
+func DecodeItemTransfer(tx Transaction, collection string) (from, to string, ok bool) {
+	if !tx.Success() || tx.InMsg == nil {
+		return "", "", false
+	}
+	source, err := chain.NormalizeAddress(chain.TON, tx.InMsg.Source)
+	if err != nil {
+		return "", "", false // 外部消息没有发送方
+	}
+	body := tx.InMsg.MessageContent.Body
+
+	if newOwner, err := DecodeNFTTransfer(body); err == nil {
+		from, to = source, newOwner
+		for _, msg := range tx.OutMsgs {
+			prev, err := DecodeOwnershipAssigned(msg.MessageContent.Body)
+			if err != nil {
+				continue
+			}
+			if dest, err := chain.NormalizeAddress(chain.TON, msg.Destination); err == nil {
+				from, to = prev, dest
+			}
+			break
+		}
+	} else if tx.Deployed() && source == collection {
+		if to, err = DecodeItemInit(body); err != nil {
+			return "", "", false
+		}
+	} else {
+		return "", "", false
+	}
+	return from, to, to != ""
+}
+
+// DecodeNFTTransfer 解析 transfer 消息体，返回新的拥有者：
+// transfer#5fcc3d14 query_id:uint64 new_owner:MsgAddress response_destination:MsgAddress ...
+func DecodeNFTTransfer(body string) (string, error) {
+	return decodeOwner(body, OpNFTTransfer)
+}
+
+// DecodeOwnershipAssigned 解析 ownership_assigned 消息体，返回之前的拥有者：
+// ownership_assigned#05138d91 query_id:uint64 prev_owner:MsgAddress forward_payload:(Either Cell ^Cell)
+func DecodeOwnershipAssigned(body string) (string, error) {
+	return decodeOwner(body, OpOwnershipAssigned)
+}
+
+// DecodeItemInit 解析 collection 部署 item 时的消息体（标准 nft-item 合约），返回初始拥有者：
+// owner_address:MsgAddress content:^Cell
+func DecodeItemInit(body string) (string, error) {
+	s, err := parseBody(body)
+	if err != nil {
+		return "", err
+	}
+	return loadOwner(s)
+}
+
+func decodeOwner(body string, op uint32) (string, error) {
+	s, err := parseBody(body)
+	if err != nil {
+		return "", err
+	}
+	got, err := s.LoadUInt(32)
+	if err != nil || uint32(got) != op {
+		return "", ErrBodyMalformed
+	}
+	if _, err = s.LoadUInt(64); err != nil { // query_id
+		return "", ErrBodyMalformed
+	}
+	return loadOwner(s)
+}
+
+func parseBody(body string) (*cell.Slice, error) {
+	boc, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrBodyMalformed
+	}
+	root, err := cell.FromBOC(boc)
+	if err != nil {
+		return nil, ErrBodyMalformed
+	}
+	return root.BeginParse(), nil
+}
+
+// loadOwner 读取地址并转为规范形式，addr_none 返回空字符串
+func loadOwner(s *cell.Slice) (string, error) {
+	addr, err := s.LoadAddr()
+	if err != nil {
+		return "", ErrBodyMalformed
+	}
+	if addr.IsAddrNone() {
+		return "", nil
+	}
+	return chain.NormalizeAddress(chain.TON, addr.StringRaw())
+}
@@ -0,0 +1,356 @@
+// Package tontest 测试用的本地 toncenter v3 API 替身
+package tontest
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/ton"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 单次查询最多返回的条数，与 toncenter 的限制一致
+const (
+	maxTransactions = 256
+	maxNFTItems     = 1000
+)
+
+// API 本地 toncenter 替身，在内存中维护 masterchain 高度、交易、余额和 NFT item
+type API struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	seqno       uint64
+	lt          uint64
+	txs         []ton.Transaction
+	balances    map[string]string // account -> nanoton
+	jettons     map[string]string // owner/jetton -> 数量
+	items       []rawNFTItem
+	collections []rawNFTCollection
+	calls       map[string]int
+}
+
+// NewAPI 启动替身 API
+func NewAPI() *API {
+	f := &API{
+		seqno:    1,
+		lt:       1000,
+		balances: make(map[string]string),
+		jettons:  make(map[string]string),
+		calls:    make(map[string]int),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/masterchainInfo", f.handleMasterchainInfo)
+	mux.HandleFunc("/transactions", f.handleTransactions)
+	mux.HandleFunc("/traces", f.handleTraces)
+	mux.HandleFunc("/account", f.handleAccount)
+	mux.HandleFunc("/jetton/wallets", f.handleJettonWallets)
+	mux.HandleFunc("/nft/items", f.handleNFTItems)
+	mux.HandleFunc("/nft/collections", f.handleNFTCollections)
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.calls[r.URL.Path]++
+		f.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	return f
+}
+
+// URL API 根地址
+func (f *API) URL() string {
+	return f.server.URL
+}
+
+// Client 连接到替身 API 的客户端
+func (f *API) Client() *ton.Client {
+	return ton.New(chain.Endpoint{URL: f.URL(), PollInterval: 10 * time.Millisecond})
+}
+
+// Calls 返回路径被请求的次数
+func (f *API) Calls(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[path]
+}
+
+// Close 关闭替身 API
+func (f *API) Close() {
+	f.server.Close()
+}
+
+// Advance 推进 count 个 masterchain 区块，返回最新 seqno
+func (f *API) Advance(count uint64) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seqno += count
+	return f.seqno
+}
+
+// AddTransaction 记录 account 上的一笔交易，自动分配逻辑时间和所在区块，返回补全后的交易；
+// TraceID 为空时交易单独构成一个 trace
+func (f *API) AddTransaction(tx ton.Transaction) ton.Transaction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lt += 1000
+	tx.Lt = strconv.FormatUint(f.lt, 10)
+	tx.McBlockSeqno = f.seqno
+	if tx.Now == 0 {
+		tx.Now = 1700000000 + int64(f.seqno)*5
+	}
+	if tx.Hash == "" {
+		tx.Hash = strconv.FormatUint(f.lt, 16)
+	}
+	if tx.TraceID == "" {
+		tx.TraceID = tx.Hash
+	}
+	if tx.OrigStatus == "" {
+		tx.OrigStatus = "active"
+	}
+	if tx.EndStatus == "" {
+		tx.EndStatus = "active"
+	}
+	if !tx.Description.Aborted {
+		tx.Description.ComputePh.Success = true
+	}
+	f.txs = append(f.txs, tx)
+	return tx
+}
+
+// SetBalance 设置 TON 余额
+func (f *API) SetBalance(account, nanoton string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.balances[normalize(account)] = nanoton
+}
+
+// SetJettonBalance 设置 jetton 余额
+func (f *API) SetJettonBalance(owner, jetton, amount string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jettons[normalize(owner)+"/"+normalize(jetton)] = amount
+}
+
+// SetNFTItem 新增或更新 NFT item
+func (f *API) SetNFTItem(item ton.NFTItem) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	raw := rawNFTItem{
+		Address:           normalize(item.Address),
+		CollectionAddress: normalize(item.Collection),
+		Index:             item.Index,
+		OwnerAddress:      normalize(item.Owner),
+		Init:              true,
+	}
+	raw.Content.URI = item.ContentURI
+	for i := range f.items {
+		if f.items[i].Address == raw.Address {
+			f.items[i] = raw
+			return
+		}
+	}
+	f.items = append(f.items, raw)
+}
+
+// SetNFTCollection 新增或更新 NFT collection
+func (f *API) SetNFTCollection(collection ton.NFTCollection) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	raw := rawNFTCollection{
+		Address:       normalize(collection.Address),
+		OwnerAddress:  normalize(collection.Owner),
+		NextItemIndex: collection.NextItemIndex,
+	}
+	raw.CollectionContent.URI = collection.ContentURI
+	for i := range f.collections {
+		if f.collections[i].Address == raw.Address {
+			f.collections[i] = raw
+			return
+		}
+	}
+	f.collections = append(f.collections, raw)
+}
+
+func (f *API) handleMasterchainInfo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	writeJSON(w, map[string]interface{}{
+		"last":  map[string]uint64{"seqno": f.seqno},
+		"first": map[string]uint64{"seqno": 1},
+	})
+}
+
+func (f *API) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > maxTransactions {
+		limit = maxTransactions
+	}
+	startLt, _ := strconv.ParseUint(q.Get("start_lt"), 10, 64)
+	hash, account := q.Get("hash"), normalize(q.Get("account"))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := []ton.Transaction{}
+	for _, tx := range f.txs {
+		if hash != "" && tx.Hash != hash {
+			continue
+		}
+		if account != "" && normalize(tx.Account) != account {
+			continue
+		}
+		if tx.LT() < startLt {
+			continue
+		}
+		result = append(result, tx)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if q.Get("sort") == "desc" {
+			return result[i].LT() > result[j].LT()
+		}
+		return result[i].LT() < result[j].LT()
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	writeJSON(w, map[string]interface{}{"transactions": result})
+}
+
+func (f *API) handleTraces(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Query().Get("tx_hash")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	traceID := ""
+	for _, tx := range f.txs {
+		if tx.Hash == hash {
+			traceID = tx.TraceID
+		}
+	}
+	traces := []map[string]interface{}{}
+	if traceID != "" {
+		txs, order := map[string]ton.Transaction{}, []string{}
+		for _, tx := range f.txs {
+			if tx.TraceID == traceID {
+				txs[tx.Hash] = tx
+				order = append(order, tx.Hash)
+			}
+		}
+		traces = append(traces, map[string]interface{}{
+			"trace_id":           traceID,
+			"transactions":       txs,
+			"transactions_order": order,
+		})
+	}
+	writeJSON(w, map[string]interface{}{"traces": traces})
+}
+
+func (f *API) handleAccount(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	balance, ok := f.balances[normalize(r.URL.Query().Get("address"))]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, map[string]string{"error": "account not found"})
+		return
+	}
+	writeJSON(w, map[string]string{"balance": balance, "status": "active"})
+}
+
+func (f *API) handleJettonWallets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	wallets := []map[string]string{}
+	if amount, ok := f.jettons[normalize(q.Get("owner_address"))+"/"+normalize(q.Get("jetton_address"))]; ok {
+		wallets = append(wallets, map[string]string{"balance": amount})
+	}
+	writeJSON(w, map[string]interface{}{"jetton_wallets": wallets})
+}
+
+func (f *API) handleNFTItems(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	addr, collection, index := normalize(q.Get("address")), normalize(q.Get("collection_address")), q.Get("index")
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > maxNFTItems {
+		limit = maxNFTItems
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := []rawNFTItem{}
+	for _, item := range f.items {
+		if addr != "" && item.Address != addr {
+			continue
+		}
+		if collection != "" && item.CollectionAddress != collection {
+			continue
+		}
+		if index != "" && item.Index != index {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, _ := strconv.ParseUint(items[i].Index, 10, 64)
+		b, _ := strconv.ParseUint(items[j].Index, 10, 64)
+		return a < b
+	})
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	writeJSON(w, map[string]interface{}{"nft_items": items})
+}
+
+func (f *API) handleNFTCollections(w http.ResponseWriter, r *http.Request) {
+	addr := normalize(r.URL.Query().Get("collection_address"))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	collections := []rawNFTCollection{}
+	for _, c := range f.collections {
+		if addr == "" || c.Address == addr {
+			collections = append(collections, c)
+		}
+	}
+	writeJSON(w, map[string]interface{}{"nft_collections": collections})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// 以下为 toncenter 返回的 NFT 格式
+type rawNFTItem struct {
+	Address           string `json:"address"`
+	CollectionAddress string `json:"collection_address"`
+	Index             string `json:"index"`
+	OwnerAddress      string `json:"owner_address"`
+	Init              bool   `json:"init"`
+	Content           struct {
+		URI string `json:"uri"`
+	} `json:"content"`
+}
+
+type rawNFTCollection struct {
+	Address           string `json:"address"`
+	OwnerAddress      string `json:"owner_address"`
+	NextItemIndex     string `json:"next_item_index"`
+	CollectionContent struct {
+		URI string `json:"uri"`
+	} `json:"collection_content"`
+}
+
+// normalize 地址按规范形式比较，非法地址原样使用
+func normalize(addr string) string {
+	if n, err := chain.NormalizeAddress(chain.TON, addr); err == nil {
+		return n
+	}
+	return addr
+}
@@ -0,0 +1,91 @@
+package ton
+
+import (
+	"strconv"
+)
+
+type transactionsResponse struct {
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Transaction toncenter v3 返回的交易
+type Transaction struct {
+	Account      string    `json:"account"`
+	Hash         string    `json:"hash"`
+	TraceID      string    `json:"trace_id"` // 同一条外部消息触发的全部交易属于同一个 trace
+	Lt           string    `json:"lt"`
+	Now          int64     `json:"now"`
+	McBlockSeqno uint64    `json:"mc_block_seqno"`
+	OrigStatus   string    `json:"orig_status"` // 交易前的账户状态：nonexist、uninit、active、frozen
+	EndStatus    string    `json:"end_status"`  // 交易后的账户状态
+	InMsg        *Message  `json:"in_msg"`
+	OutMsgs      []Message `json:"out_msgs"`
+	Description  struct {
+		Aborted   bool `json:"aborted"`
+		ComputePh struct {
+			Success bool `json:"success"`
+			Skipped bool `json:"skipped"`
+		} `json:"compute_ph"`
+	} `json:"description"`
+}
+
+// LT 逻辑时间
+func (t Transaction) LT() uint64 {
+	lt, _ := strconv.ParseUint(t.Lt, 10, 64)
+	return lt
+}
+
+// Deployed 账户是否在这笔交易中部署
+func (t Transaction) Deployed() bool {
+	return t.OrigStatus != "active" && t.EndStatus == "active"
+}
+
+// Success 交易是否执行成功
+func (t Transaction) Success() bool {
+	return !t.Description.Aborted && (t.Description.ComputePh.Success || t.Description.ComputePh.Skipped)
+}
+
+// Message 内部或外部消息，Body 为 base64 编码的 BoC
+type Message struct {
+	Hash           string `json:"hash"`
+	Source         string `json:"source"`
+	Destination    string `json:"destination"`
+	Value          string `json:"value"`
+	Opcode         string `json:"opcode"`
+	CreatedLt      string `json:"created_lt"`
+	MessageContent struct {
+		Body string `json:"body"`
+	} `json:"message_content"`
+}
+
+// NFTItem TEP-62 NFT item
+type NFTItem struct {
+	Address    string
+	Collection string
+	Index      string
+	Owner      string
+	ContentURI string
+	Init       bool
+}
+
+type rawNFTItem struct {
+	Address           string `json:"address"`
+	CollectionAddress string `json:"collection_address"`
+	Index             string `json:"index"`
+	OwnerAddress      string `json:"owner_address"`
+	Init              bool   `json:"init"`
+	Content           struct {
+		URI string `json:"uri"`
+	} `json:"content"`
+}
+
+func (r rawNFTItem) convert() *NFTItem {
+	return &NFTItem{
+		Address:    normalize(r.Address),
+		Collection: normalize(r.CollectionAddress),
+		Index:      r.Index,
+		Owner:      normalize(r.OwnerAddress),
+		ContentURI: r.Content.URI,
+		Init:       r.Init,
+	}
+}
@@ -47,4 +47,8 @@ const (
 
 	DefaultPage     = 1
 	DefaultPageSize = 10
+
+	ChainEVM    = "EVM"    // 与数据表 chain 枚举保持一致
+	ChainTON    = "TON"    // 与数据表 chain 枚举保持一致
+	ChainSolana = "Solana" // 与数据表 chain 枚举保持一致
 )
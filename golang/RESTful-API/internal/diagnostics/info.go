@@ -0,0 +1,88 @@
+package diagnostics
+
+import (
+	"RESTful-API/utils/config"
+	"fmt"
+	"github.com/spf13/viper"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// 构建时通过 -ldflags 注入，例如
+// go build -ldflags "-X RESTful-API/internal/diagnostics.Version=v1.2.0 -X RESTful-API/internal/diagnostics.Commit=$(git rev-parse HEAD)"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+const redacted = "******"
+
+// 配置项名称中包含这些词时脱敏
+var secretWords = []string{"password", "secret", "token", "apikey", "api_key", "privatekey", "private_key", "credential"}
+
+// BuildInfo 构建信息
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	Modified  bool   `json:"modified"` // 构建时工作区是否有未提交的修改
+}
+
+// ReadBuildInfo 读取构建信息，没有通过 ldflags 注入时使用 go build 自动记录的 vcs 信息
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+// EffectiveConfig 返回脱敏后的生效配置：ini 为当前 RunMode 下的配置，yaml 为 config.yaml
+func EffectiveConfig() map[string]map[string]string {
+	ini := config.Effective()
+	for k, v := range ini {
+		ini[k] = redact(k, v)
+	}
+
+	yaml := make(map[string]string)
+	for _, k := range viper.AllKeys() {
+		yaml[k] = redact(k, fmt.Sprint(viper.Get(k)))
+	}
+	return map[string]map[string]string{"ini": ini, "yaml": yaml}
+}
+
+// redact 敏感配置项替换为固定字符串，空值保持为空，方便确认是否配置
+func redact(key, value string) string {
+	if value == "" {
+		return value
+	}
+	segments := strings.Split(strings.ToLower(key), ".")
+	for _, seg := range segments[:len(segments)-1] {
+		if seg == "keys" { // 例如 token.keys.k1 签名密钥
+			return redacted
+		}
+	}
+	last := segments[len(segments)-1]
+	for _, word := range secretWords {
+		if strings.Contains(last, word) {
+			return redacted
+		}
+	}
+	return value
+}
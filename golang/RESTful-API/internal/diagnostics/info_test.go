@@ -0,0 +1,43 @@
+package diagnostics
+
+import (
+	"github.com/spf13/viper"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		key, value string
+		redacted   bool
+	}{
+		{"db.default.password", "pwd", true},
+		{"diag.token", "abc", true},
+		{"chain.EVM.apiKey", "abc", true},
+		{"storage.S3.secretKey", "abc", true},
+		{"lazymint.signerPrivateKey", "0x4c0883a6", true},
+		{"token.keys.k1", "abc", true},
+		{"db.default.password", "", false}, // 空值保持为空
+		{"db.default.host", "127.0.0.1", false},
+		{"ratelimit.default.key", "user", false},
+		{"lazymint.contract", "0x5FbDB2315678afecb367f032d93F642f64180aa3", false},
+	}
+	for _, tt := range tests {
+		got := redact(tt.key, tt.value)
+		if tt.redacted && got != redacted || !tt.redacted && got != tt.value {
+			t.Errorf("redact(%q, %q) = %q", tt.key, tt.value, got)
+		}
+	}
+}
+
+// TestEffectiveConfigRedactsSigner lazymint 的签名私钥不能出现在 /debug/config 中
+func TestEffectiveConfigRedactsSigner(t *testing.T) {
+	const key = "lazymint.signerPrivateKey"
+	viper.Set(key, "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	defer viper.Set(key, "")
+
+	// viper 的 key 都是小写
+	if got := EffectiveConfig()["yaml"][strings.ToLower(key)]; got != redacted {
+		t.Fatalf("%s = %q, want redacted", key, got)
+	}
+}
@@ -0,0 +1,131 @@
+// Package diagnostics 诊断服务，与业务接口分开监听，用于排查内存、协程泄漏等问题
+//
+//	/debug/pprof/      pprof 索引，可用 go tool pprof 抓取 heap、profile 等
+//	/debug/goroutines  全部协程的调用栈
+//	/debug/build       版本、提交、Go 版本等构建信息
+//	/debug/config      当前生效的配置，密码、密钥等已脱敏
+//	/debug/db          已初始化的数据库别名及连接池状态
+//
+// 配置见 configs/diagnostics.ini。没有配置 token 时只能绑定在本机地址上。
+package diagnostics
+
+import (
+	"RESTful-API/internal/model"
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/json"
+	"RESTful-API/utils/logs"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"time"
+)
+
+// Start 按配置在后台启动诊断服务，未开启时直接返回
+func Start() {
+	if !config.GetConfig("diag.enable").MustBool(false) {
+		return
+	}
+	addr := config.GetConfig("diag.addr").MustString("127.0.0.1")
+	token := config.GetConfig("diag.token").String()
+	if token == "" && !isLoopback(addr) {
+		logs.Error("diagnostics server not started: diag.token is required when listening on %s", addr)
+		return
+	}
+
+	server := &http.Server{
+		Addr:              net.JoinHostPort(addr, config.GetConfig("diag.port").MustString("6060")),
+		Handler:           NewHandler(token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logs.Info("diagnostics server listening on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logs.Error("diagnostics server error: %v", err)
+		}
+	}()
+}
+
+// NewHandler 诊断接口路由，token 为空时只允许本机访问
+func NewHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/goroutines", goroutines)
+	mux.HandleFunc("/debug/build", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ReadBuildInfo())
+	})
+	mux.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, EffectiveConfig())
+	})
+	mux.HandleFunc("/debug/db", databases)
+	return protect(token, mux)
+}
+
+// protect 校验管理令牌；没有配置令牌时只允许来自本机的请求
+func protect(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		} else if host, _, err := net.SplitHostPort(r.RemoteAddr); err != nil || !isLoopback(host) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// goroutines 输出全部协程的完整调用栈
+func goroutines(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// databases 输出 OrmMap 中的数据库别名和连接池状态
+func databases(w http.ResponseWriter, _ *http.Request) {
+	aliases := make([]string, 0, len(model.OrmMap))
+	for alias := range model.OrmMap {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	result := make([]map[string]interface{}, 0, len(aliases))
+	for _, alias := range aliases {
+		item := map[string]interface{}{"alias": alias}
+		if sqlDB, err := model.OrmMap[alias].DB(); err == nil {
+			item["stats"] = sqlDB.Stats()
+		}
+		result = append(result, item)
+	}
+	writeJSON(w, result)
+}
+
+// writeJSON 输出格式化的 json
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("marshal error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write(body)
+}
+
+// isLoopback 判断地址是否为本机地址
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -57,14 +57,31 @@ var (
 	ErrUploadMustMintError              = &ErrMsg{Code: 20016, Msg: "You must mint before you can upload your work"}
 	ErrChainIDInvalidError              = &ErrMsg{Code: 20017, Msg: "The chain ID is invalid"}
 	ErrHashNotExists                    = &ErrMsg{Code: 20018, Msg: "tx hash not exists"}
+	ErrWalletChainBoundError            = &ErrMsg{Code: 20019, Msg: "A wallet is already linked on this chain, unlink it first"}
+	ErrWalletLastLoginMethodError       = &ErrMsg{Code: 20020, Msg: "Cannot unlink the only way to log in to this account"}
+	ErrWalletNotLinkedError             = &ErrMsg{Code: 20021, Msg: "No wallet is linked on this chain"}
+	ErrAddressInvalidError              = &ErrMsg{Code: 20022, Msg: "Invalid wallet address"}
+	ErrHashSubmitRepeatError            = &ErrMsg{Code: 20023, Msg: "This transaction has already been submitted"}
+	ErrTxMismatchError                  = &ErrMsg{Code: 20024, Msg: "The transaction does not match the submitted details"}
+	ErrTxPendingError                   = &ErrMsg{Code: 20025, Msg: "The transaction is not confirmed yet, please submit it again later"}
+	ErrNFTCreateDisabledError           = &ErrMsg{Code: 20026, Msg: "NFT creation is not enabled"}
+	ErrMetadataInvalidError             = &ErrMsg{Code: 20027, Msg: "Invalid NFT metadata"}
 
-	ErrUploadErrorError = &ErrMsg{Code: 80000, Msg: "Upload Error"}
-	ErrFileTypeError    = &ErrMsg{Code: 80001, Msg: "File type error"}
+	ErrUploadErrorError    = &ErrMsg{Code: 80000, Msg: "Upload Error"}
+	ErrFileTypeError       = &ErrMsg{Code: 80001, Msg: "File type error"}
+	ErrFileTooLargeError   = &ErrMsg{Code: 80002, Msg: "File is too large"}
+	ErrImageDimensionError = &ErrMsg{Code: 80003, Msg: "Image width or height exceeds the limit"}
 
 	ErrTokenVerificationError = &ErrMsg{Code: 30001, Msg: "token verification fails or has expired. Please log in again"}
 	ErrNotLoginError          = &ErrMsg{Code: 30002, Msg: "You are not logged in yet, please log in first"}
 	ErrTonLoginAuthError      = &ErrMsg{Code: 30003, Msg: "Login authentication failure"}
 	ErrSignVerifyError        = &ErrMsg{Code: 30004, Msg: "Signature verification failed"}
+	ErrUserNameExistsError    = &ErrMsg{Code: 30005, Msg: "Username already exists"}
+	ErrEmailExistsError       = &ErrMsg{Code: 30006, Msg: "Email already exists"}
+	ErrPasswordError          = &ErrMsg{Code: 30007, Msg: "Incorrect account or password"}
+	ErrAccountLockedError     = &ErrMsg{Code: 30008, Msg: "Too many failed attempts, the account is temporarily locked"}
+	ErrPasswordTooWeakError   = &ErrMsg{Code: 30009, Msg: "Password must be 8 to 64 characters and contain letters and digits"}
+	ErrUserNameInvalidError   = &ErrMsg{Code: 30010, Msg: "Username must be 3 to 50 letters, digits or underscores"}
 
 	ErrRepeatSubmitChannelInfoError = &ErrMsg{Code: 40001, Msg: "You have already submitted the information of the channel provider, please do not submit it again"}
 )
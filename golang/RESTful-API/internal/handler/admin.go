@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/response"
+	"RESTful-API/utils/logs"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// TailLogs 查看内存中最近的日志，需要在 log.sinks 中开启 memory
+// GET /api/v1/admin/logs?n=200
+func TailLogs(c *gin.Context) {
+	sink := logs.Memory()
+	if sink == nil {
+		response.Fail(c, errno.ErrServerNotImplemented)
+		return
+	}
+	n, err := strconv.Atoi(c.DefaultQuery("n", "200"))
+	if err != nil || n <= 0 {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+	response.Success(c, gin.H{"lines": sink.Tail(n)})
+}
@@ -0,0 +1,110 @@
+// Package handler HTTP 接口处理函数
+package handler
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/response"
+	"RESTful-API/internal/service"
+	"RESTful-API/internal/verifier"
+	"github.com/gin-gonic/gin"
+)
+
+// TonPayload 获取 ton_proof 使用的 payload
+// GET /api/v1/auth/ton/payload
+func TonPayload(c *gin.Context) {
+	payload, e := service.TonPayload()
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, gin.H{"payload": payload})
+}
+
+// TonLogin 使用 TON Connect 的 ton_proof 登录
+// POST /api/v1/auth/ton/login
+func TonLogin(c *gin.Context) {
+	req := &verifier.TonProof{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	result, e := service.TonLogin(c.Request.Context(), req)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, result)
+}
+
+// refreshTokenReq 续签与退出登录的请求参数
+type refreshTokenReq struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken 使用 refresh token 续签
+// POST /api/v1/auth/refresh
+func RefreshToken(c *gin.Context) {
+	req := &refreshTokenReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	pair, e := service.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, pair)
+}
+
+// Logout 退出登录
+// POST /api/v1/auth/logout
+func Logout(c *gin.Context) {
+	req := &refreshTokenReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	if e := service.Logout(c.Request.Context(), req.RefreshToken); e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// Register 用户名密码注册
+// POST /api/v1/auth/register
+func Register(c *gin.Context) {
+	req := &service.RegisterReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	result, e := service.Register(c.Request.Context(), req)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, result)
+}
+
+// PasswordLogin 用户名或邮箱加密码登录
+// POST /api/v1/auth/login
+func PasswordLogin(c *gin.Context) {
+	req := &service.PasswordLoginReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	result, e := service.PasswordLogin(c.Request.Context(), req)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, result)
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"RESTful-API/internal/metrics"
+	"RESTful-API/utils/config"
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Metrics Prometheus 抓取接口，配置了 metrics.token 时需要携带 Bearer token，只有 dev 模式允许不配置
+// GET /metrics
+func Metrics() gin.HandlerFunc {
+	h := metrics.Handler()
+	return func(c *gin.Context) {
+		if token := config.GetConfig("metrics.token").String(); token != "" {
+			expected := "Bearer " + token
+			if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(expected)) != 1 {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
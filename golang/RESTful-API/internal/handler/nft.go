@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/middleware"
+	"RESTful-API/internal/response"
+	"RESTful-API/internal/service"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// CreateNFT 创建 NFT（懒铸造），multipart 表单：file 为图片，name、description、attributes、min_price 为元数据和最低价格
+// POST /api/v1/user/nfts
+func CreateNFT(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.UploadMaxSize()+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Fail(c, errno.ErrFileTooLargeError)
+			return
+		}
+		response.Fail(c, errno.ErrParamLost)
+		return
+	}
+	req := &service.CreateNFTReq{}
+	if err = c.ShouldBind(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	nft, e := service.CreateNFT(c.Request.Context(), user, req, file)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, nft)
+}
+
+// NftVoucher 查询草稿 NFT 的铸造凭证，买家用它在链上铸造
+// GET /api/v1/user/nfts/:id/voucher
+func NftVoucher(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	voucher, e := service.NftVoucherOf(c.Request.Context(), id)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, voucher)
+}
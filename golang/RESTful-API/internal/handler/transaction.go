@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/middleware"
+	"RESTful-API/internal/response"
+	"RESTful-API/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SubmitTrade 提交 NFT 成交的交易哈希，链上校验通过后记录
+// POST /api/v1/user/transactions/nft
+func SubmitTrade(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	req := &service.SubmitTradeReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	row, e := service.SubmitTrade(c.Request.Context(), user, req)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, row)
+}
+
+// SubmitSwap 提交 swap 的交易哈希，链上校验通过后记录
+// POST /api/v1/user/transactions/swap
+func SubmitSwap(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	req := &service.SubmitSwapReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	row, e := service.SubmitSwap(c.Request.Context(), user, req)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, row)
+}
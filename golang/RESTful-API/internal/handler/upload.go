@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/middleware"
+	"RESTful-API/internal/response"
+	"RESTful-API/internal/service"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// multipartOverhead multipart 请求中除文件内容之外的边界和表单字段
+const multipartOverhead = 1 << 20
+
+// UploadImage 上传图片，表单字段 file
+// POST /api/v1/user/upload/image
+func UploadImage(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	// 限制请求体大小，超出时不再继续读取，避免大文件写满临时目录
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.UploadMaxSize()+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Fail(c, errno.ErrFileTooLargeError)
+			return
+		}
+		response.Fail(c, errno.ErrParamLost)
+		return
+	}
+
+	img, e := service.UploadImage(c.Request.Context(), user, file)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, img)
+}
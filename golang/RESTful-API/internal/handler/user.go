@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/middleware"
+	"RESTful-API/internal/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Me 获取当前登录用户信息
+// GET /api/v1/user/me
+func Me(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		response.Fail(c, errno.ErrNotLoginError)
+		return
+	}
+	response.Success(c, user)
+}
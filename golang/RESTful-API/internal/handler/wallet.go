@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/middleware"
+	"RESTful-API/internal/response"
+	"RESTful-API/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// WalletNonce 获取绑定钱包需要签名的内容
+// GET /api/v1/user/wallets/nonce?chain=EVM&address=0x...
+func WalletNonce(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	chain, address := c.Query("chain"), c.Query("address")
+	if chain == "" || address == "" {
+		response.Fail(c, errno.ErrParamLost)
+		return
+	}
+
+	challenge, e := service.WalletNonce(c.Request.Context(), user, chain, address)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, challenge)
+}
+
+// ListWallets 当前用户绑定的钱包列表
+// GET /api/v1/user/wallets
+func ListWallets(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	response.Success(c, service.ListWallets(user))
+}
+
+// LinkWallet 绑定钱包
+// POST /api/v1/user/wallets
+func LinkWallet(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	req := &service.WalletProof{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	wallet, e := service.LinkWallet(c.Request.Context(), user, req)
+	if e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, wallet)
+}
+
+// UnlinkWallet 解绑指定链上的钱包
+// DELETE /api/v1/user/wallets/:chain
+func UnlinkWallet(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	req := &service.UnlinkWalletReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.Fail(c, errno.ErrParam)
+		return
+	}
+
+	if e := service.UnlinkWallet(c.Request.Context(), user, c.Param("chain"), req); e != nil {
+		response.Fail(c, e)
+		return
+	}
+	response.Success(c, nil)
+}
@@ -0,0 +1,148 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// 事件签名的 keccak256，即日志的 topic0
+const (
+	// TopicTransfer Transfer(address,address,uint256)，ERC-721 的 tokenId 是第三个 indexed 参数
+	TopicTransfer = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// TopicTransferSingle TransferSingle(address,address,address,uint256,uint256)
+	TopicTransferSingle = "0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62"
+	// TopicTransferBatch TransferBatch(address,address,address,uint256[],uint256[])
+	TopicTransferBatch = "0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb"
+)
+
+var errMalformedLog = errors.New("malformed transfer log")
+
+// DecodeTransfers 解码 ERC-721 Transfer、ERC-1155 TransferSingle/TransferBatch 日志。
+// 三个 topic 的 Transfer 是 ERC-20 事件，返回空结果
+func DecodeTransfers(l evm.Log) ([]Transfer, error) {
+	if len(l.Topics) == 0 {
+		return nil, nil
+	}
+	base := Transfer{
+		Chain:       chain.EVM,
+		Contract:    l.Address,
+		BlockNumber: l.BlockNumber,
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TxHash,
+		LogIndex:    l.LogIndex,
+	}
+	switch strings.ToLower(l.Topics[0]) {
+	case TopicTransfer:
+		if len(l.Topics) != 4 {
+			return nil, nil
+		}
+		t := base
+		t.From, t.To = topicAddress(l.Topics[1]), topicAddress(l.Topics[2])
+		id, err := topicUint(l.Topics[3])
+		if err != nil {
+			return nil, err
+		}
+		t.TokenID, t.Amount = id.String(), big.NewInt(1)
+		return []Transfer{t}, nil
+
+	case TopicTransferSingle:
+		if len(l.Topics) != 4 || len(l.Data) < 64 {
+			return nil, errMalformedLog
+		}
+		t := base
+		t.From, t.To = topicAddress(l.Topics[2]), topicAddress(l.Topics[3])
+		t.TokenID = new(big.Int).SetBytes(l.Data[:32]).String()
+		t.Amount = new(big.Int).SetBytes(l.Data[32:64])
+		if t.Amount.Sign() == 0 {
+			return nil, nil
+		}
+		return []Transfer{t}, nil
+
+	case TopicTransferBatch:
+		if len(l.Topics) != 4 {
+			return nil, errMalformedLog
+		}
+		ids, err := abiUintArray(l.Data, 0)
+		if err != nil {
+			return nil, err
+		}
+		values, err := abiUintArray(l.Data, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) != len(values) {
+			return nil, errMalformedLog
+		}
+		from, to := topicAddress(l.Topics[2]), topicAddress(l.Topics[3])
+		transfers := make([]Transfer, 0, len(ids))
+		for i := range ids {
+			if values[i].Sign() == 0 {
+				continue
+			}
+			t := base
+			t.From, t.To = from, to
+			t.TokenID, t.Amount = ids[i].String(), values[i]
+			transfers = append(transfers, t)
+		}
+		return transfers, nil
+	}
+	return nil, nil
+}
+
+// topicAddress 取 topic 的低 20 字节作为地址，零地址返回空
+func topicAddress(topic string) string {
+	topic = strings.TrimPrefix(topic, "0x")
+	if len(topic) != 64 {
+		return ""
+	}
+	body := topic[24:]
+	if strings.Trim(body, "0") == "" {
+		return ""
+	}
+	return chain.ChecksumEVM(body)
+}
+
+func topicUint(topic string) (*big.Int, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(topic, "0x"))
+	if err != nil || len(b) != 32 {
+		return nil, errMalformedLog
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// abiUintArray 解码 ABI 编码数据中第 index 个参数（uint256[]）
+func abiUintArray(data []byte, index int) ([]*big.Int, error) {
+	offset, ok := abiWord(data, index*32)
+	if !ok {
+		return nil, errMalformedLog
+	}
+	length, ok := abiWord(data, int(offset))
+	if !ok || length > uint64(len(data)/32) {
+		return nil, errMalformedLog
+	}
+	result := make([]*big.Int, 0, length)
+	for i := uint64(0); i < length; i++ {
+		start := int(offset) + 32 + int(i)*32
+		if start+32 > len(data) {
+			return nil, errMalformedLog
+		}
+		result = append(result, new(big.Int).SetBytes(data[start:start+32]))
+	}
+	return result, nil
+}
+
+// abiWord 读取 pos 处的 32 字节整数，超出 uint32 范围时视为非法
+func abiWord(data []byte, pos int) (uint64, bool) {
+	if pos < 0 || pos+32 > len(data) {
+		return 0, false
+	}
+	n := new(big.Int).SetBytes(data[pos : pos+32])
+	if !n.IsUint64() || n.Uint64() > 1<<32 {
+		return 0, false
+	}
+	return n.Uint64(), true
+}
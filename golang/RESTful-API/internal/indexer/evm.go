@@ -0,0 +1,222 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/utils/logs"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// EVMOptions EVM 索引配置
+type EVMOptions struct {
+	Contracts  []string      // 需要索引的合约
+	StartBlock uint64        // 没有索引进度时的起始区块
+	BatchSize  uint64        // 单次 eth_getLogs 查询的区块数
+	Interval   time.Duration // 追上最新区块后的轮询间隔
+	// Confirmations 确认数，只索引到 最新高度 - Confirmations，为 0 时索引到最新区块
+	Confirmations uint64
+	// ReorgWindow 保留最近多少个区块的哈希用于查找分叉点，应大于 Confirmations
+	ReorgWindow uint64
+}
+
+// maxReorgRetries 单轮同步中连续处理链重组的最大次数，超过后等待下一轮
+const maxReorgRetries = 3
+
+// ErrReorgTooDeep 分叉点早于保留的区块哈希，需要人工处理
+var ErrReorgTooDeep = errors.New("reorg deeper than tracked block history")
+
+// EVMIndexer 轮询 eth_getLogs，把 ERC-721 / ERC-1155 的转移写入 nfts 表。
+// nfts 每个 token 只有一个拥有者，ERC-1155 以最后一次转入的地址作为拥有者
+type EVMIndexer struct {
+	client *evm.Client
+	store  Store
+	opts   EVMOptions
+	log    *logs.Logger
+}
+
+// NewEVMIndexer 创建 EVM 索引器，合约地址会被规范化
+func NewEVMIndexer(client *evm.Client, store Store, opts EVMOptions) (*EVMIndexer, error) {
+	contracts := make([]string, 0, len(opts.Contracts))
+	for _, c := range opts.Contracts {
+		addr, err := chain.NormalizeAddress(chain.EVM, c)
+		if err != nil {
+			return nil, fmt.Errorf("indexer contract %q: %w", c, err)
+		}
+		contracts = append(contracts, addr)
+	}
+	opts.Contracts = contracts
+	if opts.BatchSize == 0 {
+		opts.BatchSize = 500
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.ReorgWindow <= opts.Confirmations {
+		opts.ReorgWindow = opts.Confirmations + 1000
+	}
+	return &EVMIndexer{
+		client: client,
+		store:  store,
+		opts:   opts,
+		log:    logs.With(logs.String("indexer", string(chain.EVM))),
+	}, nil
+}
+
+// Run 持续索引直到 ctx 结束，单轮出错时记录日志并在下一轮重试
+func (ix *EVMIndexer) Run(ctx context.Context) {
+	ticker := time.NewTicker(ix.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := ix.Sync(ctx); err != nil && ctx.Err() == nil {
+			ix.log.With(logs.Err(err)).Error("sync failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 把所有合约索引到 最新高度 - Confirmations
+func (ix *EVMIndexer) Sync(ctx context.Context) error {
+	head, err := ix.client.BlockHeight(ctx)
+	if err != nil {
+		return err
+	}
+	if head < ix.opts.Confirmations {
+		return nil
+	}
+	head -= ix.opts.Confirmations
+	for _, contract := range ix.opts.Contracts {
+		if err = ix.syncContract(ctx, contract, head); err != nil {
+			return fmt.Errorf("%s: %w", contract, err)
+		}
+	}
+	if head > ix.opts.ReorgWindow {
+		return ix.store.Prune(ctx, chain.EVM, head-ix.opts.ReorgWindow)
+	}
+	return nil
+}
+
+// syncContract 从进度继续索引，检测到链重组时回滚到分叉点后重新索引
+func (ix *EVMIndexer) syncContract(ctx context.Context, contract string, head uint64) error {
+	for i := 0; i < maxReorgRetries; i++ {
+		reorg, err := ix.syncRange(ctx, contract, head)
+		if err != nil || !reorg {
+			return err
+		}
+	}
+	return fmt.Errorf("chain reorganized %d times in one round", maxReorgRetries)
+}
+
+// syncRange 索引到 head，进度的区块哈希与链上不一致时回滚并返回 true
+func (ix *EVMIndexer) syncRange(ctx context.Context, contract string, head uint64) (bool, error) {
+	cp, err := ix.store.Checkpoint(ctx, chain.EVM, contract)
+	if err != nil {
+		return false, err
+	}
+	from := ix.opts.StartBlock
+	if cp != nil {
+		from = cp.Block + 1
+	}
+	if cp != nil && cp.BlockHash != "" && from <= head {
+		next, err := ix.client.HeaderByNumber(ctx, from)
+		if err != nil {
+			return false, err
+		}
+		if next.ParentHash != cp.BlockHash {
+			return true, ix.rollback(ctx, cp, next)
+		}
+	}
+
+	for from <= head {
+		to := from + ix.opts.BatchSize - 1
+		if to > head {
+			to = head
+		}
+		before, err := ix.client.HeaderByNumber(ctx, to)
+		if err != nil {
+			return false, err
+		}
+		logList, err := ix.client.Logs(ctx, evm.LogQuery{
+			FromBlock: from,
+			ToBlock:   to,
+			Addresses: []string{contract},
+			Topics:    []string{TopicTransfer, TopicTransferSingle, TopicTransferBatch},
+		})
+		if err != nil {
+			return false, err
+		}
+		// 查询日志期间 to 所在区块被替换时，日志可能来自两条分叉，放弃本批等待下一轮
+		after, err := ix.client.HeaderByNumber(ctx, to)
+		if err != nil {
+			return false, err
+		}
+		if after.Hash != before.Hash {
+			return false, fmt.Errorf("block %d changed while fetching logs", to)
+		}
+		transfers, err := decodeLogs(logList)
+		if err != nil {
+			return false, err
+		}
+		cp := Checkpoint{Chain: chain.EVM, Contract: contract, Block: to, BlockHash: after.Hash}
+		if err = ix.store.Apply(ctx, cp, transfers); err != nil {
+			return false, err
+		}
+		if len(transfers) > 0 {
+			ix.log.With(logs.String("contract", contract), logs.Uint64("from", from), logs.Uint64("to", to),
+				logs.Int("transfers", len(transfers))).Info("indexed")
+		}
+		from = to + 1
+	}
+	return false, nil
+}
+
+// rollback 从进度所在区块向前查找哈希仍与链上一致的区块作为分叉点，回滚该链在分叉点之后的数据
+func (ix *EVMIndexer) rollback(ctx context.Context, cp *Checkpoint, next *evm.Header) error {
+	refs, err := ix.store.BlockRefs(ctx, chain.EVM, cp.Block, int(ix.opts.ReorgWindow))
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		header, err := ix.client.HeaderByNumber(ctx, ref.Number)
+		if err != nil {
+			return err
+		}
+		if header.Hash != ref.Hash {
+			continue
+		}
+		ix.log.With(logs.String("contract", cp.Contract), logs.Uint64("checkpoint", cp.Block),
+			logs.String("expected_parent", cp.BlockHash), logs.String("actual_parent", next.ParentHash),
+			logs.Uint64("fork", ref.Number)).Warn("chain reorganization detected, rolling back")
+		return ix.store.Rollback(ctx, chain.EVM, ref)
+	}
+	return fmt.Errorf("%w: checkpoint %d", ErrReorgTooDeep, cp.Block)
+}
+
+// decodeLogs 按区块和日志序号排序后解码，保证同一个 token 的多次转移按链上顺序应用
+func decodeLogs(logList []evm.Log) ([]Transfer, error) {
+	sort.SliceStable(logList, func(i, j int) bool {
+		if logList[i].BlockNumber != logList[j].BlockNumber {
+			return logList[i].BlockNumber < logList[j].BlockNumber
+		}
+		return logList[i].LogIndex < logList[j].LogIndex
+	})
+	var transfers []Transfer
+	for _, l := range logList {
+		if l.Removed {
+			continue
+		}
+		decoded, err := DecodeTransfers(l)
+		if err != nil {
+			return nil, fmt.Errorf("tx %s log %d: %w", l.TxHash, l.LogIndex, err)
+		}
+		transfers = append(transfers, decoded...)
+	}
+	return transfers, nil
+}
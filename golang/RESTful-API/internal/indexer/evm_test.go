@@ -0,0 +1,170 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/evm/evmtest"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const (
+	collection = "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"
+	alice      = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	bob        = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+)
+
+// addressTopic 地址左补零到 32 字节，空地址为零地址
+func addressTopic(addr string) string {
+	if addr == "" {
+		return "0x" + strings.Repeat("0", 64)
+	}
+	return "0x" + strings.Repeat("0", 24) + strings.ToLower(addr[2:])
+}
+
+func uintWord(n int64) []byte {
+	return new(big.Int).SetInt64(n).FillBytes(make([]byte, 32))
+}
+
+func erc721Transfer(contract, from, to string, tokenID int64) evm.Log {
+	return evm.Log{
+		Address: contract,
+		Topics:  []string{TopicTransfer, addressTopic(from), addressTopic(to), fmt.Sprintf("0x%064x", tokenID)},
+	}
+}
+
+func erc1155Single(contract, from, to string, tokenID, amount int64) evm.Log {
+	return evm.Log{
+		Address: contract,
+		Topics:  []string{TopicTransferSingle, addressTopic(alice), addressTopic(from), addressTopic(to)},
+		Data:    append(uintWord(tokenID), uintWord(amount)...),
+	}
+}
+
+func erc1155Batch(contract, from, to string, ids, amounts []int64) evm.Log {
+	data := append(uintWord(64), uintWord(int64(96+32*len(ids)))...)
+	for _, arr := range [][]int64{ids, amounts} {
+		data = append(data, uintWord(int64(len(arr)))...)
+		for _, n := range arr {
+			data = append(data, uintWord(n)...)
+		}
+	}
+	return evm.Log{
+		Address: contract,
+		Topics:  []string{TopicTransferBatch, addressTopic(alice), addressTopic(from), addressTopic(to)},
+		Data:    data,
+	}
+}
+
+func TestDecodeTransfers(t *testing.T) {
+	tests := []struct {
+		name string
+		log  evm.Log
+		want []string // tokenId:from:to:amount
+		err  error
+	}{
+		{"erc721 mint", erc721Transfer(collection, "", alice, 7), []string{"7::" + alice + ":1"}, nil},
+		{"erc721 burn", erc721Transfer(collection, alice, "", 7), []string{"7:" + alice + "::1"}, nil},
+		{"erc20 transfer", evm.Log{Topics: []string{TopicTransfer, addressTopic(alice), addressTopic(bob)}}, nil, nil},
+		{"erc1155 single", erc1155Single(collection, alice, bob, 1, 5), []string{"1:" + alice + ":" + bob + ":5"}, nil},
+		{"erc1155 zero amount", erc1155Single(collection, alice, bob, 1, 0), nil, nil},
+		{"erc1155 batch", erc1155Batch(collection, "", bob, []int64{1, 2, 3}, []int64{4, 0, 6}),
+			[]string{"1::" + bob + ":4", "3::" + bob + ":6"}, nil},
+		{"erc1155 single short data", evm.Log{Topics: erc1155Single(collection, alice, bob, 1, 1).Topics, Data: uintWord(1)}, nil, errMalformedLog},
+		{"erc1155 batch length mismatch", erc1155Batch(collection, alice, bob, []int64{1, 2}, []int64{1}), nil, errMalformedLog},
+		{"erc1155 batch bad offset", evm.Log{Topics: erc1155Batch(collection, alice, bob, nil, nil).Topics, Data: uintWord(1 << 40)}, nil, errMalformedLog},
+		{"no topics", evm.Log{}, nil, nil},
+	}
+	for _, tt := range tests {
+		transfers, err := DecodeTransfers(tt.log)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		var got []string
+		for _, tr := range transfers {
+			got = append(got, fmt.Sprintf("%s:%s:%s:%s", tr.TokenID, tr.From, tr.To, tr.Amount))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func newTestEVMIndexer(t *testing.T, node *evmtest.Node, store Store, opts EVMOptions) *EVMIndexer {
+	t.Helper()
+	opts.Contracts = []string{strings.ToLower(collection)}
+	ix, err := NewEVMIndexer(node.Client(), store, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ix
+}
+
+func TestEVMIndexerSync(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	store := NewMemoryStore()
+	ctx := context.Background()
+	ix := newTestEVMIndexer(t, node, store, EVMOptions{StartBlock: 1, BatchSize: 2, Confirmations: 1})
+
+	node.Mine(1)
+	node.AddLog(erc721Transfer(collection, "", alice, 1))
+	node.AddLog(erc721Transfer(collection, "", alice, 2))
+	node.Mine(1)
+	// 同一区块内按日志顺序应用，token 1 最终属于 bob
+	node.AddLog(erc721Transfer(collection, alice, bob, 1))
+	node.AddLog(erc721Transfer(bob, "", alice, 9)) // 其他合约的日志不索引
+	node.Mine(1)
+	node.AddLog(erc1155Single(collection, "", bob, 3, 10))
+
+	if err := ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 最新高度 3，确认数 1，只索引到 2
+	cp, _ := store.Checkpoint(ctx, chain.EVM, collection)
+	if cp == nil || cp.Block != 2 || cp.BlockHash != node.Header(2).Hash {
+		t.Fatalf("checkpoint %+v", cp)
+	}
+	if owner, _ := store.Owner(chain.EVM, collection, "1"); owner != bob {
+		t.Fatalf("token 1 owner %q, want bob", owner)
+	}
+	if owner, _ := store.Owner(chain.EVM, collection, "2"); owner != alice {
+		t.Fatalf("token 2 owner %q, want alice", owner)
+	}
+	if _, ok := store.Owner(chain.EVM, collection, "3"); ok {
+		t.Fatal("token 3 is not confirmed yet")
+	}
+	if _, ok := store.Owner(chain.EVM, bob, "9"); ok {
+		t.Fatal("logs of other contracts should not be indexed")
+	}
+
+	// 下一轮从进度继续，不重复应用已索引的日志
+	node.AddLog(erc721Transfer(collection, alice, "", 2))
+	node.Mine(1)
+	if err := ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if owner, _ := store.Owner(chain.EVM, collection, "3"); owner != bob {
+		t.Fatalf("token 3 owner %q, want bob", owner)
+	}
+	if _, ok := store.Owner(chain.EVM, collection, "2"); ok {
+		t.Fatal("burned token should have no owner")
+	}
+	if n := len(store.Transfers()); n != 5 {
+		t.Fatalf("got %d transfers, want 5", n)
+	}
+	if tokens, _ := store.Tokens(ctx, chain.EVM, collection); len(tokens) != 3 {
+		t.Fatalf("tokens %v", tokens)
+	}
+}
+
+func TestEVMIndexerInvalidContract(t *testing.T) {
+	if _, err := NewEVMIndexer(nil, NewMemoryStore(), EVMOptions{Contracts: []string{"0x1234"}}); !errors.Is(err, chain.ErrInvalidAddress) {
+		t.Fatalf("got %v, want ErrInvalidAddress", err)
+	}
+}
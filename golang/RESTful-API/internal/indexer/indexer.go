@@ -0,0 +1,54 @@
+// Package indexer 跟踪链上 NFT 转移，更新 nfts 表中的拥有者
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"context"
+	"math/big"
+)
+
+// Transfer 一次 NFT 转移，From 为空表示铸造，To 为空表示销毁
+type Transfer struct {
+	Chain       chain.Chain
+	Contract    string
+	TokenID     string
+	From        string
+	To          string
+	Amount      *big.Int // ERC-1155 的转移数量，ERC-721 为 1
+	BlockNumber uint64
+	BlockHash   string
+	TxHash      string
+	LogIndex    uint
+	MetadataURI string // 非空时同时更新 nfts.metadata_uri
+}
+
+// Checkpoint 合约的索引进度
+type Checkpoint struct {
+	Chain     chain.Chain
+	Contract  string
+	Block     uint64 // 已处理到的高度（含）
+	BlockHash string // Block 的区块哈希，为空时不检测链重组
+}
+
+// BlockRef 已索引的区块
+type BlockRef struct {
+	Number uint64
+	Hash   string
+}
+
+// Store 索引结果的持久化存储
+type Store interface {
+	// Checkpoint 查询合约的索引进度，没有记录时返回 nil
+	Checkpoint(ctx context.Context, c chain.Chain, contract string) (*Checkpoint, error)
+	// Apply 按顺序应用转移，记录转移历史、进度和进度所在区块的哈希，在同一个事务中完成
+	Apply(ctx context.Context, cp Checkpoint, transfers []Transfer) error
+	// BlockRefs 按区块号倒序返回不高于 below 的已索引区块，最多 limit 个
+	BlockRefs(ctx context.Context, c chain.Chain, below uint64, limit int) ([]BlockRef, error)
+	// Rollback 链重组时回滚 fork 之后的数据：转移历史、NFT 拥有者、nft_transactions、swap_transactions，
+	// 并把该链上超过 fork 的进度退回到 fork
+	Rollback(ctx context.Context, c chain.Chain, fork BlockRef) error
+	// Prune 删除低于 below 的区块哈希，这些区块已经不会再被重组
+	Prune(ctx context.Context, c chain.Chain, below uint64) error
+	// Tokens 返回合约（collection）下已索引的全部 tokenId
+	Tokens(ctx context.Context, c chain.Chain, contract string) ([]string, error)
+}
@@ -0,0 +1,172 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore 内存中的 Store，供测试使用
+type MemoryStore struct {
+	mu          sync.Mutex
+	owners      map[string]string
+	checkpoints map[string]Checkpoint
+	blocks      map[chain.Chain]map[uint64]string
+	transfers   []Transfer
+	metadata    map[string]string
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		owners:      make(map[string]string),
+		checkpoints: make(map[string]Checkpoint),
+		blocks:      make(map[chain.Chain]map[uint64]string),
+		metadata:    make(map[string]string),
+	}
+}
+
+func (s *MemoryStore) Checkpoint(ctx context.Context, c chain.Chain, contract string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[string(c)+"/"+contract]
+	if !ok {
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+func (s *MemoryStore) Apply(ctx context.Context, cp Checkpoint, transfers []Transfer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range transfers {
+		s.setOwner(t.Chain, t.Contract, t.TokenID, t.To)
+		if t.MetadataURI != "" {
+			s.metadata[nftKey(t.Chain, t.Contract, t.TokenID)] = t.MetadataURI
+		}
+		s.transfers = append(s.transfers, t)
+	}
+	s.checkpoints[string(cp.Chain)+"/"+cp.Contract] = cp
+	if cp.BlockHash != "" {
+		if s.blocks[cp.Chain] == nil {
+			s.blocks[cp.Chain] = make(map[uint64]string)
+		}
+		s.blocks[cp.Chain][cp.Block] = cp.BlockHash
+	}
+	return nil
+}
+
+func (s *MemoryStore) BlockRefs(ctx context.Context, c chain.Chain, below uint64, limit int) ([]BlockRef, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var refs []BlockRef
+	for n, h := range s.blocks[c] {
+		if n <= below {
+			refs = append(refs, BlockRef{Number: n, Hash: h})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Number > refs[j].Number })
+	if len(refs) > limit {
+		refs = refs[:limit]
+	}
+	return refs, nil
+}
+
+func (s *MemoryStore) Rollback(ctx context.Context, c chain.Chain, fork BlockRef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	affected := make(map[string]Transfer)
+	kept := s.transfers[:0]
+	for _, t := range s.transfers {
+		if t.Chain == c && t.BlockNumber > fork.Number {
+			affected[nftKey(t.Chain, t.Contract, t.TokenID)] = t
+			continue
+		}
+		kept = append(kept, t)
+	}
+	s.transfers = kept
+	for key, t := range affected {
+		owner := ""
+		for _, k := range s.transfers {
+			if nftKey(k.Chain, k.Contract, k.TokenID) == key {
+				owner = k.To
+			}
+		}
+		s.setOwner(t.Chain, t.Contract, t.TokenID, owner)
+	}
+	for key, cp := range s.checkpoints {
+		if cp.Chain == c && cp.Block > fork.Number {
+			cp.Block, cp.BlockHash = fork.Number, fork.Hash
+			s.checkpoints[key] = cp
+		}
+	}
+	for n := range s.blocks[c] {
+		if n > fork.Number {
+			delete(s.blocks[c], n)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Prune(ctx context.Context, c chain.Chain, below uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for n := range s.blocks[c] {
+		if n < below {
+			delete(s.blocks[c], n)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Tokens(ctx context.Context, c chain.Chain, contract string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, t := range s.transfers {
+		if t.Chain == c && t.Contract == contract && !seen[t.TokenID] {
+			seen[t.TokenID] = true
+			tokens = append(tokens, t.TokenID)
+		}
+	}
+	return tokens, nil
+}
+
+// Owner 返回 NFT 当前的拥有者，不存在或已销毁时返回 false
+func (s *MemoryStore) Owner(c chain.Chain, contract, tokenID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owner, ok := s.owners[nftKey(c, contract, tokenID)]
+	return owner, ok
+}
+
+// MetadataURI 返回 NFT 的元数据地址
+func (s *MemoryStore) MetadataURI(c chain.Chain, contract, tokenID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.metadata[nftKey(c, contract, tokenID)]
+}
+
+// Transfers 返回当前保留的转移历史
+func (s *MemoryStore) Transfers() []Transfer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Transfer(nil), s.transfers...)
+}
+
+func (s *MemoryStore) setOwner(c chain.Chain, contract, tokenID, owner string) {
+	key := nftKey(c, contract, tokenID)
+	if owner == "" {
+		delete(s.owners, key)
+		return
+	}
+	s.owners[key] = owner
+}
+
+func nftKey(c chain.Chain, contract, tokenID string) string {
+	return string(c) + "/" + contract + "/" + tokenID
+}
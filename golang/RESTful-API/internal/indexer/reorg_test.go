@@ -0,0 +1,134 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/evm/evmtest"
+	"context"
+	"errors"
+	"testing"
+)
+
+// carol EIP-55 文档中的示例地址
+const carol = "0x52908400098527886E0F7030069857D2E4169EE7"
+
+// mineAndSync 出一个包含 logs 的区块后同步一轮，模拟索引器按轮询间隔跟上最新区块
+func mineAndSync(t *testing.T, node *evmtest.Node, ix *EVMIndexer, logs ...evm.Log) {
+	t.Helper()
+	node.Mine(1)
+	for _, l := range logs {
+		node.AddLog(l)
+	}
+	if err := ix.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEVMIndexerReorg(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	store := NewMemoryStore()
+	ctx := context.Background()
+	ix := newTestEVMIndexer(t, node, store, EVMOptions{StartBlock: 1})
+
+	mineAndSync(t, node, ix, erc721Transfer(collection, "", alice, 1))
+	mineAndSync(t, node, ix, erc721Transfer(collection, alice, bob, 1))
+	if owner, _ := store.Owner(chain.EVM, collection, "1"); owner != bob {
+		t.Fatalf("owner before reorg %q, want bob", owner)
+	}
+
+	// 区块 2 被替换，新分叉上 token 1 转给了 carol
+	node.Reorg(2)
+	node.Mine(1)
+	node.AddLog(erc721Transfer(collection, alice, carol, 1))
+	node.Mine(1)
+	if err := ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if owner, _ := store.Owner(chain.EVM, collection, "1"); owner != carol {
+		t.Fatalf("owner after reorg %q, want carol", owner)
+	}
+	transfers := store.Transfers()
+	if len(transfers) != 2 || transfers[1].To != carol || transfers[1].BlockHash != node.Header(2).Hash {
+		t.Fatalf("transfers after reorg %+v", transfers)
+	}
+	cp, _ := store.Checkpoint(ctx, chain.EVM, collection)
+	if cp.Block != 3 || cp.BlockHash != node.Header(3).Hash {
+		t.Fatalf("checkpoint %+v", cp)
+	}
+	refs, _ := store.BlockRefs(ctx, chain.EVM, 3, 10)
+	for _, ref := range refs {
+		if ref.Hash != node.Header(ref.Number).Hash {
+			t.Fatalf("stale block ref %+v", ref)
+		}
+	}
+}
+
+func TestEVMIndexerReorgRemovesMint(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	store := NewMemoryStore()
+	ix := newTestEVMIndexer(t, node, store, EVMOptions{StartBlock: 1})
+
+	mineAndSync(t, node, ix)
+	mineAndSync(t, node, ix, erc721Transfer(collection, "", alice, 5))
+
+	// 铸造所在的区块被丢弃，新分叉上没有这笔铸造
+	node.Reorg(2)
+	node.Mine(2)
+	if err := ix.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if owner, ok := store.Owner(chain.EVM, collection, "5"); ok {
+		t.Fatalf("token minted on the dropped fork still owned by %s", owner)
+	}
+	if n := len(store.Transfers()); n != 0 {
+		t.Fatalf("got %d transfers, want 0", n)
+	}
+}
+
+// TestEVMIndexerConfirmations 分叉深度不超过确认数时，被替换的区块从未被索引
+func TestEVMIndexerConfirmations(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	store := NewMemoryStore()
+	ix := newTestEVMIndexer(t, node, store, EVMOptions{StartBlock: 1, Confirmations: 2})
+
+	mineAndSync(t, node, ix)
+	mineAndSync(t, node, ix, erc721Transfer(collection, "", alice, 1))
+	node.Reorg(2)
+	node.Mine(1)
+	node.AddLog(erc721Transfer(collection, "", bob, 1))
+	node.Mine(2)
+	if err := ix.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if owner, _ := store.Owner(chain.EVM, collection, "1"); owner != bob {
+		t.Fatalf("owner %q, want bob", owner)
+	}
+	if n := len(store.Transfers()); n != 1 {
+		t.Fatalf("got %d transfers, want 1", n)
+	}
+}
+
+func TestEVMIndexerReorgTooDeep(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	store := NewMemoryStore()
+	ix := newTestEVMIndexer(t, node, store, EVMOptions{StartBlock: 1, ReorgWindow: 1})
+
+	mineAndSync(t, node, ix, erc721Transfer(collection, "", alice, 1))
+	mineAndSync(t, node, ix)
+	mineAndSync(t, node, ix)
+	// 只保留最近的区块哈希，分叉点已经被清理
+	node.Reorg(1)
+	node.Mine(5)
+	if err := ix.Sync(context.Background()); !errors.Is(err, ErrReorgTooDeep) {
+		t.Fatalf("got %v, want ErrReorgTooDeep", err)
+	}
+	// 找不到分叉点时不回滚，等待人工处理
+	if owner, _ := store.Owner(chain.EVM, collection, "1"); owner != alice {
+		t.Fatalf("owner %q, want alice", owner)
+	}
+}
@@ -0,0 +1,381 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/solana"
+	"RESTful-API/utils/logs"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxSignaturePage getSignaturesForAddress 每页的条数
+const maxSignaturePage = 1000
+
+// SolanaOptions Solana 索引配置
+type SolanaOptions struct {
+	Collections []string // collection 的 mint 地址，自动发现其中已验证的 NFT
+	Mints       []string // 单独跟踪的 NFT mint
+	StartSlot   uint64   // 没有索引进度时的起始 slot
+	Interval    time.Duration
+	// Confirmations 只处理不高于 当前 slot - Confirmations 的交易，Solana 不做回滚处理
+	Confirmations uint64
+}
+
+// SolanaIndexer 通过 getSignaturesForAddress / getTransaction 跟踪 SPL token 转移。
+// Solana 上的 NFT 是供应量为 1 的 mint：nfts.token_id 为 mint 地址，
+// contract_address 为 Metaplex metadata 中已验证的 collection，没有时为 mint 自身
+type SolanaIndexer struct {
+	client *solana.Client
+	store  Store
+	opts   SolanaOptions
+	log    *logs.Logger
+
+	loaded   bool
+	mints    map[string]bool             // 跟踪中的 mint
+	metadata map[string]*solana.Metadata // mint 的 metadata 缓存，nil 表示没有 metadata 账户
+}
+
+// NewSolanaIndexer 创建 Solana 索引器
+func NewSolanaIndexer(client *solana.Client, store Store, opts SolanaOptions) (*SolanaIndexer, error) {
+	for _, list := range [][]string{opts.Collections, opts.Mints} {
+		for _, addr := range list {
+			if _, err := chain.NormalizeAddress(chain.Solana, addr); err != nil {
+				return nil, fmt.Errorf("indexer address %q: %w", addr, err)
+			}
+		}
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	return &SolanaIndexer{
+		client:   client,
+		store:    store,
+		opts:     opts,
+		log:      logs.With(logs.String("indexer", string(chain.Solana))),
+		mints:    make(map[string]bool),
+		metadata: make(map[string]*solana.Metadata),
+	}, nil
+}
+
+// Run 持续索引直到 ctx 结束，单轮出错时记录日志并在下一轮重试
+func (ix *SolanaIndexer) Run(ctx context.Context) {
+	ticker := time.NewTicker(ix.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := ix.Sync(ctx); err != nil && ctx.Err() == nil {
+			ix.log.With(logs.Err(err)).Error("sync failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 先从 collection 的交易中发现新的 mint，再逐个索引 mint 的转移
+func (ix *SolanaIndexer) Sync(ctx context.Context) error {
+	if err := ix.load(ctx); err != nil {
+		return err
+	}
+	slot, err := ix.client.BlockHeight(ctx)
+	if err != nil {
+		return err
+	}
+	if slot < ix.opts.Confirmations {
+		return nil
+	}
+	safe := slot - ix.opts.Confirmations
+
+	for _, collection := range ix.opts.Collections {
+		if err = ix.syncCollection(ctx, collection, safe); err != nil {
+			return fmt.Errorf("collection %s: %w", collection, err)
+		}
+	}
+	mints := make([]string, 0, len(ix.mints))
+	for mint := range ix.mints {
+		mints = append(mints, mint)
+	}
+	sort.Strings(mints)
+	for _, mint := range mints {
+		if err = ix.syncMint(ctx, mint, safe); err != nil {
+			return fmt.Errorf("mint %s: %w", mint, err)
+		}
+	}
+	return nil
+}
+
+// load 首次同步时载入配置的 mint 和之前从 collection 中发现的 mint
+func (ix *SolanaIndexer) load(ctx context.Context) error {
+	if ix.loaded {
+		return nil
+	}
+	for _, mint := range ix.opts.Mints {
+		ix.mints[mint] = true
+	}
+	for _, collection := range ix.opts.Collections {
+		tokens, err := ix.store.Tokens(ctx, chain.Solana, collection)
+		if err != nil {
+			return err
+		}
+		for _, mint := range tokens {
+			ix.mints[mint] = true
+		}
+	}
+	ix.loaded = true
+	return nil
+}
+
+// syncCollection collection 的 mint 会出现在铸造和验证 collection 的交易中，
+// 从这些交易里找出 metadata 指向该 collection 且已验证的 NFT
+func (ix *SolanaIndexer) syncCollection(ctx context.Context, collection string, safe uint64) error {
+	cp, err := ix.store.Checkpoint(ctx, chain.Solana, collection)
+	if err != nil {
+		return err
+	}
+	sigs, err := ix.newSignatures(ctx, collection, cp, safe)
+	if err != nil {
+		return err
+	}
+	for _, s := range sigs {
+		if s.Failed() {
+			continue
+		}
+		tx, err := ix.client.RawTransaction(ctx, s.Signature)
+		if err != nil {
+			return err
+		}
+		if tx.Meta == nil {
+			continue
+		}
+		for _, b := range tx.Meta.PostTokenBalances {
+			if ix.mints[b.Mint] || b.Mint == collection || b.UITokenAmount.Decimals != 0 {
+				continue
+			}
+			md, err := ix.metadataOf(ctx, b.Mint)
+			if err != nil {
+				return err
+			}
+			if md != nil && md.CollectionVerified && md.Collection == collection {
+				ix.mints[b.Mint] = true
+				ix.log.With(logs.String("collection", collection), logs.String("mint", b.Mint)).Info("mint discovered")
+			}
+		}
+	}
+	return ix.store.Apply(ctx, Checkpoint{Chain: chain.Solana, Contract: collection, Block: safe}, nil)
+}
+
+// syncMint 普通的 transfer 指令不包含 mint 账户，不会出现在 mint 的签名列表中，
+// 所以同时查询持有该 mint 的 token 账户的签名，这些地址共用 mint 的索引进度。
+// 转出方和转入方的 token 账户在同步前都已关闭时，这笔 transfer 仍然找不到，拥有者在下一次转移时修正
+func (ix *SolanaIndexer) syncMint(ctx context.Context, mint string, safe uint64) error {
+	cp, err := ix.store.Checkpoint(ctx, chain.Solana, mint)
+	if err != nil {
+		return err
+	}
+	accounts, err := ix.client.MintTokenAccounts(ctx, mint)
+	if err != nil {
+		return err
+	}
+	var sigs []solana.Signature
+	seen := make(map[string]bool)
+	for _, address := range append([]string{mint}, accounts...) {
+		list, err := ix.newSignatures(ctx, address, cp, safe)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			if !seen[s.Signature] {
+				seen[s.Signature] = true
+				sigs = append(sigs, s)
+			}
+		}
+	}
+	// 各地址的签名分别按 slot 排好序，合并后重新排序；同一 slot 内保持 mint 的签名在前
+	sort.SliceStable(sigs, func(i, j int) bool { return sigs[i].Slot < sigs[j].Slot })
+
+	var transfers []Transfer
+	for _, s := range sigs {
+		if s.Failed() {
+			continue
+		}
+		tx, err := ix.client.RawTransaction(ctx, s.Signature)
+		if err != nil {
+			return err
+		}
+		decoded, err := ix.decode(ctx, s.Signature, tx, mint)
+		if err != nil {
+			return err
+		}
+		transfers = append(transfers, decoded...)
+	}
+	if err = ix.store.Apply(ctx, Checkpoint{Chain: chain.Solana, Contract: mint, Block: safe}, transfers); err != nil {
+		return err
+	}
+	if len(transfers) > 0 {
+		ix.log.With(logs.String("mint", mint), logs.Uint64("slot", safe), logs.Int("transfers", len(transfers))).Info("indexed")
+	}
+	return nil
+}
+
+// newSignatures 向前翻页取出 address 在进度 cp 之后、不高于 safe 的签名，按 slot 从旧到新返回
+func (ix *SolanaIndexer) newSignatures(ctx context.Context, address string, cp *Checkpoint, safe uint64) ([]solana.Signature, error) {
+	done := func(slot uint64) bool {
+		if cp != nil {
+			return slot <= cp.Block
+		}
+		return slot < ix.opts.StartSlot
+	}
+
+	var result []solana.Signature
+	before := ""
+	for {
+		page, err := ix.client.SignaturesForAddress(ctx, address, before, "", maxSignaturePage)
+		if err != nil {
+			return nil, err
+		}
+		finished := len(page) < maxSignaturePage
+		for _, s := range page {
+			if done(s.Slot) {
+				finished = true
+				break
+			}
+			if s.Slot <= safe {
+				result = append(result, s)
+			}
+		}
+		if finished {
+			break
+		}
+		before = page[len(page)-1].Signature
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+// metadataOf 查询并缓存 mint 的 Metaplex metadata，没有 metadata 账户时返回 nil
+func (ix *SolanaIndexer) metadataOf(ctx context.Context, mint string) (*solana.Metadata, error) {
+	if md, ok := ix.metadata[mint]; ok {
+		return md, nil
+	}
+	md, err := ix.client.Metadata(ctx, mint)
+	if errors.Is(err, chain.ErrNotFound) || errors.Is(err, solana.ErrMetadataMalformed) {
+		md, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ix.metadata[mint] = md
+	return md, nil
+}
+
+// tokenInstruction jsonParsed 编码的 spl-token 指令
+type tokenInstruction struct {
+	Type string `json:"type"`
+	Info struct {
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+		Account     string `json:"account"`
+		Mint        string `json:"mint"`
+		Amount      string `json:"amount"`
+		TokenAmount struct {
+			Amount string `json:"amount"`
+		} `json:"tokenAmount"`
+	} `json:"info"`
+}
+
+// decode 解析交易中 mint 的 transfer/transferChecked/mintTo/burn 指令（含内部指令），
+// token 账户的所有者取自交易前后的 token 余额
+func (ix *SolanaIndexer) decode(ctx context.Context, signature string, tx *solana.Transaction, mint string) ([]Transfer, error) {
+	if tx.Failed() {
+		return nil, nil
+	}
+	keys := tx.Transaction.Message.AccountKeys
+	type tokenAccount struct{ mint, owner string }
+	accounts := make(map[string]tokenAccount)
+	for _, b := range append(append([]solana.TokenBalance(nil), tx.Meta.PreTokenBalances...), tx.Meta.PostTokenBalances...) {
+		if b.AccountIndex >= 0 && b.AccountIndex < len(keys) {
+			accounts[keys[b.AccountIndex].Pubkey] = tokenAccount{mint: b.Mint, owner: b.Owner}
+		}
+	}
+
+	var instructions []solana.Instruction
+	for i, ins := range tx.Transaction.Message.Instructions {
+		instructions = append(instructions, ins)
+		for _, inner := range tx.Meta.InnerInstructions {
+			if inner.Index == i {
+				instructions = append(instructions, inner.Instructions...)
+			}
+		}
+	}
+
+	md, err := ix.metadataOf(ctx, mint)
+	if err != nil {
+		return nil, err
+	}
+	contract, uri := mint, ""
+	if md != nil {
+		uri = md.URI
+		if md.CollectionVerified && md.Collection != "" {
+			contract = md.Collection
+		}
+	}
+
+	var transfers []Transfer
+	for i, ins := range instructions {
+		if ins.Program != "spl-token" && ins.Program != "spl-token-2022" || len(ins.Parsed) == 0 {
+			continue
+		}
+		var p tokenInstruction
+		if err := json.Unmarshal(ins.Parsed, &p); err != nil {
+			continue // 部分指令的 parsed 是字符串
+		}
+		t := Transfer{
+			Chain:       chain.Solana,
+			Contract:    contract,
+			TokenID:     mint,
+			BlockNumber: tx.Slot,
+			TxHash:      signature,
+			LogIndex:    uint(i),
+			MetadataURI: uri,
+		}
+		amount, instructionMint := p.Info.Amount, p.Info.Mint
+		if amount == "" {
+			amount = p.Info.TokenAmount.Amount
+		}
+		switch p.Type {
+		case "transfer", "transferChecked":
+			src, dst := accounts[p.Info.Source], accounts[p.Info.Destination]
+			if instructionMint == "" {
+				instructionMint = src.mint
+			}
+			t.From, t.To = src.owner, dst.owner
+			if t.From == "" || t.To == "" {
+				continue
+			}
+		case "mintTo", "mintToChecked":
+			t.To = accounts[p.Info.Account].owner
+			if t.To == "" {
+				continue
+			}
+		case "burn", "burnChecked":
+			t.From = accounts[p.Info.Account].owner
+			if instructionMint == "" {
+				instructionMint = accounts[p.Info.Account].mint
+			}
+		default:
+			continue
+		}
+		if instructionMint != mint || amount == "" || amount == "0" {
+			continue
+		}
+		transfers = append(transfers, t)
+	}
+	return transfers, nil
+}
@@ -0,0 +1,260 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/solana"
+	"RESTful-API/internal/chain/solana/solanatest"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/mr-tron/base58"
+	"testing"
+)
+
+// solanaKey 由同一个字节填充的 32 字节公钥，测试中用作各种地址
+func solanaKey(b byte) string {
+	return base58.Encode(bytes.Repeat([]byte{b}, 32))
+}
+
+var (
+	solCollection = solanaKey(1)
+	solMint       = solanaKey(2)
+	solFakeMint   = solanaKey(3) // metadata 声称属于 collection 但未验证
+	solFungible   = solanaKey(4)
+	solAlice      = solanaKey(5)
+	solBob        = solanaKey(6)
+	solAliceATA   = solanaKey(7)
+	solBobATA     = solanaKey(8)
+	solPayer      = solanaKey(9)
+)
+
+// metadataAccount 按 Metaplex MetadataV1 的 Borsh 布局编码 metadata 账户
+func metadataAccount(mint, collection string, verified bool, uri string) []byte {
+	var buf bytes.Buffer
+	pubkey := func(s string) {
+		b, _ := base58.Decode(s)
+		buf.Write(b)
+	}
+	str := func(s string, size int) {
+		padded := s + string(make([]byte, size-len(s))) // 链上字符串按固定长度用 \x00 填充
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(padded)))
+		buf.WriteString(padded)
+	}
+	buf.WriteByte(4)
+	pubkey(solPayer)
+	pubkey(mint)
+	str("Test NFT", 32)
+	str("TNFT", 10)
+	str(uri, 200)
+	buf.Write([]byte{0xf4, 0x01}) // seller_fee_basis_points
+	buf.WriteByte(0)              // creators: None
+	buf.Write([]byte{1, 1})       // primary_sale_happened, is_mutable
+	buf.Write([]byte{1, 255})     // edition_nonce
+	buf.Write([]byte{1, 0})       // token_standard
+	if collection != "" {
+		buf.WriteByte(1)
+		if verified {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		pubkey(collection)
+	} else {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+func setMetadata(t *testing.T, node *solanatest.Node, mint, collection string, verified bool, uri string) {
+	t.Helper()
+	addr, err := solana.MetadataAddress(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.SetAccountData(addr, metadataAccount(mint, collection, verified, uri))
+}
+
+func tokenInstr(typ string, info map[string]interface{}) solana.Instruction {
+	parsed, _ := json.Marshal(map[string]interface{}{"type": typ, "info": info})
+	return solana.Instruction{Program: "spl-token", ProgramID: solana.TokenProgramID, Parsed: parsed}
+}
+
+func tokenBalance(index int, mint, owner string, decimals int) solana.TokenBalance {
+	b := solana.TokenBalance{AccountIndex: index, Mint: mint, Owner: owner}
+	b.UITokenAmount.Amount, b.UITokenAmount.Decimals = "1", decimals
+	return b
+}
+
+// tokenTx 构造 jsonParsed 格式的交易，keys[0] 为付款人
+func tokenTx(keys []string, pre, post []solana.TokenBalance, instructions ...solana.Instruction) *solana.Transaction {
+	tx := &solana.Transaction{Meta: &solana.TransactionMeta{
+		Err:               json.RawMessage("null"),
+		PreTokenBalances:  pre,
+		PostTokenBalances: post,
+	}}
+	for i, k := range keys {
+		tx.Transaction.Message.AccountKeys = append(tx.Transaction.Message.AccountKeys, solana.AccountKey{Pubkey: k, Signer: i == 0})
+	}
+	tx.Transaction.Message.Instructions = instructions
+	return tx
+}
+
+func mintToTx(mint string) *solana.Transaction {
+	return tokenTx([]string{solPayer, solAliceATA}, nil,
+		[]solana.TokenBalance{tokenBalance(1, mint, solAlice, 0)},
+		tokenInstr("mintTo", map[string]interface{}{"account": solAliceATA, "mint": mint, "amount": "1"}))
+}
+
+// transferTx alice 转给 bob，转移指令由其他程序通过 CPI 发起，出现在内部指令中
+func transferTx(mint string) *solana.Transaction {
+	tx := tokenTx([]string{solPayer, solAliceATA, solBobATA},
+		[]solana.TokenBalance{tokenBalance(1, mint, solAlice, 0)},
+		[]solana.TokenBalance{tokenBalance(1, mint, solAlice, 0), tokenBalance(2, mint, solBob, 0)},
+		solana.Instruction{Program: "marketplace", ProgramID: solanaKey(10)})
+	inner := tokenInstr("transferChecked", map[string]interface{}{
+		"source": solAliceATA, "destination": solBobATA, "mint": mint,
+		"tokenAmount": map[string]string{"amount": "1"},
+	})
+	tx.Meta.InnerInstructions = []solana.InnerInstruction{{Index: 0, Instructions: []solana.Instruction{inner}}}
+	return tx
+}
+
+func TestSolanaIndexerCollection(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	store := NewMemoryStore()
+	ctx := context.Background()
+	ix, err := NewSolanaIndexer(node.Client(), store, SolanaOptions{Collections: []string{solCollection}, Confirmations: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setMetadata(t, node, solMint, solCollection, true, "https://example.com/2.json")
+	setMetadata(t, node, solFakeMint, solCollection, false, "https://example.com/3.json")
+	// 铸造交易同时出现在 collection 和 mint 的签名列表中
+	mint := mintToTx(solMint)
+	mint.Meta.PostTokenBalances = append(mint.Meta.PostTokenBalances, tokenBalance(1, solFungible, solAlice, 6))
+	node.AddTransaction("sig-mint", mint, solCollection, solMint)
+	node.AddTransaction("sig-fake", mintToTx(solFakeMint), solCollection, solFakeMint)
+	node.Advance(1)
+	failed := transferTx(solMint)
+	failed.Meta.Err = json.RawMessage(`{"InstructionError":[0,"Custom"]}`)
+	node.AddTransaction("sig-failed", failed, solMint)
+	node.Advance(1)
+	node.AddTransaction("sig-transfer", transferTx(solMint), solMint)
+
+	// 当前 slot 3，确认数 1，只处理到 slot 2
+	if err = ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if owner, _ := store.Owner(chain.Solana, solCollection, solMint); owner != solAlice {
+		t.Fatalf("owner %q, want alice", owner)
+	}
+	if uri := store.MetadataURI(chain.Solana, solCollection, solMint); uri != "https://example.com/2.json" {
+		t.Fatalf("metadata uri %q", uri)
+	}
+	if _, ok := store.Owner(chain.Solana, solFakeMint, solFakeMint); ok {
+		t.Fatal("mint with an unverified collection should not be discovered")
+	}
+	if cp, _ := store.Checkpoint(ctx, chain.Solana, solCollection); cp == nil || cp.Block != 2 {
+		t.Fatalf("collection checkpoint %+v", cp)
+	}
+
+	node.Advance(1)
+	if err = ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if owner, _ := store.Owner(chain.Solana, solCollection, solMint); owner != solBob {
+		t.Fatalf("owner %q, want bob", owner)
+	}
+	if n := len(store.Transfers()); n != 2 {
+		t.Fatalf("got %d transfers, want 2", n)
+	}
+
+	// 重启后从 store 中载入已发现的 mint，不再重复应用
+	restarted, _ := NewSolanaIndexer(node.Client(), store, SolanaOptions{Collections: []string{solCollection}})
+	if err = restarted.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(store.Transfers()); n != 2 || !restarted.mints[solMint] {
+		t.Fatalf("got %d transfers after restart, mints %v", n, restarted.mints)
+	}
+}
+
+func TestSolanaIndexerMintWithoutMetadata(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	store := NewMemoryStore()
+	ix, err := NewSolanaIndexer(node.Client(), store, SolanaOptions{Mints: []string{solMint}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node.AddTransaction("sig-mint", mintToTx(solMint), solMint)
+	node.Advance(1)
+	burn := tokenTx([]string{solAlice, solAliceATA},
+		[]solana.TokenBalance{tokenBalance(1, solMint, solAlice, 0)}, nil,
+		tokenInstr("burn", map[string]interface{}{"account": solAliceATA, "mint": solMint, "amount": "1"}))
+	node.AddTransaction("sig-burn", burn, solMint)
+	if err = ix.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有 metadata 账户时 contract 为 mint 自身；销毁后没有拥有者
+	transfers := store.Transfers()
+	if len(transfers) != 2 || transfers[0].Contract != solMint || transfers[1].From != solAlice || transfers[1].To != "" {
+		t.Fatalf("transfers %+v", transfers)
+	}
+	if _, ok := store.Owner(chain.Solana, solMint, solMint); ok {
+		t.Fatal("burned mint should have no owner")
+	}
+}
+
+// TestSolanaIndexerPlainTransfer 普通 transfer 指令不包含 mint，只出现在 token 账户的签名列表中
+func TestSolanaIndexerPlainTransfer(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	store := NewMemoryStore()
+	ctx := context.Background()
+	ix, err := NewSolanaIndexer(node.Client(), store, SolanaOptions{Mints: []string{solMint}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node.AddTransaction("sig-mint", mintToTx(solMint), solMint, solAliceATA)
+	node.Advance(1)
+	transfer := tokenTx([]string{solAlice, solAliceATA, solBobATA},
+		[]solana.TokenBalance{tokenBalance(1, solMint, solAlice, 0)},
+		[]solana.TokenBalance{tokenBalance(1, solMint, solAlice, 0), tokenBalance(2, solMint, solBob, 0)},
+		tokenInstr("transfer", map[string]interface{}{"source": solAliceATA, "destination": solBobATA, "authority": solAlice, "amount": "1"}))
+	node.AddTransaction("sig-transfer", transfer, solAliceATA, solBobATA)
+	node.SetTokenAccount(solana.TokenAccount{Address: solAliceATA, Mint: solMint, Owner: solAlice, Amount: "0"})
+	node.SetTokenAccount(solana.TokenAccount{Address: solBobATA, Mint: solMint, Owner: solBob, Amount: "1"})
+
+	for i := 0; i < 2; i++ { // 第二次同步没有新交易，同一笔交易不会因出现在多个地址中而重复应用
+		if err = ix.Sync(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if owner, _ := store.Owner(chain.Solana, solMint, solMint); owner != solBob {
+		t.Fatalf("owner %q, want bob", owner)
+	}
+	transfers := store.Transfers()
+	if len(transfers) != 2 || transfers[0].TxHash != "sig-mint" || transfers[1].TxHash != "sig-transfer" || transfers[1].From != solAlice {
+		t.Fatalf("transfers %+v", transfers)
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	md, err := solana.ParseMetadata(metadataAccount(solMint, solCollection, true, "ipfs://cid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Mint != solMint || md.Name != "Test NFT" || md.URI != "ipfs://cid" || md.Collection != solCollection || !md.CollectionVerified {
+		t.Fatalf("unexpected metadata %+v", md)
+	}
+	if _, err = solana.ParseMetadata([]byte{4, 1, 2}); err == nil {
+		t.Fatal("truncated account should fail")
+	}
+}
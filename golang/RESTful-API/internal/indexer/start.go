@@ -0,0 +1,115 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/solana"
+	"RESTful-API/internal/chain/ton"
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/logs"
+	"context"
+	"time"
+)
+
+// Start 按 indexer.ini 在后台启动各链的索引器，未开启或未配置合约时不启动
+func Start() {
+	if !config.GetConfig("indexer.enable").MustBool(false) {
+		return
+	}
+	interval := time.Duration(config.GetConfig("indexer.interval").MustInt(5000)) * time.Millisecond
+	startEVM(context.Background(), interval)
+	startSolana(context.Background(), interval)
+	startTON(context.Background(), interval)
+}
+
+func startEVM(ctx context.Context, interval time.Duration) {
+	contracts := config.GetConfig("indexer.EVM.contracts").Strings(",")
+	if len(contracts) == 0 {
+		return
+	}
+	client, err := chain.Get(chain.EVM)
+	if err != nil {
+		logs.Error("indexer EVM client error: %v", err)
+		return
+	}
+	// 索引需要 eth_getLogs，只能使用 EVM 的具体实现
+	evmClient, ok := client.(*evm.Client)
+	if !ok {
+		logs.Error("indexer EVM client %T does not support eth_getLogs", client)
+		return
+	}
+	ix, err := NewEVMIndexer(evmClient, NewDBStore(), EVMOptions{
+		Contracts:     contracts,
+		StartBlock:    config.GetConfig("indexer.EVM.startBlock").MustUint64(0),
+		BatchSize:     config.GetConfig("indexer.EVM.batchSize").MustUint64(500),
+		Interval:      interval,
+		Confirmations: chain.EndpointFromConfig(chain.EVM).Confirmations,
+		ReorgWindow:   config.GetConfig("indexer.EVM.reorgWindow").MustUint64(0),
+	})
+	if err != nil {
+		logs.Error("indexer EVM config error: %v", err)
+		return
+	}
+	logs.Info("indexer EVM started, contracts: %v", contracts)
+	go ix.Run(ctx)
+}
+
+func startSolana(ctx context.Context, interval time.Duration) {
+	collections := config.GetConfig("indexer.Solana.collections").Strings(",")
+	mints := config.GetConfig("indexer.Solana.mints").Strings(",")
+	if len(collections) == 0 && len(mints) == 0 {
+		return
+	}
+	client, err := chain.Get(chain.Solana)
+	if err != nil {
+		logs.Error("indexer Solana client error: %v", err)
+		return
+	}
+	solanaClient, ok := client.(*solana.Client)
+	if !ok {
+		logs.Error("indexer Solana client %T does not support getSignaturesForAddress", client)
+		return
+	}
+	ix, err := NewSolanaIndexer(solanaClient, NewDBStore(), SolanaOptions{
+		Collections:   collections,
+		Mints:         mints,
+		StartSlot:     config.GetConfig("indexer.Solana.startSlot").MustUint64(0),
+		Interval:      interval,
+		Confirmations: chain.EndpointFromConfig(chain.Solana).Confirmations,
+	})
+	if err != nil {
+		logs.Error("indexer Solana config error: %v", err)
+		return
+	}
+	logs.Info("indexer Solana started, collections: %v, mints: %v", collections, mints)
+	go ix.Run(ctx)
+}
+
+func startTON(ctx context.Context, interval time.Duration) {
+	collections := config.GetConfig("indexer.TON.collections").Strings(",")
+	if len(collections) == 0 {
+		return
+	}
+	client, err := chain.Get(chain.TON)
+	if err != nil {
+		logs.Error("indexer TON client error: %v", err)
+		return
+	}
+	tonClient, ok := client.(*ton.Client)
+	if !ok {
+		logs.Error("indexer TON client %T does not support account transactions", client)
+		return
+	}
+	ix, err := NewTONIndexer(tonClient, NewDBStore(), TONOptions{
+		Collections:   collections,
+		StartLt:       config.GetConfig("indexer.TON.startLt").MustUint64(0),
+		Interval:      interval,
+		Confirmations: chain.EndpointFromConfig(chain.TON).Confirmations,
+	})
+	if err != nil {
+		logs.Error("indexer TON config error: %v", err)
+		return
+	}
+	logs.Info("indexer TON started, collections: %v", collections)
+	go ix.Run(ctx)
+}
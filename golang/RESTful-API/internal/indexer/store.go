@@ -0,0 +1,331 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/model"
+	"context"
+	"gorm.io/gorm"
+)
+
+// dbStore 基于 nfts、nft_transfers、nft_vouchers、indexer_checkpoints、indexer_blocks 表的存储
+type dbStore struct{}
+
+var _ Store = (*dbStore)(nil)
+
+// NewDBStore 创建数据库存储
+func NewDBStore() Store {
+	return &dbStore{}
+}
+
+func (s *dbStore) Checkpoint(ctx context.Context, c chain.Chain, contract string) (*Checkpoint, error) {
+	dao := &model.IndexerCheckpointsModel{}
+	err := model.NewIndexerCheckpointsModel().WithContext(ctx).QueryOne(map[string]interface{}{
+		"chain = ?":            string(c),
+		"contract_address = ?": contract,
+	}, dao)
+	if err != nil {
+		return nil, err
+	}
+	if dao.ID == 0 {
+		return nil, nil
+	}
+	return &Checkpoint{Chain: c, Contract: contract, Block: dao.BlockNumber, BlockHash: dao.BlockHash}, nil
+}
+
+func (s *dbStore) Apply(ctx context.Context, cp Checkpoint, transfers []Transfer) error {
+	tx, err := model.TxBegin()
+	if err != nil {
+		return err
+	}
+	for _, t := range transfers {
+		if err = recordTransfer(ctx, tx, t); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err = setOwner(ctx, tx, t.Chain, t.Contract, t.TokenID, t.To, t.MetadataURI); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = saveCheckpoint(ctx, tx, cp); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if cp.BlockHash != "" {
+		if err = saveBlock(ctx, tx, cp.Chain, BlockRef{Number: cp.Block, Hash: cp.BlockHash}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return model.TxCommit(tx)
+}
+
+func (s *dbStore) BlockRefs(ctx context.Context, c chain.Chain, below uint64, limit int) ([]BlockRef, error) {
+	var list []model.IndexerBlocksModel
+	err := model.NewIndexerBlocksModel().WithContext(ctx).List(map[string]interface{}{
+		"chain = ?":         string(c),
+		"block_number <= ?": below,
+	}, 1, limit, &list, "block_number DESC")
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]BlockRef, 0, len(list))
+	for _, b := range list {
+		refs = append(refs, BlockRef{Number: b.BlockNumber, Hash: b.BlockHash})
+	}
+	return refs, nil
+}
+
+func (s *dbStore) Rollback(ctx context.Context, c chain.Chain, fork BlockRef) error {
+	tx, err := model.TxBegin()
+	if err != nil {
+		return err
+	}
+	if err = rollback(ctx, tx, c, fork); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return model.TxCommit(tx)
+}
+
+func (s *dbStore) Prune(ctx context.Context, c chain.Chain, below uint64) error {
+	return model.NewIndexerBlocksModel().WithContext(ctx).Delete(map[string]interface{}{
+		"chain = ?":        string(c),
+		"block_number < ?": below,
+	}, &model.IndexerBlocksModel{})
+}
+
+func (s *dbStore) Tokens(ctx context.Context, c chain.Chain, contract string) ([]string, error) {
+	var list []model.NftsModel
+	// 懒铸造的草稿还没有 token_id
+	err := model.NewNftsModel().WithContext(ctx).ListNoPage(map[string]interface{}{
+		"chain = ?":            string(c),
+		"contract_address = ?": contract,
+		"token_id <> ?":        "",
+	}, &list)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]string, 0, len(list))
+	for _, n := range list {
+		tokens = append(tokens, n.TokenID)
+	}
+	return tokens, nil
+}
+
+func rollback(ctx context.Context, tx *gorm.DB, c chain.Chain, fork BlockRef) error {
+	after := map[string]interface{}{
+		"chain = ?":        string(c),
+		"block_number > ?": fork.Number,
+	}
+
+	// 回滚区间内涉及的 NFT，删除转移记录后按剩余的最后一次转移恢复拥有者
+	var affected []model.NftTransfersModel
+	if err := model.NewNftTransfersModel(tx).WithContext(ctx).ListAndGroupBy(after, &affected,
+		[]string{"contract_address", "token_id"}, "contract_address, token_id"); err != nil {
+		return err
+	}
+	if err := model.NewNftTransfersModel(tx).WithContext(ctx).Delete(after, &model.NftTransfersModel{}); err != nil {
+		return err
+	}
+	for _, a := range affected {
+		last := &model.NftTransfersModel{}
+		err := model.NewNftTransfersModel(tx).WithContext(ctx).QueryOne(map[string]interface{}{
+			"chain = ?":            string(c),
+			"contract_address = ?": a.ContractAddress,
+			"token_id = ?":         a.TokenID,
+		}, last, "block_number DESC", "log_index DESC")
+		if err != nil {
+			return err
+		}
+		// 懒铸造的 NFT 铸造被回滚时恢复为草稿，凭证可以再次兑换
+		if last.ID == 0 {
+			demoted, err := demoteDraft(ctx, tx, c, a.ContractAddress, a.TokenID)
+			if err != nil {
+				return err
+			}
+			if demoted {
+				continue
+			}
+		}
+		// 没有剩余记录时 last.ToAddress 为空，拥有者置空
+		if err = setOwner(ctx, tx, c, a.ContractAddress, a.TokenID, last.ToAddress, ""); err != nil {
+			return err
+		}
+	}
+
+	// 分叉之后记录的成交和 swap 已不在链上，删除后需要重新提交
+	if err := model.NewNftTransactionsModel(tx).WithContext(ctx).Delete(after, &model.NftTransactionsModel{}); err != nil {
+		return err
+	}
+	if err := model.NewSwapTransactionsModel(tx).WithContext(ctx).Delete(after, &model.SwapTransactionsModel{}); err != nil {
+		return err
+	}
+
+	if _, err := model.NewIndexerCheckpointsModel(tx).WithContext(ctx).Update(map[string]interface{}{
+		"block_number": fork.Number,
+		"block_hash":   fork.Hash,
+	}, after); err != nil {
+		return err
+	}
+	return model.NewIndexerBlocksModel(tx).WithContext(ctx).Delete(after, &model.IndexerBlocksModel{})
+}
+
+func recordTransfer(ctx context.Context, tx *gorm.DB, t Transfer) error {
+	err := model.NewNftTransfersModel(tx).WithContext(ctx).Create(&model.NftTransfersModel{
+		Chain:           string(t.Chain),
+		ContractAddress: t.Contract,
+		TokenID:         t.TokenID,
+		FromAddress:     t.From,
+		ToAddress:       t.To,
+		BlockNumber:     t.BlockNumber,
+		BlockHash:       t.BlockHash,
+		TxHash:          t.TxHash,
+		LogIndex:        t.LogIndex,
+	})
+	// 进度保存失败后重新索引同一区间时，转移记录已经存在
+	if model.IsUniqueErr(err) {
+		return nil
+	}
+	return err
+}
+
+// setOwner 更新 NFT 拥有者，owner 为空表示已销毁，metadataURI 为空时不更新；首次出现的 NFT 以未上架状态写入
+func setOwner(ctx context.Context, tx *gorm.DB, c chain.Chain, contract, tokenID, owner, metadataURI string) error {
+	filters := map[string]interface{}{
+		"chain = ?":            string(c),
+		"contract_address = ?": contract,
+		"token_id = ?":         tokenID,
+	}
+	nft := &model.NftsModel{}
+	if err := model.NewNftsModel(tx).WithContext(ctx).QueryOne(filters, nft); err != nil {
+		return err
+	}
+	data := map[string]interface{}{"owner_wallet_address": nil}
+	if nft.ID == 0 {
+		if owner == "" {
+			return nil
+		}
+		draftID, err := promoteDraft(ctx, tx, c, contract, tokenID)
+		if err != nil {
+			return err
+		}
+		if draftID == 0 {
+			return model.NewNftsModel(tx).WithContext(ctx).Create(&model.NftsModel{
+				ContractAddress:    contract,
+				OwnerWalletAddress: owner,
+				Chain:              string(c),
+				TokenID:            tokenID,
+				MetadataURI:        metadataURI,
+				Status:             model.NftStatusUnlisted,
+			})
+		}
+		nft.ID = draftID
+		data["token_id"] = tokenID
+	}
+
+	if owner != "" {
+		data["owner_wallet_address"] = owner
+	}
+	if metadataURI != "" {
+		data["metadata_uri"] = metadataURI
+	}
+	// 带上 chain 条件，BaseModel.Update 按该链规范化拥有者地址
+	_, err := model.NewNftsModel(tx).WithContext(ctx).Update(data, map[string]interface{}{"id = ?": nft.ID, "chain = ?": string(c)})
+	return err
+}
+
+// promoteDraft 铸造的 tokenId 有未兑换的懒铸造凭证时，把凭证对应的草稿转为正式 NFT，返回草稿 id；没有凭证时返回 0
+func promoteDraft(ctx context.Context, tx *gorm.DB, c chain.Chain, contract, tokenID string) (int64, error) {
+	voucher := &model.NftVouchersModel{}
+	err := model.NewNftVouchersModel(tx).WithContext(ctx).QueryOne(map[string]interface{}{
+		"chain = ?":            string(c),
+		"contract_address = ?": contract,
+		"token_id = ?":         tokenID,
+		"status = ?":           model.NftVoucherStatusPending,
+	}, voucher)
+	if err != nil || voucher.ID == 0 {
+		return 0, err
+	}
+	if _, err = model.NewNftVouchersModel(tx).WithContext(ctx).Update(map[string]interface{}{
+		"status": model.NftVoucherStatusMinted,
+	}, map[string]interface{}{"id = ?": voucher.ID}); err != nil {
+		return 0, err
+	}
+	return voucher.NftID, nil
+}
+
+// demoteDraft 铸造被链重组回滚时把 NFT 恢复为草稿：清空 token_id，拥有者改回创作者；不是懒铸造的 NFT 返回 false
+func demoteDraft(ctx context.Context, tx *gorm.DB, c chain.Chain, contract, tokenID string) (bool, error) {
+	voucher := &model.NftVouchersModel{}
+	err := model.NewNftVouchersModel(tx).WithContext(ctx).QueryOne(map[string]interface{}{
+		"chain = ?":            string(c),
+		"contract_address = ?": contract,
+		"token_id = ?":         tokenID,
+		"status = ?":           model.NftVoucherStatusMinted,
+	}, voucher)
+	if err != nil || voucher.ID == 0 {
+		return false, err
+	}
+	if _, err = model.NewNftVouchersModel(tx).WithContext(ctx).Update(map[string]interface{}{
+		"status": model.NftVoucherStatusPending,
+	}, map[string]interface{}{"id = ?": voucher.ID}); err != nil {
+		return false, err
+	}
+	_, err = model.NewNftsModel(tx).WithContext(ctx).Update(map[string]interface{}{
+		"token_id":             nil,
+		"owner_wallet_address": voucher.CreatorWalletAddress,
+		"status":               model.NftStatusUnlisted,
+	}, map[string]interface{}{"id = ?": voucher.NftID, "chain = ?": string(c)})
+	return true, err
+}
+
+func saveCheckpoint(ctx context.Context, tx *gorm.DB, cp Checkpoint) error {
+	filters := map[string]interface{}{
+		"chain = ?":            string(cp.Chain),
+		"contract_address = ?": cp.Contract,
+	}
+	dao := &model.IndexerCheckpointsModel{}
+	if err := model.NewIndexerCheckpointsModel(tx).WithContext(ctx).QueryOne(filters, dao); err != nil {
+		return err
+	}
+	if dao.ID == 0 {
+		return model.NewIndexerCheckpointsModel(tx).WithContext(ctx).Create(&model.IndexerCheckpointsModel{
+			Chain:           string(cp.Chain),
+			ContractAddress: cp.Contract,
+			BlockNumber:     cp.Block,
+			BlockHash:       cp.BlockHash,
+		})
+	}
+	_, err := model.NewIndexerCheckpointsModel(tx).WithContext(ctx).Update(map[string]interface{}{
+		"block_number": cp.Block,
+		"block_hash":   cp.BlockHash,
+	}, map[string]interface{}{"id = ?": dao.ID})
+	return err
+}
+
+// saveBlock 记录区块哈希，多个合约的进度可能落在同一个区块上
+func saveBlock(ctx context.Context, tx *gorm.DB, c chain.Chain, ref BlockRef) error {
+	filters := map[string]interface{}{
+		"chain = ?":        string(c),
+		"block_number = ?": ref.Number,
+	}
+	dao := &model.IndexerBlocksModel{}
+	if err := model.NewIndexerBlocksModel(tx).WithContext(ctx).QueryOne(filters, dao); err != nil {
+		return err
+	}
+	if dao.ID == 0 {
+		return model.NewIndexerBlocksModel(tx).WithContext(ctx).Create(&model.IndexerBlocksModel{
+			Chain:       string(c),
+			BlockNumber: ref.Number,
+			BlockHash:   ref.Hash,
+		})
+	}
+	if dao.BlockHash == ref.Hash {
+		return nil
+	}
+	_, err := model.NewIndexerBlocksModel(tx).WithContext(ctx).Update(map[string]interface{}{
+		"block_hash": ref.Hash,
+	}, map[string]interface{}{"id = ?": dao.ID})
+	return err
+}
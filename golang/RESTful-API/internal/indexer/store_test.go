@@ -0,0 +1,238 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/evm/evmtest"
+	"RESTful-API/internal/lazymint"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/model/modeltest"
+	"RESTful-API/internal/money"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// useStoreDB 建好 dbStore 读写的表
+func useStoreDB(t *testing.T) {
+	t.Helper()
+	modeltest.Open(t, &model.NftsModel{}, &model.NftTransfersModel{}, &model.NftVouchersModel{},
+		&model.IndexerCheckpointsModel{}, &model.IndexerBlocksModel{},
+		&model.NftTransactionsModel{}, &model.SwapTransactionsModel{})
+}
+
+func blockHash(fork string, number uint64) string {
+	return fmt.Sprintf("0x%s%063d", fork, number)
+}
+
+// applyBlock 把 transfers 作为 number 区块的内容写入 store
+func applyBlock(t *testing.T, store Store, fork string, number uint64, transfers ...Transfer) {
+	t.Helper()
+	for i := range transfers {
+		transfers[i].Chain, transfers[i].Contract = chain.EVM, collection
+		transfers[i].BlockNumber, transfers[i].BlockHash = number, blockHash(fork, number)
+		transfers[i].TxHash, transfers[i].LogIndex = fmt.Sprintf("0x%s%d%d", fork, number, i), uint(i)
+	}
+	cp := Checkpoint{Chain: chain.EVM, Contract: collection, Block: number, BlockHash: blockHash(fork, number)}
+	if err := store.Apply(context.Background(), cp, transfers); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadNFT(t *testing.T, id int64) *model.NftsModel {
+	t.Helper()
+	nft := &model.NftsModel{}
+	if err := model.NewNftsModel().WithContext(context.Background()).QueryOne(map[string]interface{}{"id = ?": id}, nft); err != nil {
+		t.Fatal(err)
+	}
+	return nft
+}
+
+func nftByToken(t *testing.T, tokenID string) *model.NftsModel {
+	t.Helper()
+	nft := &model.NftsModel{}
+	if err := model.NewNftsModel().WithContext(context.Background()).QueryOne(map[string]interface{}{
+		"chain = ?": string(chain.EVM), "contract_address = ?": collection, "token_id = ?": tokenID,
+	}, nft); err != nil {
+		t.Fatal(err)
+	}
+	return nft
+}
+
+func voucherStatus(t *testing.T, id int64) string {
+	t.Helper()
+	voucher := &model.NftVouchersModel{}
+	if err := model.NewNftVouchersModel().WithContext(context.Background()).QueryOne(map[string]interface{}{"id = ?": id}, voucher); err != nil {
+		t.Fatal(err)
+	}
+	return voucher.Status
+}
+
+// createDraft 写入懒铸造的草稿和待兑换的凭证
+func createDraft(t *testing.T, tokenID, creator string) (*model.NftsModel, *model.NftVouchersModel) {
+	t.Helper()
+	ctx := context.Background()
+	draft := &model.NftsModel{ContractAddress: collection, OwnerWalletAddress: creator, Chain: string(chain.EVM), MetadataURI: "ipfs://draft"}
+	if err := model.NewNftsModel().WithContext(ctx).Create(draft); err != nil {
+		t.Fatal(err)
+	}
+	voucher := &model.NftVouchersModel{
+		NftID: draft.ID, Chain: string(chain.EVM), ContractAddress: collection, TokenID: tokenID,
+		CreatorWalletAddress: creator, MetadataURI: draft.MetadataURI, Status: model.NftVoucherStatusPending,
+	}
+	if err := model.NewNftVouchersModel().WithContext(ctx).Create(voucher); err != nil {
+		t.Fatal(err)
+	}
+	return draft, voucher
+}
+
+func TestDBStoreRollback(t *testing.T) {
+	useStoreDB(t)
+	ctx := context.Background()
+	store := NewDBStore()
+	draft, voucher := createDraft(t, "9", alice)
+
+	applyBlock(t, store, "a", 10, Transfer{TokenID: "1", To: alice, MetadataURI: "ipfs://1"})
+	applyBlock(t, store, "a", 11,
+		Transfer{TokenID: "1", From: alice, To: bob},
+		Transfer{TokenID: "2", To: bob},
+		Transfer{TokenID: "9", To: carol}) // 兑换懒铸造凭证
+	for _, block := range []uint64{10, 11} {
+		if err := model.NewNftTransactionsModel().WithContext(ctx).Create(&model.NftTransactionsModel{
+			NftID: 1, Price: money.NewFromInt(1), TxHash: fmt.Sprintf("0xtrade%d", block), Chain: string(chain.EVM), BlockNumber: block,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := model.NewSwapTransactionsModel().WithContext(ctx).Create(&model.SwapTransactionsModel{
+			WalletID: 1, TransactionHash: fmt.Sprintf("0xswap%d", block), Chain: string(chain.EVM), BlockNumber: block,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 铸造时草稿转为正式 NFT，不另建记录
+	if nft := nftByToken(t, "1"); nft.OwnerWalletAddress != bob || nft.MetadataURI != "ipfs://1" {
+		t.Fatalf("token 1 before rollback: %+v", nft)
+	}
+	if nft := loadNFT(t, draft.ID); nft.TokenID != "9" || nft.OwnerWalletAddress != carol {
+		t.Fatalf("draft before rollback: %+v", nft)
+	}
+	if status := voucherStatus(t, voucher.ID); status != model.NftVoucherStatusMinted {
+		t.Fatalf("voucher before rollback: %s", status)
+	}
+
+	if err := store.Rollback(ctx, chain.EVM, BlockRef{Number: 10, Hash: blockHash("a", 10)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 拥有者按剩余的最后一次转移恢复，没有剩余转移的 NFT 拥有者置空
+	if nft := nftByToken(t, "1"); nft.OwnerWalletAddress != alice || nft.MetadataURI != "ipfs://1" {
+		t.Fatalf("token 1 after rollback: %+v", nft)
+	}
+	if nft := nftByToken(t, "2"); nft.ID == 0 || nft.OwnerWalletAddress != "" {
+		t.Fatalf("token 2 after rollback: %+v", nft)
+	}
+	// 懒铸造的 NFT 恢复为草稿，凭证可以再次兑换
+	if nft := loadNFT(t, draft.ID); nft.TokenID != "" || nft.OwnerWalletAddress != alice || nft.Status != model.NftStatusUnlisted {
+		t.Fatalf("draft after rollback: %+v", nft)
+	}
+	if status := voucherStatus(t, voucher.ID); status != model.NftVoucherStatusPending {
+		t.Fatalf("voucher after rollback: %s", status)
+	}
+
+	var trades []model.NftTransactionsModel
+	if err := model.NewNftTransactionsModel().WithContext(ctx).ListNoPage(map[string]interface{}{}, &trades); err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].BlockNumber != 10 {
+		t.Fatalf("nft transactions after rollback: %+v", trades)
+	}
+	var swaps []model.SwapTransactionsModel
+	if err := model.NewSwapTransactionsModel().WithContext(ctx).ListNoPage(map[string]interface{}{}, &swaps); err != nil {
+		t.Fatal(err)
+	}
+	if len(swaps) != 1 || swaps[0].BlockNumber != 10 {
+		t.Fatalf("swap transactions after rollback: %+v", swaps)
+	}
+	var transfers []model.NftTransfersModel
+	if err := model.NewNftTransfersModel().WithContext(ctx).ListNoPage(map[string]interface{}{}, &transfers); err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 1 || transfers[0].BlockNumber != 10 {
+		t.Fatalf("transfers after rollback: %+v", transfers)
+	}
+
+	cp, err := store.Checkpoint(ctx, chain.EVM, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Block != 10 || cp.BlockHash != blockHash("a", 10) {
+		t.Fatalf("checkpoint after rollback: %+v", cp)
+	}
+	refs, err := store.BlockRefs(ctx, chain.EVM, 100, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Number != 10 {
+		t.Fatalf("block refs after rollback: %+v", refs)
+	}
+	// 草稿没有 token_id，不在已知 token 中
+	tokens, err := store.Tokens(ctx, chain.EVM, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, []string{"1", "2"}) {
+		t.Fatalf("tokens after rollback: %v", tokens)
+	}
+
+	// 新分叉上凭证由 bob 兑换，草稿再次转为正式 NFT
+	applyBlock(t, store, "b", 11, Transfer{TokenID: "9", To: bob})
+	if nft := loadNFT(t, draft.ID); nft.TokenID != "9" || nft.OwnerWalletAddress != bob {
+		t.Fatalf("draft after re-mint: %+v", nft)
+	}
+	if status := voucherStatus(t, voucher.ID); status != model.NftVoucherStatusMinted {
+		t.Fatalf("voucher after re-mint: %s", status)
+	}
+}
+
+// TestEVMIndexerPromotesDraft 索引器发现懒铸造凭证的铸造后，草稿转为正式 NFT
+func TestEVMIndexerPromotesDraft(t *testing.T) {
+	useStoreDB(t)
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx := context.Background()
+	store := NewDBStore()
+	ix := newTestEVMIndexer(t, node, store, EVMOptions{StartBlock: 1})
+
+	tokenID, err := lazymint.TokenID(alice, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft, voucher := createDraft(t, tokenID.String(), alice)
+	// 其他 tokenId 的铸造不影响草稿
+	mineAndSync(t, node, ix, erc721Transfer(collection, "", bob, 5))
+	if nft := loadNFT(t, draft.ID); nft.TokenID != "" || voucherStatus(t, voucher.ID) != model.NftVoucherStatusPending {
+		t.Fatalf("draft promoted by another mint: %+v", nft)
+	}
+
+	mineAndSync(t, node, ix, evm.Log{
+		Address: collection,
+		Topics:  []string{TopicTransfer, addressTopic(""), addressTopic(carol), fmt.Sprintf("0x%064x", tokenID)},
+	})
+	nft := loadNFT(t, draft.ID)
+	if nft.TokenID != tokenID.String() || nft.OwnerWalletAddress != carol || nft.MetadataURI != "ipfs://draft" {
+		t.Fatalf("draft after mint: %+v", nft)
+	}
+	if status := voucherStatus(t, voucher.ID); status != model.NftVoucherStatusMinted {
+		t.Fatalf("voucher after mint: %s", status)
+	}
+	// 草稿就是铸造出的 NFT，不会另建一条记录
+	tokens, err := store.Tokens(ctx, chain.EVM, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, []string{tokenID.String(), "5"}) {
+		t.Fatalf("tokens %v", tokens)
+	}
+}
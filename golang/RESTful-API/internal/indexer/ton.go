@@ -0,0 +1,249 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/ton"
+	"RESTful-API/utils/logs"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// tonPageSize 单次查询账户交易的数量
+const tonPageSize = 256
+
+// TONOptions TON 索引配置
+type TONOptions struct {
+	Collections []string // TEP-62 collection 地址
+	StartLt     uint64   // 没有索引进度时 collection 交易的起始逻辑时间
+	Interval    time.Duration
+	// Confirmations 交易所在的 masterchain 区块至少有多少个确认才处理
+	Confirmations uint64
+}
+
+// tonItem 跟踪中的 NFT item
+type tonItem struct {
+	collection string
+	index      string
+	contentURI string
+}
+
+// TONIndexer 通过 toncenter 兼容的 API 跟踪 TEP-62 collection 中的 item。
+// 每个 item 是独立的合约，按账户的逻辑时间 lt 读取其交易，进度也记录为 lt：
+// nfts.contract_address 为 collection 地址，token_id 为 item 的序号，地址均为规范的原始格式
+type TONIndexer struct {
+	client *ton.Client
+	store  Store
+	opts   TONOptions
+	log    *logs.Logger
+
+	loaded map[string]bool     // 已载入 item 列表的 collection
+	items  map[string]*tonItem // item 地址 -> item
+}
+
+// NewTONIndexer 创建 TON 索引器，collection 地址统一转为规范形式
+func NewTONIndexer(client *ton.Client, store Store, opts TONOptions) (*TONIndexer, error) {
+	collections := make([]string, 0, len(opts.Collections))
+	for _, addr := range opts.Collections {
+		normalized, err := chain.NormalizeAddress(chain.TON, addr)
+		if err != nil {
+			return nil, fmt.Errorf("indexer collection %q: %w", addr, err)
+		}
+		collections = append(collections, normalized)
+	}
+	opts.Collections = collections
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	return &TONIndexer{
+		client: client,
+		store:  store,
+		opts:   opts,
+		log:    logs.With(logs.String("indexer", string(chain.TON))),
+		loaded: make(map[string]bool),
+		items:  make(map[string]*tonItem),
+	}, nil
+}
+
+// Run 持续索引直到 ctx 结束，单轮出错时记录日志并在下一轮重试
+func (ix *TONIndexer) Run(ctx context.Context) {
+	ticker := time.NewTicker(ix.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := ix.Sync(ctx); err != nil && ctx.Err() == nil {
+			ix.log.With(logs.Err(err)).Error("sync failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 载入 collection 的 item 列表，从 collection 的交易中发现新部署的 item，再逐个处理 item 的交易
+func (ix *TONIndexer) Sync(ctx context.Context) error {
+	head, err := ix.client.BlockHeight(ctx)
+	if err != nil {
+		return err
+	}
+	for _, collection := range ix.opts.Collections {
+		if err = ix.load(ctx, collection); err != nil {
+			return fmt.Errorf("collection %s: %w", collection, err)
+		}
+		if err = ix.syncCollection(ctx, collection, head); err != nil {
+			return fmt.Errorf("collection %s: %w", collection, err)
+		}
+	}
+	addresses := make([]string, 0, len(ix.items))
+	for addr := range ix.items {
+		addresses = append(addresses, addr)
+	}
+	sort.Strings(addresses)
+	for _, addr := range addresses {
+		if err = ix.syncItem(ctx, addr, head); err != nil {
+			return fmt.Errorf("item %s: %w", addr, err)
+		}
+	}
+	return nil
+}
+
+// load 首次同步时读取 collection 信息和已有的全部 item
+func (ix *TONIndexer) load(ctx context.Context, collection string) error {
+	if ix.loaded[collection] {
+		return nil
+	}
+	info, err := ix.client.NFTCollection(ctx, collection)
+	if err != nil {
+		return err
+	}
+	for offset := 0; ; {
+		items, err := ix.client.NFTItems(ctx, collection, offset, 0)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			ix.track(item)
+		}
+		if len(items) == 0 {
+			break
+		}
+		offset += len(items)
+	}
+	ix.loaded[collection] = true
+	ix.log.With(logs.String("collection", collection), logs.String("nextItemIndex", info.NextItemIndex)).Info("collection loaded")
+	return nil
+}
+
+func (ix *TONIndexer) track(item ton.NFTItem) {
+	if _, ok := ix.items[item.Address]; ok {
+		return
+	}
+	ix.items[item.Address] = &tonItem{collection: item.Collection, index: item.Index, contentURI: item.ContentURI}
+}
+
+// syncCollection collection 部署 item 时会向 item 地址发送消息，
+// 对出站消息的目标地址查询 item，属于该 collection 的即为新的 item
+func (ix *TONIndexer) syncCollection(ctx context.Context, collection string, head uint64) error {
+	return ix.syncAccount(ctx, collection, ix.opts.StartLt, head, func(tx ton.Transaction) ([]Transfer, error) {
+		for _, msg := range tx.OutMsgs {
+			dest, err := chain.NormalizeAddress(chain.TON, msg.Destination)
+			if err != nil || ix.items[dest] != nil {
+				continue
+			}
+			item, err := ix.client.NFTItem(ctx, collection, dest)
+			if errors.Is(err, chain.ErrNotFound) {
+				continue // 发给钱包的找零等消息
+			}
+			if err != nil {
+				return nil, err
+			}
+			if item.Collection == collection {
+				ix.track(*item)
+				ix.log.With(logs.String("collection", collection), logs.String("item", dest), logs.String("index", item.Index)).Info("item discovered")
+			}
+		}
+		return nil, nil
+	})
+}
+
+func (ix *TONIndexer) syncItem(ctx context.Context, addr string, head uint64) error {
+	item := ix.items[addr]
+	return ix.syncAccount(ctx, addr, 0, head, func(tx ton.Transaction) ([]Transfer, error) {
+		t, ok := ix.decode(tx, item)
+		if !ok {
+			return nil, nil
+		}
+		return []Transfer{t}, nil
+	})
+}
+
+// syncAccount 按 lt 升序处理 account 在进度之后、已确认的交易，并在同一批中记录新的进度
+func (ix *TONIndexer) syncAccount(ctx context.Context, account string, startLt, head uint64,
+	handle func(tx ton.Transaction) ([]Transfer, error)) error {
+	cp, err := ix.store.Checkpoint(ctx, chain.TON, account)
+	if err != nil {
+		return err
+	}
+	if cp != nil {
+		startLt = cp.Block + 1
+	}
+
+	var (
+		transfers []Transfer
+		last      uint64
+	)
+	for {
+		txs, err := ix.client.Transactions(ctx, account, startLt, tonPageSize)
+		if err != nil {
+			return err
+		}
+		confirmed := true
+		for _, tx := range txs {
+			if tx.McBlockSeqno == 0 || tx.McBlockSeqno+ix.opts.Confirmations > head+1 {
+				confirmed = false
+				break
+			}
+			decoded, err := handle(tx)
+			if err != nil {
+				return err
+			}
+			transfers = append(transfers, decoded...)
+			last = tx.LT()
+		}
+		if !confirmed || len(txs) < tonPageSize {
+			break
+		}
+		startLt = last + 1
+	}
+	if last == 0 {
+		return nil
+	}
+	if err = ix.store.Apply(ctx, Checkpoint{Chain: chain.TON, Contract: account, Block: last}, transfers); err != nil {
+		return err
+	}
+	if len(transfers) > 0 {
+		ix.log.With(logs.String("account", account), logs.Uint64("lt", last), logs.Int("transfers", len(transfers))).Info("indexed")
+	}
+	return nil
+}
+
+// decode 解析 item 的一笔交易，规则见 ton.DecodeItemTransfer
+func (ix *TONIndexer) decode(tx ton.Transaction, item *tonItem) (Transfer, bool) {
+	from, to, ok := ton.DecodeItemTransfer(tx, item.collection)
+	if !ok {
+		return Transfer{}, false
+	}
+	return Transfer{
+		Chain:       chain.TON,
+		Contract:    item.collection,
+		TokenID:     item.index,
+		From:        from,
+		To:          to,
+		BlockNumber: tx.LT(),
+		TxHash:      tx.Hash,
+		MetadataURI: item.contentURI,
+	}, true
+}
@@ -0,0 +1,163 @@
+package indexer
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/ton"
+	"RESTful-API/internal/chain/ton/tontest"
+	"context"
+	"encoding/base64"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"strings"
+	"testing"
+)
+
+var (
+	tonCollection = "0:" + strings.Repeat("c1", 32)
+	tonItem0      = "0:" + strings.Repeat("a0", 32)
+	tonItem1      = "0:" + strings.Repeat("a1", 32)
+	tonAlice      = "0:" + strings.Repeat("e1", 32)
+	tonBob        = "0:" + strings.Repeat("e2", 32)
+	tonCarol      = "0:" + strings.Repeat("e3", 32)
+)
+
+func tonBody(b *cell.Builder) string {
+	return base64.StdEncoding.EncodeToString(b.EndCell().ToBOC())
+}
+
+// itemInitBody collection 部署标准 nft-item 时发送的 owner_address + ^content
+func itemInitBody(owner string) string {
+	return tonBody(cell.BeginCell().MustStoreAddr(address.MustParseRawAddr(owner)).MustStoreRef(cell.BeginCell().EndCell()))
+}
+
+func transferBody(newOwner string) string {
+	return tonBody(cell.BeginCell().MustStoreUInt(uint64(ton.OpNFTTransfer), 32).MustStoreUInt(1, 64).
+		MustStoreAddr(address.MustParseRawAddr(newOwner)).MustStoreAddr(address.MustParseRawAddr(newOwner)))
+}
+
+func ownershipAssignedBody(prevOwner string) string {
+	return tonBody(cell.BeginCell().MustStoreUInt(uint64(ton.OpOwnershipAssigned), 32).MustStoreUInt(1, 64).
+		MustStoreAddr(address.MustParseRawAddr(prevOwner)).MustStoreBoolBit(false))
+}
+
+// message toncenter 返回大写的原始地址
+func message(source, dest, body string) *ton.Message {
+	m := &ton.Message{Source: strings.ToUpper(source), Destination: strings.ToUpper(dest), Value: "50000000"}
+	m.MessageContent.Body = body
+	return m
+}
+
+func deployTx(item, owner string) ton.Transaction {
+	return ton.Transaction{
+		Account:    strings.ToUpper(item),
+		OrigStatus: "uninit",
+		EndStatus:  "active",
+		InMsg:      message(tonCollection, item, itemInitBody(owner)),
+	}
+}
+
+func itemTransferTx(item, from, to string) ton.Transaction {
+	notify := *message(item, to, ownershipAssignedBody(from))
+	return ton.Transaction{
+		Account: strings.ToUpper(item),
+		InMsg:   message(from, item, transferBody(to)),
+		OutMsgs: []ton.Message{notify},
+	}
+}
+
+func TestTONIndexer(t *testing.T) {
+	api := tontest.NewAPI()
+	defer api.Close()
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	friendly, err := chain.FriendlyTON(tonCollection, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix, err := NewTONIndexer(api.Client(), store, TONOptions{Collections: []string{friendly}, Confirmations: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api.SetNFTCollection(ton.NFTCollection{Address: tonCollection, Owner: tonAlice, NextItemIndex: "2"})
+	api.SetNFTItem(ton.NFTItem{Address: tonItem0, Collection: tonCollection, Index: "0", Owner: tonBob, ContentURI: "0.json"})
+	api.AddTransaction(deployTx(tonItem0, tonAlice))
+	api.Advance(1)
+	api.AddTransaction(itemTransferTx(tonItem0, tonAlice, tonBob))
+	// 失败的转移不改变拥有者
+	aborted := itemTransferTx(tonItem0, tonBob, tonCarol)
+	aborted.Description.Aborted = true
+	aborted = api.AddTransaction(aborted)
+	// collection 部署 item 1，同时给钱包发了一条找零消息
+	api.AddTransaction(ton.Transaction{
+		Account: strings.ToUpper(tonCollection),
+		OutMsgs: []ton.Message{*message(tonCollection, tonItem1, ""), *message(tonCollection, tonAlice, "")},
+	})
+	api.AddTransaction(deployTx(tonItem1, tonCarol))
+
+	// seqno 2 的交易只有 1 个确认，这一轮只处理 item 0 的部署
+	if err = ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if owner, _ := store.Owner(chain.TON, tonCollection, "0"); owner != tonAlice {
+		t.Fatalf("item 0 owner %q, want alice", owner)
+	}
+	if uri := store.MetadataURI(chain.TON, tonCollection, "0"); uri != "0.json" {
+		t.Fatalf("item 0 metadata uri %q", uri)
+	}
+
+	// item 1 在首次载入之后才出现在 API 中，只能从 collection 的交易中发现
+	api.SetNFTItem(ton.NFTItem{Address: tonItem1, Collection: tonCollection, Index: "1", Owner: tonCarol})
+	api.Advance(1)
+	if err = ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if owner, _ := store.Owner(chain.TON, tonCollection, "0"); owner != tonBob {
+		t.Fatalf("item 0 owner %q, want bob", owner)
+	}
+	if owner, _ := store.Owner(chain.TON, tonCollection, "1"); owner != tonCarol {
+		t.Fatalf("item 1 owner %q, want carol", owner)
+	}
+	transfers := store.Transfers()
+	if len(transfers) != 3 || transfers[1].From != tonAlice || transfers[1].To != tonBob {
+		t.Fatalf("transfers %+v", transfers)
+	}
+	// 进度为 item 最后一笔交易的逻辑时间，再次同步不会重复应用
+	if cp, _ := store.Checkpoint(ctx, chain.TON, tonItem0); cp == nil || cp.Block != aborted.LT() {
+		t.Fatalf("item 0 checkpoint %+v, want lt %d", cp, aborted.LT())
+	}
+	if err = ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(store.Transfers()); n != 3 {
+		t.Fatalf("got %d transfers after resync, want 3", n)
+	}
+}
+
+func TestDecodeItemTransfer(t *testing.T) {
+	tests := []struct {
+		name     string
+		tx       ton.Transaction
+		from, to string
+		ok       bool
+	}{
+		{"deploy", deployTx(tonItem0, tonAlice), "", tonAlice, true},
+		{"transfer", itemTransferTx(tonItem0, tonAlice, tonBob), tonAlice, tonBob, true},
+		{"deploy from other account", ton.Transaction{
+			OrigStatus: "uninit", EndStatus: "active", InMsg: message(tonAlice, tonItem0, itemInitBody(tonAlice)),
+		}, "", "", false},
+		{"plain transfer of ton", ton.Transaction{InMsg: message(tonAlice, tonItem0, "")}, "", "", false},
+		{"external message", ton.Transaction{InMsg: &ton.Message{Destination: tonItem0}}, "", "", false},
+	}
+	for _, tt := range tests {
+		tx := tt.tx
+		if !tx.Description.Aborted {
+			tx.Description.ComputePh.Success = true
+		}
+		from, to, ok := ton.DecodeItemTransfer(tx, tonCollection)
+		if from != tt.from || to != tt.to || ok != tt.ok {
+			t.Errorf("%s: got %q, %q, %v", tt.name, from, to, ok)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package lazymint
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/utils/config"
+	"errors"
+	"github.com/spf13/viper"
+	"os"
+)
+
+// ErrDisabled 未开启懒铸造或未配置合约
+var ErrDisabled = errors.New("lazy mint is not enabled")
+
+// FromConfig 按 lazymint.ini 返回签名域和签名者，chainId 使用 chain.ini 中的 chain.EVM.chainId。
+// 签名私钥不放在入库的 ini 中，优先取环境变量 LAZYMINT_SIGNER_PRIVATE_KEY，其次为 config.yaml 合并
+// configs/secret.yaml 后的 lazymint.signerPrivateKey
+func FromConfig() (Domain, *Signer, error) {
+	contract := config.GetConfig("lazymint.contract").String()
+	if !config.GetConfig("lazymint.enable").MustBool(false) || contract == "" {
+		return Domain{}, nil, ErrDisabled
+	}
+	contract, err := chain.NormalizeAddress(chain.EVM, contract)
+	if err != nil {
+		return Domain{}, nil, err
+	}
+	signer, err := NewSigner(signerKey())
+	if err != nil {
+		return Domain{}, nil, err
+	}
+	return Domain{
+		Name:              config.GetConfig("lazymint.domainName").MustString("LazyNFT"),
+		Version:           config.GetConfig("lazymint.domainVersion").MustString("1"),
+		ChainID:           chain.EndpointFromConfig(chain.EVM).ChainID,
+		VerifyingContract: contract,
+	}, signer, nil
+}
+
+func signerKey() string {
+	if key := os.Getenv("LAZYMINT_SIGNER_PRIVATE_KEY"); key != "" {
+		return key
+	}
+	return viper.GetString("lazymint.signerPrivateKey")
+}
@@ -0,0 +1,196 @@
+// Package lazymint 懒铸造：创作者先创建草稿，服务端签发 EIP-712 铸造凭证，买家在链上兑换凭证时才真正铸造
+package lazymint
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/verifier"
+	"encoding/hex"
+	"errors"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"math/big"
+	"strings"
+)
+
+const (
+	// VoucherType 凭证的 EIP-712 类型，合约中的 struct 必须与之一致
+	VoucherType = "MintVoucher(uint256 tokenId,uint256 minPrice,string uri,address creator)"
+	// DomainType 签名域的 EIP-712 类型
+	DomainType = "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+	// draftIDBits tokenId 低位中草稿 id 占用的位数，高 160 位为创作者地址
+	draftIDBits = 96
+)
+
+var (
+	ErrInvalidKey     = errors.New("invalid voucher signer key")
+	ErrInvalidAddress = errors.New("invalid evm address")
+	ErrDraftID        = errors.New("draft id out of range")
+	ErrUint256        = errors.New("value out of uint256 range")
+)
+
+// Domain EIP-712 签名域
+type Domain struct {
+	Name              string `json:"name"`
+	Version           string `json:"version"`
+	ChainID           int64  `json:"chainId"`
+	VerifyingContract string `json:"verifyingContract"`
+}
+
+// Voucher 铸造凭证：买家支付不少于 MinPrice（wei）即可铸造 TokenID，铸造款归 Creator
+type Voucher struct {
+	TokenID  *big.Int
+	MinPrice *big.Int
+	URI      string
+	Creator  string
+}
+
+// TokenID 预留的 tokenId：creator << 96 | draftID，合约可以据此校验凭证属于哪个创作者
+func TokenID(creator string, draftID int64) (*big.Int, error) {
+	addr, err := addressBytes(creator)
+	if err != nil {
+		return nil, err
+	}
+	if draftID <= 0 || big.NewInt(draftID).BitLen() > draftIDBits {
+		return nil, ErrDraftID
+	}
+	id := new(big.Int).Lsh(new(big.Int).SetBytes(addr), draftIDBits)
+	return id.Or(id, big.NewInt(draftID)), nil
+}
+
+// Separator 签名域的哈希
+func (d Domain) Separator() ([]byte, error) {
+	contract, err := addressBytes(d.VerifyingContract)
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Keccak256(
+		verifier.Keccak256([]byte(DomainType)),
+		verifier.Keccak256([]byte(d.Name)),
+		verifier.Keccak256([]byte(d.Version)),
+		uint256(big.NewInt(d.ChainID)),
+		leftPad(contract),
+	), nil
+}
+
+// StructHash 凭证的 hashStruct，string 类型按 keccak256 编码
+func (v Voucher) StructHash() ([]byte, error) {
+	creator, err := addressBytes(v.Creator)
+	if err != nil {
+		return nil, err
+	}
+	if !validUint256(v.TokenID) || !validUint256(v.MinPrice) {
+		return nil, ErrUint256
+	}
+	return verifier.Keccak256(
+		verifier.Keccak256([]byte(VoucherType)),
+		uint256(v.TokenID),
+		uint256(v.MinPrice),
+		verifier.Keccak256([]byte(v.URI)),
+		leftPad(creator),
+	), nil
+}
+
+// Digest 需要签名的哈希：keccak256("\x19\x01" || domainSeparator || hashStruct(voucher))
+func Digest(d Domain, v Voucher) ([]byte, error) {
+	separator, err := d.Separator()
+	if err != nil {
+		return nil, err
+	}
+	structHash, err := v.StructHash()
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Keccak256([]byte{0x19, 0x01}, separator, structHash), nil
+}
+
+// Signer 签发凭证的服务端密钥，对应地址需要在合约中被授予铸造权限
+type Signer struct {
+	key     *secp256k1.PrivateKey
+	address string
+}
+
+// NewSigner 从 hex 编码的 32 字节私钥创建签名者
+func NewSigner(hexKey string) (*Signer, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil || len(raw) != 32 {
+		return nil, ErrInvalidKey
+	}
+	key := secp256k1.PrivKeyFromBytes(raw)
+	if key.Key.IsZero() {
+		return nil, ErrInvalidKey
+	}
+	addr := verifier.Keccak256(key.PubKey().SerializeUncompressed()[1:])[12:]
+	address, err := chain.NormalizeAddress(chain.EVM, "0x"+hex.EncodeToString(addr))
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{key: key, address: address}, nil
+}
+
+// Address 签名者地址，与库中其他 EVM 地址一样为 EIP-55 校验和格式
+func (s *Signer) Address() string {
+	return s.address
+}
+
+// Sign 签署凭证，返回 65 字节 r||s||v（v 为 27/28）的 hex，与 eth_signTypedData_v4 的格式一致
+func (s *Signer) Sign(d Domain, v Voucher) (string, error) {
+	digest, err := Digest(d, v)
+	if err != nil {
+		return "", err
+	}
+	// decred 的紧凑签名格式为 [27+v] || r || s
+	compact := ecdsa.SignCompact(s.key, digest, false)
+	sig := append(compact[1:65:65], compact[0])
+	return "0x" + hex.EncodeToString(sig), nil
+}
+
+// TypedData eth_signTypedData_v4 格式的凭证，前端可以直接用钱包或 ethers 的 verifyTypedData 校验
+func TypedData(d Domain, v Voucher) map[string]interface{} {
+	return map[string]interface{}{
+		"types": map[string]interface{}{
+			"EIP712Domain": []map[string]string{
+				{"name": "name", "type": "string"},
+				{"name": "version", "type": "string"},
+				{"name": "chainId", "type": "uint256"},
+				{"name": "verifyingContract", "type": "address"},
+			},
+			"MintVoucher": []map[string]string{
+				{"name": "tokenId", "type": "uint256"},
+				{"name": "minPrice", "type": "uint256"},
+				{"name": "uri", "type": "string"},
+				{"name": "creator", "type": "address"},
+			},
+		},
+		"primaryType": "MintVoucher",
+		"domain":      d,
+		"message": map[string]string{
+			"tokenId":  v.TokenID.String(),
+			"minPrice": v.MinPrice.String(),
+			"uri":      v.URI,
+			"creator":  v.Creator,
+		},
+	}
+}
+
+func addressBytes(addr string) ([]byte, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(addr), "0x"))
+	if err != nil || len(raw) != 20 || !strings.HasPrefix(strings.ToLower(addr), "0x") {
+		return nil, ErrInvalidAddress
+	}
+	return raw, nil
+}
+
+func validUint256(n *big.Int) bool {
+	return n != nil && n.Sign() >= 0 && n.BitLen() <= 256
+}
+
+// uint256 按 ABI 编码为 32 字节大端，调用方需要先用 validUint256 校验
+func uint256(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, 32))
+}
+
+func leftPad(b []byte) []byte {
+	out := make([]byte, 32)
+	copy(out[32-len(b):], b)
+	return out
+}
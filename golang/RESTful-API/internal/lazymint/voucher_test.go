@@ -0,0 +1,111 @@
+package lazymint
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const (
+	testCreator  = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	testContract = "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"
+	// testSignerKey web3.js 文档中的示例私钥，对应地址 0x2c7536E3605D9C16a7a3D7b1898e529396a65c23
+	testSignerKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+)
+
+func testVoucher(t *testing.T) (Domain, Voucher) {
+	t.Helper()
+	tokenID, err := TokenID(testCreator, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Domain{Name: "LazyNFT", Version: "1", ChainID: 11155111, VerifyingContract: testContract},
+		Voucher{
+			TokenID:  tokenID,
+			MinPrice: big.NewInt(1e16),
+			URI:      "ipfs://bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+			Creator:  testCreator,
+		}
+}
+
+// TestDomainSeparatorSpec EIP-712 规范中 Mail 示例的签名域
+func TestDomainSeparatorSpec(t *testing.T) {
+	d := Domain{Name: "Ether Mail", Version: "1", ChainID: 1, VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}
+	separator, err := d.Separator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(separator); got != "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" {
+		t.Fatalf("got %s", got)
+	}
+}
+
+// TestSignVector 期望值由 go-ethereum 的 apitypes.TypedDataAndHash（clef 的 eth_signTypedData_v4 实现）
+// 按 TypedData 返回的类型独立计算，签名由 crypto.Sign 使用同一私钥生成
+func TestSignVector(t *testing.T) {
+	d, v := testVoucher(t)
+	if v.TokenID.String() != "41016844021060681834146332200510513382994275193220079980408889647915006427143" {
+		t.Fatalf("token id %s", v.TokenID)
+	}
+	separator, err := d.Separator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(separator); got != "81f354710a4437454fad9909ce75146bb87c420ee7ec9c0e6668cb9af1008570" {
+		t.Fatalf("separator %s", got)
+	}
+	digest, err := Digest(d, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(digest); got != "7cd5062345f876d2c587f1bb6a2d1485cd58a3fac20d21ac901eee362e861f24" {
+		t.Fatalf("digest %s", got)
+	}
+
+	signer, err := NewSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address() != "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" {
+		t.Fatalf("signer %s", signer.Address())
+	}
+	sig, err := signer.Sign(d, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RFC 6979 确定性签名，两个实现的结果逐字节一致
+	want := "0x0a8a8504b2ad6420e51f678086f579baa28a378229da3ea01b7f2d49d68902c123be700779d8deaf3badb74a4a7b31c052826264017d0b4c73750b1acbcaf1331c"
+	if sig != want {
+		t.Fatalf("signature %s", sig)
+	}
+}
+
+func TestVoucherRejects(t *testing.T) {
+	if _, err := TokenID(testCreator, 0); !errors.Is(err, ErrDraftID) {
+		t.Errorf("draft 0: got %v", err)
+	}
+	if _, err := TokenID(testCreator, 1<<62); err != nil {
+		t.Errorf("large draft id: %v", err)
+	}
+	if _, err := TokenID("5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", 1); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("address without 0x: got %v", err)
+	}
+
+	d, v := testVoucher(t)
+	v.MinPrice = big.NewInt(-1)
+	if _, err := Digest(d, v); !errors.Is(err, ErrUint256) {
+		t.Errorf("negative price: got %v", err)
+	}
+	d.VerifyingContract = ""
+	if _, err := Digest(d, v); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("empty contract: got %v", err)
+	}
+
+	for _, key := range []string{"", "0x1234", strings.Repeat("00", 32), strings.Repeat("zz", 32)} {
+		if _, err := NewSigner(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("key %q: got %v", key, err)
+		}
+	}
+}
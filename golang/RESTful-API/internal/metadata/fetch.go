@@ -0,0 +1,210 @@
+package metadata
+
+import (
+	"RESTful-API/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRedirects 跟随重定向的最大次数
+const maxRedirects = 3
+
+var (
+	ErrUnsupportedURI = errors.New("unsupported metadata uri")
+	ErrBlockedAddress = errors.New("metadata host resolves to a blocked address")
+	ErrTooLarge       = errors.New("metadata too large")
+)
+
+// blockedNetworks 除 net.IP 自带判断之外需要拒绝的保留网段
+var blockedNetworks = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",     // 本网络
+		"100.64.0.0/10", // 运营商级 NAT
+		"192.0.0.0/24",  // IETF 协议分配
+		"198.18.0.0/15", // 性能测试
+		"240.0.0.0/4",   // 保留
+		"64:ff9b::/96",  // NAT64，可以映射到内网 IPv4
+	} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// FetcherOptions 抓取配置
+type FetcherOptions struct {
+	IPFSGateways   []string // IPFS 网关，例如 https://ipfs.io/ipfs/，按顺序尝试
+	ArweaveGateway string   // Arweave 网关，例如 https://arweave.net/
+	Timeout        time.Duration
+	MaxSize        int64 // 元数据的最大字节数
+	// AllowPrivate 允许访问内网、本机地址和任意端口，只用于本地 IPFS 节点或测试
+	AllowPrivate bool
+}
+
+// Fetcher 解析元数据 URI 并抓取内容。
+// 连接建立时检查实际解析到的 IP，内网、本机、保留地址一律拒绝，重定向和 DNS 重绑定同样受到限制
+type Fetcher struct {
+	opts   FetcherOptions
+	client *http.Client
+}
+
+// NewFetcher 创建 Fetcher，不使用环境变量中的代理，否则无法检查目标地址
+func NewFetcher(opts FetcherOptions) *Fetcher {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 1 << 20
+	}
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivate {
+		dialer.Control = checkDial
+	}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	return &Fetcher{
+		opts: opts,
+		client: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: tracing.NewTransport(transport, "metadata"),
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				if req.URL.Scheme != "https" && req.URL.Scheme != "http" {
+					return fmt.Errorf("%w: redirect to %s", ErrUnsupportedURI, req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Resolve 把元数据 URI 转为可以请求的 URL：
+//   - ipfs://<cid>/<path>（也接受 ipfs://ipfs/<cid>）依次使用各个 IPFS 网关
+//   - ar://<txid> 使用 Arweave 网关
+//   - https://、http:// 直接访问
+func (f *Fetcher) Resolve(uri string) ([]string, error) {
+	uri = strings.TrimSpace(uri)
+	lower := strings.ToLower(uri)
+	switch {
+	case strings.HasPrefix(lower, "ipfs://"):
+		path := strings.TrimPrefix(uri[len("ipfs://"):], "ipfs/")
+		if path == "" || len(f.opts.IPFSGateways) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedURI, uri)
+		}
+		urls := make([]string, 0, len(f.opts.IPFSGateways))
+		for _, gateway := range f.opts.IPFSGateways {
+			urls = append(urls, strings.TrimRight(gateway, "/")+"/"+path)
+		}
+		return urls, nil
+
+	case strings.HasPrefix(lower, "ar://"):
+		id := uri[len("ar://"):]
+		if id == "" || f.opts.ArweaveGateway == "" {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedURI, uri)
+		}
+		return []string{strings.TrimRight(f.opts.ArweaveGateway, "/") + "/" + id}, nil
+
+	case strings.HasPrefix(lower, "https://"), strings.HasPrefix(lower, "http://"):
+		u, err := url.Parse(uri)
+		if err != nil || u.Host == "" || u.User != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedURI, uri)
+		}
+		return []string{u.String()}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedURI, uri)
+}
+
+// Fetch 抓取并校验元数据，网关返回错误时尝试下一个网关；内容不合法时直接返回，不再重试
+func (f *Fetcher) Fetch(ctx context.Context, uri string) (*Document, error) {
+	urls, err := f.Resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, u := range urls {
+		data, err := f.get(ctx, u)
+		if err != nil {
+			lastErr = err
+			if errors.Is(err, ErrTooLarge) || ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		return Parse(data)
+	}
+	return nil, lastErr
+}
+
+// get 请求 URL，响应超过 MaxSize 时返回 ErrTooLarge
+func (f *Fetcher) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d", u, resp.StatusCode)
+	}
+	if resp.ContentLength > f.opts.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > f.opts.MaxSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, f.opts.MaxSize)
+	}
+	return data, nil
+}
+
+// checkDial 在建立连接前检查目标地址，此时域名已经解析为 IP，只允许 80 和 443 端口
+func checkDial(network, address string, _ syscall.RawConn) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if port != "80" && port != "443" {
+		return fmt.Errorf("%w: port %s", ErrBlockedAddress, port)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || blockedIP(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+func blockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range blockedNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
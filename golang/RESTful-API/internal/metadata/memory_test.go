@@ -0,0 +1,75 @@
+package metadata
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore 内存中的 Store，供测试使用
+type MemoryStore struct {
+	mu      sync.Mutex
+	uris    map[int64]string
+	results map[int64]Result
+}
+
+// NewMemoryStore 创建空的内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{uris: make(map[int64]string), results: make(map[int64]Result)}
+}
+
+// SetNFT 设置 NFT 的 metadata_uri，为空表示删除
+func (s *MemoryStore) SetNFT(nftID int64, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if uri == "" {
+		delete(s.uris, nftID)
+		return
+	}
+	s.uris[nftID] = uri
+}
+
+// Result 返回 NFT 最近一次保存的抓取结果，抓取失败时 Document 为之前成功的内容
+func (s *MemoryStore) Result(nftID int64) (Result, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.results[nftID]
+	return r, ok
+}
+
+func (s *MemoryStore) Entries(_ context.Context, after int64, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(s.uris))
+	for id := range s.uris {
+		if id > after {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		e := Entry{NftID: id, URI: s.uris[id]}
+		if r, ok := s.results[id]; ok {
+			e.FetchedURI, e.FetchError, e.FetchedAt = r.URI, r.Err, r.FetchedAt
+			if r.Document != nil {
+				e.ContentHash = r.Document.Hash
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, r Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Document == nil {
+		r.Document = s.results[r.NftID].Document
+	}
+	s.results[r.NftID] = r
+	return nil
+}
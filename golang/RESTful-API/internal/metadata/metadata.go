@@ -0,0 +1,174 @@
+// Package metadata 解析 nfts.metadata_uri，抓取并校验 NFT 元数据 JSON，保存规范化后的副本并定时刷新
+package metadata
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 元数据字段的长度限制
+const (
+	maxNameLength        = 255
+	maxDescriptionLength = 10000
+	maxURILength         = 2048
+	maxAttributes        = 200
+)
+
+// ErrInvalidMetadata 内容不是合法的 NFT 元数据
+var ErrInvalidMetadata = errors.New("invalid nft metadata")
+
+// Attribute 元数据中的属性，Value 为字符串、数字（json.Number）或布尔值
+type Attribute struct {
+	TraitType   string      `json:"trait_type,omitempty"`
+	Value       interface{} `json:"value"`
+	DisplayType string      `json:"display_type,omitempty"`
+}
+
+// Metadata 规范化后的 NFT 元数据，只保留 ERC-721 / Metaplex / TEP-64 通用的字段
+type Metadata struct {
+	Name         string      `json:"name"`
+	Description  string      `json:"description,omitempty"`
+	Image        string      `json:"image,omitempty"`
+	AnimationURL string      `json:"animation_url,omitempty"`
+	ExternalURL  string      `json:"external_url,omitempty"`
+	Attributes   []Attribute `json:"attributes,omitempty"`
+}
+
+// Document 一次抓取的结果
+type Document struct {
+	Metadata *Metadata
+	Content  []byte // 规范化后的 JSON
+	Hash     string // Content 的 sha256（hex）
+}
+
+// Parse 校验元数据 JSON 并规范化：
+//   - 必须是 JSON 对象，name 为非空字符串
+//   - description、image、animation_url、external_url 为字符串，image 缺失时使用 image_url
+//   - 链接只能是 ipfs://、ar://、http(s):// 或 data: 图片
+//   - attributes 为对象数组，value 只能是字符串、数字或布尔值
+func Parse(data []byte) (*Document, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]json.RawMessage
+	if err := dec.Decode(&raw); err != nil || raw == nil {
+		return nil, fmt.Errorf("%w: not a json object", ErrInvalidMetadata)
+	}
+
+	m := &Metadata{}
+	fields := []struct {
+		key      string
+		dst      *string
+		max      int
+		required bool
+		link     bool
+	}{
+		{"name", &m.Name, maxNameLength, true, false},
+		{"description", &m.Description, maxDescriptionLength, false, false},
+		{"image", &m.Image, maxURILength, false, true},
+		{"animation_url", &m.AnimationURL, maxURILength, false, true},
+		{"external_url", &m.ExternalURL, maxURILength, false, true},
+	}
+	for _, f := range fields {
+		s, err := stringField(raw, f.key)
+		if err != nil {
+			return nil, err
+		}
+		if f.key == "image" && s == "" {
+			if s, err = stringField(raw, "image_url"); err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case f.required && s == "":
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidMetadata, f.key)
+		case utf8.RuneCountInString(s) > f.max:
+			return nil, fmt.Errorf("%w: %s too long", ErrInvalidMetadata, f.key)
+		case f.link && s != "" && !validLink(s):
+			return nil, fmt.Errorf("%w: %s has unsupported scheme", ErrInvalidMetadata, f.key)
+		}
+		*f.dst = s
+	}
+
+	attributes, err := parseAttributes(raw["attributes"])
+	if err != nil {
+		return nil, err
+	}
+	m.Attributes = attributes
+
+	content, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	return &Document{Metadata: m, Content: content, Hash: hex.EncodeToString(sum[:])}, nil
+}
+
+// stringField 读取字符串字段，不存在或为 null 时返回空字符串
+func stringField(raw map[string]json.RawMessage, key string) (string, error) {
+	v, ok := raw[key]
+	if !ok || string(v) == "null" {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return "", fmt.Errorf("%w: %s must be a string", ErrInvalidMetadata, key)
+	}
+	return strings.TrimSpace(s), nil
+}
+
+func parseAttributes(v json.RawMessage) ([]Attribute, error) {
+	if len(v) == 0 || string(v) == "null" {
+		return nil, nil
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(v, &items); err != nil {
+		return nil, fmt.Errorf("%w: attributes must be an array of objects", ErrInvalidMetadata)
+	}
+	if len(items) > maxAttributes {
+		return nil, fmt.Errorf("%w: too many attributes", ErrInvalidMetadata)
+	}
+	attributes := make([]Attribute, 0, len(items))
+	for _, item := range items {
+		var (
+			a   Attribute
+			err error
+		)
+		if a.TraitType, err = stringField(item, "trait_type"); err != nil {
+			return nil, err
+		}
+		if a.DisplayType, err = stringField(item, "display_type"); err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(item["value"]))
+		dec.UseNumber()
+		if err = dec.Decode(&a.Value); err != nil {
+			return nil, fmt.Errorf("%w: attribute value is required", ErrInvalidMetadata)
+		}
+		switch value := a.Value.(type) {
+		case string:
+			a.Value = strings.TrimSpace(value)
+		case json.Number, bool:
+		default:
+			return nil, fmt.Errorf("%w: attribute value must be a string, number or bool", ErrInvalidMetadata)
+		}
+		attributes = append(attributes, a)
+	}
+	return attributes, nil
+}
+
+// validLink 元数据中的链接只允许可以解析的协议，拒绝 javascript: 等
+func validLink(s string) bool {
+	lower := strings.ToLower(s)
+	for _, prefix := range []string{"ipfs://", "ar://", "https://", "http://", "data:image/"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"minimal", `{"name":"A"}`, nil},
+		{"image_url fallback", `{"name":"A","image_url":"ipfs://cid/1.png"}`, nil},
+		{"null fields", `{"name":"A","description":null,"attributes":null}`, nil},
+		{"not an object", `["name"]`, ErrInvalidMetadata},
+		{"null", `null`, ErrInvalidMetadata},
+		{"missing name", `{"description":"d"}`, ErrInvalidMetadata},
+		{"blank name", `{"name":"  "}`, ErrInvalidMetadata},
+		{"name not a string", `{"name":1}`, ErrInvalidMetadata},
+		{"name too long", `{"name":"` + strings.Repeat("名", maxNameLength+1) + `"}`, ErrInvalidMetadata},
+		{"javascript image", `{"name":"A","image":"javascript:alert(1)"}`, ErrInvalidMetadata},
+		{"data url that is not an image", `{"name":"A","image":"data:text/html,<b>"}`, ErrInvalidMetadata},
+		{"attributes not an array", `{"name":"A","attributes":{}}`, ErrInvalidMetadata},
+		{"attribute without value", `{"name":"A","attributes":[{"trait_type":"t"}]}`, ErrInvalidMetadata},
+		{"nested attribute value", `{"name":"A","attributes":[{"value":{"a":1}}]}`, ErrInvalidMetadata},
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.data)); !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestParseNormalizes(t *testing.T) {
+	doc, err := Parse([]byte(`{
+		"attributes": [
+			{"trait_type": " Eyes ", "value": " Blue "},
+			{"trait_type": "Level", "value": 12345678901234567890, "display_type": "number"},
+			{"value": true}
+		],
+		"image_url": "ar://tx",
+		"name": " Token #1 ",
+		"unknown": {"ignored": true}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := doc.Metadata
+	if m.Name != "Token #1" || m.Image != "ar://tx" || len(m.Attributes) != 3 {
+		t.Fatalf("unexpected metadata %+v", m)
+	}
+	// 大整数保留原样，不经过 float64
+	if v, ok := m.Attributes[1].Value.(json.Number); !ok || v.String() != "12345678901234567890" {
+		t.Fatalf("attribute value %#v", m.Attributes[1].Value)
+	}
+	want := `{"name":"Token #1","image":"ar://tx","attributes":[{"trait_type":"Eyes","value":"Blue"},` +
+		`{"trait_type":"Level","value":12345678901234567890,"display_type":"number"},{"value":true}]}`
+	if string(doc.Content) != want {
+		t.Fatalf("content %s", doc.Content)
+	}
+
+	// 字段顺序、空白和未知字段不影响内容哈希
+	again, err := Parse([]byte(`{"name":"Token #1","image":"ar://tx","attributes":[{"trait_type":"Eyes","value":"Blue"},` +
+		`{"display_type":"number","trait_type":"Level","value":12345678901234567890},{"value":true}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Hash != doc.Hash || len(doc.Hash) != 64 {
+		t.Fatalf("hash %s != %s", again.Hash, doc.Hash)
+	}
+}
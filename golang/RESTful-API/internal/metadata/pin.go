@@ -0,0 +1,133 @@
+package metadata
+
+import (
+	"RESTful-API/internal/storage"
+	"RESTful-API/internal/tracing"
+	"RESTful-API/internal/upload"
+	"RESTful-API/utils/config"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Pinner 把内容固定到不可变的地址，返回可以写入 tokenURI 的 URI
+type Pinner interface {
+	Pin(ctx context.Context, contentType string, data []byte) (string, error)
+}
+
+// PinnerFromConfig 按 metadata.ini 中的 metadata.pinner 创建 Pinner：ipfs 使用 IPFS 节点，storage 使用上传文件的存储
+func PinnerFromConfig() (Pinner, error) {
+	switch kind := config.GetConfig("metadata.pinner").MustString("storage"); kind {
+	case "ipfs":
+		return NewIPFSPinner(
+			config.GetConfig("metadata.ipfsAPI").String(),
+			time.Duration(config.GetConfig("metadata.timeout").MustInt(10000))*time.Millisecond,
+		)
+	case "storage":
+		s, err := storage.Default()
+		if err != nil {
+			return nil, err
+		}
+		return NewStoragePinner(s, config.GetConfig("metadata.pinBaseURL").String()), nil
+	default:
+		return nil, fmt.Errorf("unknown metadata pinner %q", kind)
+	}
+}
+
+// IPFSPinner 通过 IPFS 节点（kubo）的 /api/v0/add 添加并固定内容，返回 ipfs://<cid>
+type IPFSPinner struct {
+	api    string
+	client *http.Client
+}
+
+// NewIPFSPinner 创建 IPFSPinner，api 为节点的 RPC 地址，例如 http://127.0.0.1:5001
+func NewIPFSPinner(api string, timeout time.Duration) (*IPFSPinner, error) {
+	if api == "" {
+		return nil, errors.New("metadata.ipfsAPI is empty")
+	}
+	return &IPFSPinner{
+		api:    strings.TrimRight(api, "/"),
+		client: &http.Client{Timeout: timeout, Transport: tracing.NewTransport(http.DefaultTransport, "ipfs")},
+	}, nil
+}
+
+func (p *IPFSPinner) Pin(ctx context.Context, _ string, data []byte) (string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "file")
+	if err != nil {
+		return "", err
+	}
+	if _, err = part.Write(data); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.api+"/api/v0/add?pin=true&cid-version=1", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return "", fmt.Errorf("ipfs add: status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	var result struct {
+		Hash string `json:"Hash"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.Hash == "" {
+		return "", errors.New("ipfs add: empty cid")
+	}
+	return "ipfs://" + result.Hash, nil
+}
+
+// StoragePinner 按内容哈希把文件写入对象存储，内容不变则地址不变；
+// 不是真正的 IPFS 固定，地址的可用性取决于存储本身
+type StoragePinner struct {
+	store   storage.Storage
+	baseURL string
+}
+
+// NewStoragePinner 创建 StoragePinner，存储返回相对地址（例如本地存储的 /uploads）时拼接 baseURL
+func NewStoragePinner(store storage.Storage, baseURL string) *StoragePinner {
+	return &StoragePinner{store: store, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (p *StoragePinner) Pin(ctx context.Context, contentType string, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	key := "pinned/" + hex.EncodeToString(sum[:])
+	if contentType == "application/json" {
+		key += ".json"
+	} else {
+		key += upload.Ext(contentType)
+	}
+	if err := p.store.Put(ctx, key, contentType, data); err != nil {
+		return "", err
+	}
+	uri := p.store.URL(key)
+	if strings.HasPrefix(uri, "/") {
+		if p.baseURL == "" {
+			return "", fmt.Errorf("storage url %s is relative, metadata.pinBaseURL is required", uri)
+		}
+		uri = p.baseURL + uri
+	}
+	return uri, nil
+}
@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"RESTful-API/internal/storage"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIPFSPinner(t *testing.T) {
+	var pinned []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/add" || r.URL.Query().Get("pin") != "true" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		pinned, _ = io.ReadAll(file)
+		_, _ = w.Write([]byte(`{"Name":"file","Hash":"bafkreicid","Size":"13"}`))
+	}))
+	defer server.Close()
+
+	p, err := NewIPFSPinner(server.URL+"/", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, err := p.Pin(context.Background(), "application/json", []byte(`{"name":"A"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri != "ipfs://bafkreicid" || string(pinned) != `{"name":"A"}` {
+		t.Fatalf("got %s, pinned %s", uri, pinned)
+	}
+
+	// 节点返回错误时带上响应内容
+	p, _ = NewIPFSPinner(server.URL+"/broken", 0)
+	if _, err = p.Pin(context.Background(), "application/json", nil); err == nil || !strings.Contains(err.Error(), "unexpected request") {
+		t.Fatalf("got %v", err)
+	}
+	if _, err = NewIPFSPinner("", 0); err == nil {
+		t.Fatal("empty api should fail")
+	}
+}
+
+func TestStoragePinner(t *testing.T) {
+	dir := t.TempDir()
+	local, err := storage.NewLocal(storage.LocalOptions{Dir: dir, PublicURL: "/uploads"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	data := []byte(`{"name":"A"}`)
+
+	// 本地存储返回相对地址，需要 baseURL
+	if _, err = NewStoragePinner(local, "").Pin(ctx, "application/json", data); err == nil {
+		t.Fatal("relative url without base url should fail")
+	}
+	p := NewStoragePinner(local, "https://api.example.com/")
+	uri, err := p.Pin(ctx, "application/json", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	key := "pinned/" + hex.EncodeToString(sum[:]) + ".json"
+	if uri != "https://api.example.com/uploads/"+key {
+		t.Fatalf("uri %s", uri)
+	}
+	if saved, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(key))); err != nil || string(saved) != string(data) {
+		t.Fatalf("saved %s, %v", saved, err)
+	}
+
+	// 内容相同则地址相同
+	if again, _ := p.Pin(ctx, "application/json", data); again != uri {
+		t.Fatalf("got %s, want %s", again, uri)
+	}
+}
@@ -0,0 +1,122 @@
+package metadata
+
+import (
+	"RESTful-API/utils/logs"
+	"context"
+	"time"
+)
+
+// maxErrorLength fetch_error 列的长度
+const maxErrorLength = 500
+
+// RefresherOptions 定时刷新配置
+type RefresherOptions struct {
+	Interval   time.Duration // 两轮扫描之间的间隔
+	MaxAge     time.Duration // 成功抓取的元数据超过该时间后重新抓取
+	RetryAfter time.Duration // 抓取失败后的重试间隔
+	BatchSize  int           // 每次从存储中读取的 NFT 数量
+}
+
+// Refresher 定时扫描全部 NFT，抓取从未抓取、metadata_uri 已变化或已过期的元数据
+type Refresher struct {
+	fetcher *Fetcher
+	store   Store
+	opts    RefresherOptions
+	log     *logs.Logger
+	now     func() time.Time
+}
+
+// NewRefresher 创建定时刷新任务
+func NewRefresher(fetcher *Fetcher, store Store, opts RefresherOptions) *Refresher {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Hour
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 24 * time.Hour
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = 10 * time.Minute
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	return &Refresher{
+		fetcher: fetcher,
+		store:   store,
+		opts:    opts,
+		log:     logs.With(logs.String("task", "metadata")),
+		now:     time.Now,
+	}
+}
+
+// Run 持续刷新直到 ctx 结束，单轮出错时记录日志并在下一轮重试
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if fetched, err := r.RefreshAll(ctx); err != nil && ctx.Err() == nil {
+			r.log.With(logs.Err(err)).Error("refresh failed")
+		} else if fetched > 0 {
+			r.log.With(logs.Int("fetched", fetched)).Info("refreshed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshAll 扫描一轮，返回抓取的数量；单个 NFT 抓取失败只记录到存储中，不中断扫描
+func (r *Refresher) RefreshAll(ctx context.Context) (int, error) {
+	fetched := 0
+	for after := int64(0); ; {
+		entries, err := r.store.Entries(ctx, after, r.opts.BatchSize)
+		if err != nil {
+			return fetched, err
+		}
+		for _, e := range entries {
+			after = e.NftID
+			if !r.due(e) {
+				continue
+			}
+			if err = r.Refresh(ctx, e.NftID, e.URI); err != nil {
+				return fetched, err
+			}
+			fetched++
+		}
+		if len(entries) < r.opts.BatchSize {
+			return fetched, nil
+		}
+	}
+}
+
+// due 从未抓取、URI 变化、成功后超过 MaxAge 或失败后超过 RetryAfter 时需要抓取
+func (r *Refresher) due(e Entry) bool {
+	if e.FetchedAt.IsZero() || e.FetchedURI != e.URI {
+		return true
+	}
+	age := r.now().Sub(e.FetchedAt)
+	if e.FetchError != "" {
+		return age >= r.opts.RetryAfter
+	}
+	return age >= r.opts.MaxAge
+}
+
+// Refresh 立即抓取一个 NFT 的元数据并保存，只有保存失败时返回错误
+func (r *Refresher) Refresh(ctx context.Context, nftID int64, uri string) error {
+	result := Result{NftID: nftID, URI: uri, FetchedAt: r.now()}
+	doc, err := r.fetcher.Fetch(ctx, uri)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		result.Err = err.Error()
+		if len(result.Err) > maxErrorLength {
+			result.Err = result.Err[:maxErrorLength]
+		}
+		r.log.With(logs.Int64("nft", nftID), logs.String("uri", uri), logs.Err(err)).Warn("fetch failed")
+	}
+	result.Document = doc
+	return r.store.Save(ctx, result)
+}
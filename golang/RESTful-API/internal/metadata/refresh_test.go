@@ -0,0 +1,92 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRefresher(t *testing.T) {
+	g, server, f := newGateway(t)
+	g.set("/ipfs/cid/1.json", `{"name":"One"}`)
+	g.set("/ipfs/cid/2.json", `{"name":"Two"}`)
+	store := NewMemoryStore()
+	store.SetNFT(1, "ipfs://cid/1.json")
+	store.SetNFT(2, "ipfs://cid/2.json")
+	store.SetNFT(3, server.URL+"/missing.json")
+	store.SetNFT(4, "")
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewRefresher(f, store, RefresherOptions{MaxAge: time.Hour, RetryAfter: 10 * time.Minute, BatchSize: 2})
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+	refresh := func(want int) {
+		t.Helper()
+		fetched, err := r.RefreshAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fetched != want {
+			t.Fatalf("fetched %d, want %d", fetched, want)
+		}
+	}
+
+	// 分批读取，抓取失败不中断扫描
+	refresh(3)
+	one, _ := store.Result(1)
+	if one.Document == nil || one.Document.Metadata.Name != "One" || one.Err != "" || !one.FetchedAt.Equal(now) {
+		t.Fatalf("nft 1 %+v", one)
+	}
+	if three, _ := store.Result(3); three.Document != nil || !strings.Contains(three.Err, "status 404") {
+		t.Fatalf("nft 3 %+v", three)
+	}
+	refresh(0)
+
+	// 失败的重试间隔短于成功的过期时间
+	now = now.Add(10 * time.Minute)
+	refresh(1)
+
+	// metadata_uri 变化后立即重新抓取
+	store.SetNFT(2, "ipfs://cid/1.json")
+	refresh(1)
+	if two, _ := store.Result(2); two.URI != "ipfs://cid/1.json" || two.Document.Hash != one.Document.Hash {
+		t.Fatalf("nft 2 %+v", two)
+	}
+
+	// 过期后重新抓取，失败时保留之前成功的内容
+	g.set("/ipfs/cid/1.json", `{"description":"no name"}`)
+	now = now.Add(time.Hour)
+	refresh(3)
+	one, _ = store.Result(1)
+	if one.Document == nil || one.Document.Metadata.Name != "One" || !strings.Contains(one.Err, "name is required") {
+		t.Fatalf("nft 1 after failed refresh %+v", one)
+	}
+}
+
+func TestRefreshTruncatesError(t *testing.T) {
+	store := NewMemoryStore()
+	r := NewRefresher(NewFetcher(FetcherOptions{}), store, RefresherOptions{})
+	uri := "ftp://example.com/" + strings.Repeat("x", 2*maxErrorLength)
+	if err := r.Refresh(context.Background(), 1, uri); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := store.Result(1); len(result.Err) != maxErrorLength {
+		t.Fatalf("error length %d, want %d", len(result.Err), maxErrorLength)
+	}
+}
+
+func TestRefreshCanceled(t *testing.T) {
+	_, server, f := newGateway(t)
+	store := NewMemoryStore()
+	r := NewRefresher(f, store, RefresherOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// ctx 结束导致的失败不保存，下一轮再抓取
+	if err := r.Refresh(ctx, 1, server.URL+"/1.json"); err != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if _, ok := store.Result(1); ok {
+		t.Fatal("canceled fetch should not be saved")
+	}
+}
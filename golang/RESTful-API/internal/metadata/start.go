@@ -0,0 +1,30 @@
+package metadata
+
+import (
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/logs"
+	"context"
+	"time"
+)
+
+// Start 按 metadata.ini 在后台启动元数据定时刷新，未开启时不启动
+func Start() {
+	if !config.GetConfig("metadata.enable").MustBool(false) {
+		return
+	}
+	fetcher := NewFetcher(FetcherOptions{
+		IPFSGateways:   config.GetConfig("metadata.ipfsGateways").Strings(","),
+		ArweaveGateway: config.GetConfig("metadata.arweaveGateway").String(),
+		Timeout:        time.Duration(config.GetConfig("metadata.timeout").MustInt(10000)) * time.Millisecond,
+		MaxSize:        config.GetConfig("metadata.maxSize").MustInt64(1 << 20),
+		AllowPrivate:   config.GetConfig("metadata.allowPrivate").MustBool(false),
+	})
+	r := NewRefresher(fetcher, NewDBStore(), RefresherOptions{
+		Interval:   time.Duration(config.GetConfig("metadata.interval").MustInt(600000)) * time.Millisecond,
+		MaxAge:     time.Duration(config.GetConfig("metadata.maxAge").MustInt(86400000)) * time.Millisecond,
+		RetryAfter: time.Duration(config.GetConfig("metadata.retryAfter").MustInt(600000)) * time.Millisecond,
+		BatchSize:  config.GetConfig("metadata.batchSize").MustInt(100),
+	})
+	logs.Info("metadata refresher started")
+	go r.Run(context.Background())
+}
@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"RESTful-API/internal/model"
+	"context"
+	"time"
+)
+
+// Entry 一个需要元数据的 NFT 及其当前保存的元数据状态
+type Entry struct {
+	NftID       int64
+	URI         string    // nfts.metadata_uri
+	FetchedURI  string    // 上次抓取时的 URI，从未抓取时为空
+	ContentHash string    // 上次成功抓取的内容哈希
+	FetchError  string    // 上次抓取失败的原因
+	FetchedAt   time.Time // 上次抓取的时间
+}
+
+// Result 一次抓取的结果，Err 不为空时保留之前成功抓取的内容
+type Result struct {
+	NftID     int64
+	URI       string
+	Document  *Document
+	Err       string
+	FetchedAt time.Time
+}
+
+// Store 元数据的持久化存储
+type Store interface {
+	// Entries 按 nft id 升序返回 id 大于 after、metadata_uri 不为空的 NFT，最多 limit 个
+	Entries(ctx context.Context, after int64, limit int) ([]Entry, error)
+	// Save 保存抓取结果
+	Save(ctx context.Context, r Result) error
+}
+
+type dbStore struct{}
+
+// NewDBStore 基于 nfts、nft_metadata 表的存储
+func NewDBStore() Store {
+	return &dbStore{}
+}
+
+func (s *dbStore) Entries(ctx context.Context, after int64, limit int) ([]Entry, error) {
+	var nfts []*model.NftsModel
+	if err := model.NewNftsModel().WithContext(ctx).List(map[string]interface{}{
+		"id > ?":            after,
+		"metadata_uri != ?": "",
+	}, 1, limit, &nfts, "id asc"); err != nil {
+		return nil, err
+	}
+	if len(nfts) == 0 {
+		return nil, nil
+	}
+	ids := make([]int64, 0, len(nfts))
+	for _, n := range nfts {
+		ids = append(ids, n.ID)
+	}
+	var rows []*model.NftMetadataModel
+	if err := model.NewNftMetadataModel().WithContext(ctx).ListNoPage(map[string]interface{}{"nft_id IN ?": ids}, &rows); err != nil {
+		return nil, err
+	}
+	saved := make(map[int64]*model.NftMetadataModel, len(rows))
+	for _, row := range rows {
+		saved[row.NftID] = row
+	}
+
+	entries := make([]Entry, 0, len(nfts))
+	for _, n := range nfts {
+		e := Entry{NftID: n.ID, URI: n.MetadataURI}
+		if row := saved[n.ID]; row != nil {
+			e.FetchedURI, e.ContentHash, e.FetchError, e.FetchedAt = row.MetadataURI, row.ContentHash, row.FetchError, row.FetchedAt
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (s *dbStore) Save(ctx context.Context, r Result) error {
+	row := &model.NftMetadataModel{NftID: r.NftID, MetadataURI: r.URI, FetchError: r.Err, FetchedAt: r.FetchedAt}
+	data := map[string]interface{}{
+		"metadata_uri": row.MetadataURI,
+		"fetch_error":  row.FetchError,
+		"fetched_at":   row.FetchedAt,
+	}
+	// 抓取失败时只更新错误信息，保留之前的内容
+	if doc := r.Document; doc != nil {
+		row.ContentHash, row.Content = doc.Hash, string(doc.Content)
+		row.Name, row.Description, row.Image = doc.Metadata.Name, doc.Metadata.Description, doc.Metadata.Image
+		data["content_hash"], data["content"] = row.ContentHash, row.Content
+		data["name"], data["description"], data["image"] = row.Name, row.Description, row.Image
+	}
+
+	existing := &model.NftMetadataModel{}
+	if err := model.NewNftMetadataModel().WithContext(ctx).QueryOne(map[string]interface{}{"nft_id = ?": r.NftID}, existing); err != nil {
+		return err
+	}
+	if existing.ID == 0 {
+		err := model.NewNftMetadataModel().WithContext(ctx).Create(row)
+		if !model.IsUniqueErr(err) {
+			return err
+		}
+		// 并发写入时已经有了记录，改为更新
+	}
+	_, err := model.NewNftMetadataModel().WithContext(ctx).Update(data, map[string]interface{}{"nft_id = ?": r.NftID})
+	return err
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const gormStartKey = "metrics:start_time"
+
+// GormPlugin 记录每次 gorm 操作的耗时
+type GormPlugin struct {
+	alias string
+}
+
+// NewGormPlugin 创建插件，alias 为数据库别名
+func NewGormPlugin(alias string) *GormPlugin {
+	return &GormPlugin{alias: alias}
+}
+
+func (p *GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize 在 gorm 各类操作的前后注册回调
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(gormStartKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(gormStartKey)
+			if !ok {
+				return
+			}
+			if start, ok := v.(time.Time); ok {
+				dbDuration.WithLabelValues(p.alias, operation).Observe(time.Since(start).Seconds())
+			}
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", after("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", after("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", after("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:after_row", after("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw"))
+}
@@ -0,0 +1,164 @@
+// Package metrics Prometheus 监控指标，通过 /metrics 暴露
+//
+// 指标列表（前缀 marketdao_）：
+//
+//	http_requests_total{method,route,status}                  HTTP 请求数
+//	http_request_duration_seconds{method,route,status}        HTTP 请求耗时
+//	db_query_duration_seconds{alias,operation}                gorm 执行耗时，operation 为 create/query/update/delete/row/raw
+//	logins_total{method,chain}                                登录成功次数，method 为 password/wallet，密码登录的 chain 为 none
+//	nft_listings_total{chain}                                 NFT 上架次数
+//	nft_drafts_total{chain}                                   懒铸造草稿创建次数
+//	nft_sales_total{chain}                                    NFT 成交笔数
+//	nft_sales_volume_total{chain}                             NFT 成交金额，按链原生币计
+//	swaps_total{chain}                                        swap 笔数
+//
+// 另外每个数据库别名都会输出 go_sql_* 连接池指标（sql.DBStats），标签 db_name 为别名。
+//
+// 为控制基数，标签中不能出现钱包地址、合约地址、交易哈希等原始值：
+// route 使用 gin 的路由模板（例如 /api/v1/user/wallets/:chain），未匹配的路由统一记为 unmatched；
+// chain 只接受 EVM/TON/Solana，其余记为 other。
+package metrics
+
+import (
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/money"
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const namespace = "marketdao"
+
+const (
+	LoginPassword = "password" // 用户名或邮箱加密码登录
+	LoginWallet   = "wallet"   // 钱包签名登录
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of gorm operations by database alias.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"alias", "operation"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Successful logins by method and chain.",
+	}, []string{"method", "chain"})
+
+	nftListings = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nft_listings_total",
+		Help:      "NFT listings by chain.",
+	}, []string{"chain"})
+
+	nftDrafts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nft_drafts_total",
+		Help:      "Lazy-mint NFT drafts created by chain.",
+	}, []string{"chain"})
+
+	nftSales = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nft_sales_total",
+		Help:      "NFT sales by chain.",
+	}, []string{"chain"})
+
+	nftSalesVolume = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nft_sales_volume_total",
+		Help:      "NFT sales volume by chain, in the chain's native currency.",
+	}, []string{"chain"})
+
+	swaps = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swaps_total",
+		Help:      "Swap transactions by chain.",
+	}, []string{"chain"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, dbDuration,
+		logins, nftListings, nftDrafts, nftSales, nftSalesVolume, swaps,
+	)
+}
+
+// Handler /metrics 接口
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// RegisterDB 输出数据库连接池指标，alias 为 db.alias 中的别名
+func RegisterDB(alias string, db *sql.DB) error {
+	return registry.Register(collectors.NewDBStatsCollector(db, alias))
+}
+
+// ObserveHTTP 记录一次 HTTP 请求，route 必须是路由模板而不是实际路径
+func ObserveHTTP(method, route, status string, seconds float64) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(seconds)
+}
+
+// Login 记录一次登录成功，密码登录 chain 传空
+func Login(method, chain string) {
+	if chain == "" {
+		logins.WithLabelValues(method, "none").Inc()
+		return
+	}
+	logins.WithLabelValues(method, chainLabel(chain)).Inc()
+}
+
+// NFTListed 记录一次 NFT 上架
+func NFTListed(chain string) {
+	nftListings.WithLabelValues(chainLabel(chain)).Inc()
+}
+
+// NFTDrafted 记录一次懒铸造草稿的创建，草稿尚未上架，不计入 nft_listings_total
+func NFTDrafted(chain string) {
+	nftDrafts.WithLabelValues(chainLabel(chain)).Inc()
+}
+
+// NFTSold 记录一笔 NFT 成交，price 为链原生币金额
+func NFTSold(chain string, price money.Decimal) {
+	label := chainLabel(chain)
+	nftSales.WithLabelValues(label).Inc()
+	if price.Sign() > 0 {
+		nftSalesVolume.WithLabelValues(label).Add(price.Float64())
+	}
+}
+
+// Swapped 记录一笔 swap
+func Swapped(chain string) {
+	swaps.WithLabelValues(chainLabel(chain)).Inc()
+}
+
+// chainLabel 只允许已知的链作为标签值
+func chainLabel(chain string) string {
+	switch chain {
+	case constants.ChainEVM, constants.ChainTON, constants.ChainSolana:
+		return chain
+	}
+	return "other"
+}
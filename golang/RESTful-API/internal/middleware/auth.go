@@ -0,0 +1,103 @@
+// Package middleware gin 中间件
+package middleware
+
+import (
+	"RESTful-API/internal/auth"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/response"
+	"RESTful-API/internal/service"
+	"RESTful-API/internal/session"
+	"RESTful-API/utils/logs"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+const (
+	AccessTokenCookie = "access_token" // 存放 access token 的 cookie 名
+
+	currentUserKey = "current_user" // gin.Context 中存放当前用户的 key
+)
+
+// Auth 必须登录的路由使用，未登录或 token 无效时直接返回错误
+func Auth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := accessToken(c)
+		if token == "" {
+			response.FailWithStatus(c, http.StatusUnauthorized, errno.ErrNeedLogin)
+			return
+		}
+
+		user, e := authenticate(c.Request.Context(), token)
+		if e != nil {
+			response.FailWithStatus(c, http.StatusUnauthorized, e)
+			return
+		}
+		setCurrentUser(c, user)
+		c.Next()
+	}
+}
+
+// OptionalAuth 允许匿名访问的路由使用，携带有效 token 时加载当前用户
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := accessToken(c); token != "" {
+			if user, e := authenticate(c.Request.Context(), token); e == nil {
+				setCurrentUser(c, user)
+			}
+		}
+		c.Next()
+	}
+}
+
+// CurrentUser 获取当前登录用户，匿名访问时返回 nil, false
+func CurrentUser(c *gin.Context) (*auth.CurrentUser, bool) {
+	if v, ok := c.Get(currentUserKey); ok {
+		user, ok := v.(*auth.CurrentUser)
+		return user, ok
+	}
+	return nil, false
+}
+
+// authenticate 校验 access token 并加载用户
+func authenticate(ctx context.Context, token string) (*auth.CurrentUser, *errno.ErrMsg) {
+	manager, err := session.Default()
+	if err != nil {
+		logs.ErrorCtx(ctx, "session manager init error: %v", err)
+		return nil, errno.ErrServerLoginAuthError
+	}
+
+	claims, err := manager.ParseAccess(token)
+	if err != nil {
+		if !errors.Is(err, session.ErrTokenExpired) && !errors.Is(err, session.ErrTokenInvalid) {
+			logs.ErrorCtx(ctx, "parse access token error: %v", err)
+		}
+		return nil, errno.ErrNotLoginError
+	}
+
+	user, e := service.LoadCurrentUser(ctx, claims.UserID)
+	if e != nil {
+		return nil, errno.ErrServerLoginAuthError
+	}
+	return user, nil
+}
+
+// accessToken 依次从 Authorization 头和 cookie 中读取 access token
+func accessToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+			return strings.TrimSpace(header[7:])
+		}
+		return ""
+	}
+	token, _ := c.Cookie(AccessTokenCookie)
+	return token
+}
+
+// setCurrentUser 将当前用户同时写入 gin.Context 和 request context，方便 service 层通过 ctx 获取
+func setCurrentUser(c *gin.Context, user *auth.CurrentUser) {
+	c.Set(currentUserKey, user)
+	c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), user))
+}
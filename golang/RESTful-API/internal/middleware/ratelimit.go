@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/ratelimit"
+	"RESTful-API/internal/response"
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/logs"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	RateLimitDefault = "default" // 默认策略
+	RateLimitLogin   = "login"   // 登录、注册
+	RateLimitNonce   = "nonce"   // 获取 nonce / payload
+
+	rateKeyUser   = "user"
+	rateKeyWallet = "wallet"
+	rateKeyIP     = "ip"
+)
+
+// defaultRatePolicies 未配置时使用的策略，登录和 nonce 更严格
+var defaultRatePolicies = map[string]struct {
+	perMinute float64
+	burst     int
+	key       string
+}{
+	RateLimitDefault: {perMinute: 120, burst: 60, key: rateKeyUser},
+	RateLimitLogin:   {perMinute: 10, burst: 5, key: rateKeyIP},
+	RateLimitNonce:   {perMinute: 20, burst: 10, key: rateKeyIP},
+}
+
+var (
+	rateStore     ratelimit.Store
+	rateStoreOnce sync.Once
+)
+
+// RateLimit 按路由分组限流，超限时返回 429 和 ErrUserHandleTooOftenExists。
+// 响应头遵循 IETF RateLimit 头草案：RateLimit-Limit / RateLimit-Remaining / RateLimit-Reset / RateLimit-Policy。
+// 在注册路由时调用，策略配置错误时 panic，服务拒绝启动
+func RateLimit(group string) gin.HandlerFunc {
+	policy, keyBy, err := ratePolicy(group)
+	if err != nil {
+		panic(fmt.Sprintf("限流配置错误 %s: %s", group, err.Error()))
+	}
+	enabled := config.GetConfig("ratelimit.enable").MustBool(true)
+
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		result, err := limiterStore().Take(c.Request.Context(), group+":"+rateKey(c, keyBy), policy)
+		if err != nil { // 存储异常时放行，避免限流组件故障导致整个服务不可用
+			logs.ErrorCtx(c.Request.Context(), "rate limit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Burst, ceilSeconds(policy.Window())))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			response.FailWithStatus(c, http.StatusTooManyRequests, errno.ErrUserHandleTooOftenExists)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ratePolicy 读取分组的限流策略，未配置的项使用默认值，perMinute 和 burst 必须为正数
+func ratePolicy(group string) (ratelimit.Policy, string, error) {
+	def, ok := defaultRatePolicies[group]
+	if !ok {
+		def = defaultRatePolicies[RateLimitDefault]
+	}
+	prefix := "ratelimit." + group + "."
+	perMinute := config.GetConfig(prefix + "perMinute").MustFloat64(def.perMinute)
+	burst := config.GetConfig(prefix + "burst").MustInt(def.burst)
+	key := config.GetConfig(prefix + "key").MustString(def.key)
+	policy := ratelimit.Policy{Rate: perMinute / 60, Burst: burst}
+	if err := policy.Validate(); err != nil {
+		return ratelimit.Policy{}, "", err
+	}
+	return policy, key, nil
+}
+
+// rateKey 按配置的维度生成限流 key，未登录或没有绑定钱包时退化为 IP
+func rateKey(c *gin.Context, keyBy string) string {
+	switch keyBy {
+	case rateKeyUser:
+		if user, ok := CurrentUser(c); ok {
+			return "u:" + strconv.FormatInt(user.ID(), 10)
+		}
+	case rateKeyWallet: // 只使用登录用户绑定的钱包，不信任请求参数中的地址
+		if user, ok := CurrentUser(c); ok && len(user.Wallets) > 0 {
+			return "w:" + user.Wallets[0].WalletAddress // 绑定时已按链规范化，Solana 地址区分大小写
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// limiterStore 按配置创建限流存储
+func limiterStore() ratelimit.Store {
+	rateStoreOnce.Do(func() {
+		if config.GetConfig("ratelimit.store").String() == "redis" {
+			client := redis.NewClient(&redis.Options{
+				Addr:     config.GetConfig("ratelimit.redis.addr").String(),
+				Password: config.GetConfig("ratelimit.redis.password").String(),
+				DB:       config.GetConfig("ratelimit.redis.db").MustInt(constants.DefaultRedisIndex),
+			})
+			rateStore = ratelimit.NewRedisStore(client, "marketdao:ratelimit:")
+			return
+		}
+		rateStore = ratelimit.NewMemoryStore()
+	})
+	return rateStore
+}
+
+// ceilSeconds 向上取整到秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"RESTful-API/internal/ratelimit"
+	"RESTful-API/utils/config"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// useRateStore 替换限流存储，测试结束后恢复
+func useRateStore(t *testing.T, store ratelimit.Store) {
+	t.Helper()
+	rateStoreOnce.Do(func() {})
+	previous := rateStore
+	rateStore = store
+	t.Cleanup(func() { rateStore = previous })
+}
+
+// setConfig 临时修改 ini 配置，测试结束后恢复
+func setConfig(t *testing.T, key, value string) {
+	t.Helper()
+	previous := config.GetConfig(key).String()
+	config.GetConfig(key).SetValue(value)
+	t.Cleanup(func() { config.GetConfig(key).SetValue(previous) })
+}
+
+func rateLimitedRouter(group string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", RateLimit(group), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return r
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	useRateStore(t, ratelimit.NewMemoryStore())
+	setConfig(t, "ratelimit.login.perMinute", "10")
+	setConfig(t, "ratelimit.login.burst", "5")
+	r := rateLimitedRouter(RateLimitLogin)
+
+	get := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 每 6 秒补充一个令牌，桶容量 5
+	for i := 1; i <= 5; i++ {
+		w := get("192.0.2.1")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: status %d", i, w.Code)
+		}
+		want := map[string]string{
+			"RateLimit-Limit":     "5",
+			"RateLimit-Remaining": strconv.Itoa(5 - i),
+			"RateLimit-Reset":     strconv.Itoa(6 * i),
+			"RateLimit-Policy":    "5;w=30",
+			"Retry-After":         "",
+		}
+		for name, value := range want {
+			if got := w.Header().Get(name); got != value {
+				t.Errorf("request %d: %s = %q, want %q", i, name, got, value)
+			}
+		}
+	}
+
+	w := get("192.0.2.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "6" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("over limit: status %d, headers %v", w.Code, w.Header())
+	}
+	// 其他 IP 不受影响
+	if w = get("192.0.2.2"); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Remaining") != "4" {
+		t.Fatalf("other ip: status %d, headers %v", w.Code, w.Header())
+	}
+}
+
+// failingStore 模拟 Redis 不可用
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Policy) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+// TestRateLimitStoreError 存储异常时放行，不输出限流头
+func TestRateLimitStoreError(t *testing.T) {
+	useRateStore(t, failingStore{})
+	w := httptest.NewRecorder()
+	rateLimitedRouter(RateLimitDefault).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "" {
+		t.Fatalf("status %d, headers %v", w.Code, w.Header())
+	}
+}
+
+func TestRatePolicy(t *testing.T) {
+	tests := []struct {
+		perMinute, burst string
+		rate             float64
+		err              error
+	}{
+		{"120", "60", 2, nil},
+		{"0.5", "1", 0.5 / 60, nil},
+		{"0", "60", 0, ratelimit.ErrInvalidPolicy},
+		{"-10", "60", 0, ratelimit.ErrInvalidPolicy},
+		{"10", "0", 0, ratelimit.ErrInvalidPolicy},
+	}
+	for _, tt := range tests {
+		setConfig(t, "ratelimit.nonce.perMinute", tt.perMinute)
+		setConfig(t, "ratelimit.nonce.burst", tt.burst)
+		policy, key, err := ratePolicy(RateLimitNonce)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("perMinute %s, burst %s: got %v, want %v", tt.perMinute, tt.burst, err, tt.err)
+			continue
+		}
+		if err == nil && (policy.Rate != tt.rate || key != rateKeyIP) {
+			t.Errorf("perMinute %s, burst %s: got %+v, %s", tt.perMinute, tt.burst, policy, key)
+		}
+	}
+
+	// 注册路由时发现配置错误，拒绝启动
+	setConfig(t, "ratelimit.nonce.perMinute", "0")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("RateLimit with rate 0: want panic")
+		}
+	}()
+	RateLimit(RateLimitNonce)
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/rbac"
+	"RESTful-API/internal/response"
+	"RESTful-API/utils/logs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Require 声明路由需要的权限，必须放在 Auth 之后。
+// 角色随当前用户在 Auth 中一次性加载，同一个请求内不会重复查库。
+func Require(perms ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			response.FailWithStatus(c, http.StatusUnauthorized, errno.ErrNeedLogin)
+			return
+		}
+		if !user.Can(perms...) {
+			logs.WarnCtx(c.Request.Context(), "permission denied, user: %d, role: %s, path: %s", user.ID(), user.Role(), c.FullPath())
+			response.FailWithStatus(c, http.StatusForbidden, errno.ErrHandleInvalid)
+			return
+		}
+		c.Next()
+	}
+}
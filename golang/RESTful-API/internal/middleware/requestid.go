@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"RESTful-API/utils/logs"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"regexp"
+)
+
+const RequestIDHeader = "X-Request-ID" // 请求 id 的请求头和响应头
+
+// 上游传入的 request id 只接受字母数字和 -_.:，避免日志注入
+var requestIDRegExp = regexp.MustCompile(`^[a-zA-Z0-9\-_.:]{1,64}$`)
+
+// RequestID 为每个请求分配 request id：优先使用上游传入的 X-Request-ID，不合法或没有时重新生成。
+// request id 写入 request context，日志、SQL 日志和返回结构中都会带上。
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDRegExp.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Request = c.Request.WithContext(logs.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// newRequestID 生成随机的 request id
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -4,6 +4,8 @@ package model
 import (
 	"RESTful-API/internal/constants" // 导入内部常量包
 	"RESTful-API/internal/errno"     // 导入内部错误包
+	"RESTful-API/internal/money"     // 导入金额类型包
+	"context"                        // 导入 context 包，用于传递请求上下文
 	"reflect"                        // 导入反射包，用于类型检查
 	"strings"                        // 导入字符串处理包
 
@@ -15,9 +17,10 @@ import (
 var OrmMap map[string]*gorm.DB // 全局变量，存储不同名称的GORM DB实例
 
 type BaseModel struct {
-	db        *gorm.DB // GORM DB实例
-	tableName string   // 数据库表名
-	T         string   // 类型字段，未使用
+	db             *gorm.DB // GORM DB实例
+	tableName      string   // 数据库表名
+	addressColumns []string // 链上地址列，按 map 更新时规范化
+	T              string   // 类型字段，未使用
 }
 
 // NewOrm 初始化并返回一个GORM DB实例
@@ -39,6 +42,31 @@ func NewOrm(alias ...string) *gorm.DB {
 	return db // 返回DB实例
 }
 
+// newBaseModel 构造基础模型，传入事务时绑定到该事务
+func newBaseModel(tableName string, tx ...*gorm.DB) BaseModel {
+	m := BaseModel{tableName: tableName}
+	if len(tx) > 0 && tx[0] != nil {
+		m.db = tx[0].Table(tableName).Session(&gorm.Session{}) // 使用新会话，避免多次查询之间条件互相叠加
+	}
+	return m
+}
+
+// withAddressColumns 声明表中的链上地址列，按 map 更新这些列时同样转为规范格式
+func (m BaseModel) withAddressColumns(columns ...string) BaseModel {
+	m.addressColumns = columns
+	return m
+}
+
+// WithContext 绑定请求的 context，SQL 日志中会带上 context 中的 request id
+func (m *BaseModel) WithContext(ctx context.Context) *BaseModel {
+	db := m.db
+	if db == nil {
+		db = NewOrm().Table(m.tableName)
+	}
+	m.db = db.WithContext(ctx)
+	return m
+}
+
 // Create 基础模型的插入方法
 func (m *BaseModel) Create(dao interface{}) error {
 	var db *gorm.DB
@@ -142,6 +170,10 @@ func (m *BaseModel) Update(data map[string]interface{}, filters map[string]inter
 		len(data) <= 0 {
 		return 0, errno.InvalidParamError // 如果BaseModel、DB实例、过滤条件或数据为空，返回参数错误
 	}
+	data, err := m.normalizeAddressData(data, filters) // 按 map 更新不经过模型钩子，在这里规范化地址列
+	if err != nil {
+		return 0, err
+	}
 
 	if len(filters) > 0 {
 		for key, value := range filters {
@@ -161,6 +193,39 @@ func (m *BaseModel) Update(data map[string]interface{}, filters map[string]inter
 	return db.RowsAffected, nil // 返回受影响的行数和nil
 }
 
+// normalizeAddressData 将 data 中的地址列转为规范格式，返回新的 map，不修改调用方的 data。
+// 链名称取 data 中的 chain 或过滤条件 "chain = ?"，更新地址列却两者都没有时返回错误
+func (m *BaseModel) normalizeAddressData(data, filters map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	for _, column := range m.addressColumns {
+		addr, ok := data[column].(string)
+		if !ok || addr == "" {
+			continue
+		}
+		chainName, ok := data["chain"].(string)
+		if !ok {
+			chainName, ok = filters["chain = ?"].(string)
+		}
+		if !ok {
+			return nil, fmt.Errorf("update %s.%s without chain", m.tableName, column)
+		}
+		if err := normalizeAddresses(chainName, &addr); err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = make(map[string]interface{}, len(data))
+			for k, v := range data {
+				result[k] = v
+			}
+		}
+		result[column] = addr
+	}
+	if result == nil {
+		return data, nil
+	}
+	return result, nil
+}
+
 // ListAndTotal 基础模型的分页查询方法，返回列表和总数
 func (m *BaseModel) ListAndTotal(filters map[string]interface{}, page, pageSize int, list interface{}, orderBy ...string) (int64, error) {
 	var db *gorm.DB
@@ -380,7 +445,7 @@ func (m *BaseModel) ListNoPage(filters map[string]interface{}, list interface{},
 	return nil // 成功返回nil
 }
 
-// Sum 基础模型的求和方法
+// Sum 基础模型的求和方法，结果为 float64，金额请使用 SumDecimal
 func (m *BaseModel) Sum(field string, filters map[string]interface{}) (float64, error) {
 	var db *gorm.DB
 	if m.db != nil {
@@ -410,6 +475,37 @@ func (m *BaseModel) Sum(field string, filters map[string]interface{}) (float64,
 	return dao.Total, nil // 返回求和结果和nil
 }
 
+// SumDecimal 基础模型的精确求和方法，用于 DECIMAL 金额列，结果不经过 float64
+func (m *BaseModel) SumDecimal(field string, filters map[string]interface{}) (money.Decimal, error) {
+	var db *gorm.DB
+	if m.db != nil {
+		db = m.db // 如果BaseModel中有DB实例，则使用它
+	} else {
+		db = NewOrm().Table(m.tableName) // 否则初始化一个新的DB实例并指定表名
+	}
+	if m == nil ||
+		db == nil ||
+		field == "" {
+		return money.Zero, errno.InvalidParamError // 如果BaseModel、DB实例或字段为空，返回参数错误
+	}
+
+	// CAST 为字符串，MySQL 驱动以文本返回，由 money.Decimal 精确解析
+	sField := fmt.Sprintf(`CAST(IFNULL(SUM(%s), 0) AS CHAR) as total`, field) // 格式化SQL字段
+	db = db.Select(sField).Table(m.tableName)                                 // 指定查询字段和表名
+	if len(filters) > 0 {
+		for key, val := range filters {
+			db = db.Where(key, val) // 添加过滤条件
+		}
+	}
+
+	var dao struct{ Total money.Decimal } // 用于存储求和结果
+	err := db.Take(&dao).Error            // 执行查询操作
+	if OrmErr(err) != nil {
+		return money.Zero, err // 如果出错，返回错误
+	}
+	return dao.Total, nil // 返回求和结果和nil
+}
+
 // Distinct 基础模型的去重查询方法
 func (m *BaseModel) Distinct(filters map[string]interface{}, fields []string, dao interface{}, page int, pageSize int, orderBy ...string) error {
 	var db *gorm.DB
@@ -0,0 +1,50 @@
+package model_test
+
+import (
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/model/modeltest"
+	"RESTful-API/internal/money"
+	"context"
+	"testing"
+)
+
+// TestSumDecimal SQLite 把 DECIMAL 列存为 REAL，这里只用二进制能精确表示的金额，MySQL 下按 DECIMAL 精确求和
+func TestSumDecimal(t *testing.T) {
+	modeltest.Open(t, &model.NftTransactionsModel{})
+	ctx := context.Background()
+
+	sum, err := model.NewNftTransactionsModel().WithContext(ctx).SumDecimal("price", nil)
+	if err != nil || !sum.IsZero() {
+		t.Fatalf("empty table: %s, %v", sum, err)
+	}
+
+	for i, price := range []string{"1.5", "2.25", "0.125"} {
+		if err = model.NewNftTransactionsModel().WithContext(ctx).Create(&model.NftTransactionsModel{
+			NftID: int64(i%2 + 1), Price: money.MustParse(price), Chain: "EVM",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		filters map[string]interface{}
+		want    string
+	}{
+		{nil, "3.875"},
+		{map[string]interface{}{"nft_id = ?": 1}, "1.625"},
+		{map[string]interface{}{"nft_id = ?": 3}, "0"}, // 没有匹配的行时为 0 而不是 NULL
+	}
+	for _, tt := range tests {
+		sum, err = model.NewNftTransactionsModel().WithContext(ctx).SumDecimal("price", tt.filters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sum.Equal(money.MustParse(tt.want)) {
+			t.Errorf("SumDecimal(%v) = %s, want %s", tt.filters, sum, tt.want)
+		}
+	}
+
+	if _, err = model.NewNftTransactionsModel().WithContext(ctx).SumDecimal("", nil); err == nil {
+		t.Fatal("empty field: want error")
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"RESTful-API/internal/chain"
+	"fmt"
+)
+
+// normalizeAddresses 校验链名称，并将地址字段替换为该链的规范格式，空地址跳过。
+// 各模型在 BeforeSave 中调用；按 map 更新不经过钩子，由 BaseModel.Update 对 withAddressColumns 声明的列调用，
+// 保证同一个地址只会以一种形式写入数据库。
+func normalizeAddresses(chainName string, addrs ...*string) error {
+	c, ok := chain.Parse(chainName)
+	if !ok {
+		return fmt.Errorf("%w: %q", chain.ErrUnknownChain, chainName)
+	}
+	for _, addr := range addrs {
+		if addr == nil || *addr == "" {
+			continue
+		}
+		normalized, err := chain.NormalizeAddress(c, *addr)
+		if err != nil {
+			return fmt.Errorf("%w: %s %q", err, c, *addr)
+		}
+		*addr = normalized
+	}
+	return nil
+}
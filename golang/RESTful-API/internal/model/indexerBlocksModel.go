@@ -0,0 +1,28 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+const IndexerBlocksTableName = "indexer_blocks" // 已索引区块哈希表名
+
+// IndexerBlocksModel 索引过程中记录的区块哈希，链重组时用于查找分叉点
+type IndexerBlocksModel struct {
+	ID          int64               `json:"id" gorm:"primary_key;column:id"`
+	Chain       string              `json:"chain" gorm:"column:chain"`
+	BlockNumber uint64              `json:"block_number" gorm:"column:block_number"`
+	BlockHash   string              `json:"block_hash" gorm:"column:block_hash"`
+	BaseModel   `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (IndexerBlocksModel) TableName() string {
+	return IndexerBlocksTableName
+}
+
+// NewIndexerBlocksModel 创建区块哈希模型，传入事务时所有操作都在该事务内执行
+func NewIndexerBlocksModel(tx ...*gorm.DB) *IndexerBlocksModel {
+	m := &IndexerBlocksModel{}
+	m.BaseModel = newBaseModel(IndexerBlocksTableName, tx...)
+	return m
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const IndexerCheckpointsTableName = "indexer_checkpoints" // 索引进度表名
+
+// IndexerCheckpointsModel 链上索引进度，每个链上的每个合约（或账户）一条记录
+type IndexerCheckpointsModel struct {
+	ID              int64               `json:"id" gorm:"primary_key;column:id"`
+	Chain           string              `json:"chain" gorm:"column:chain"`
+	ContractAddress string              `json:"contract_address" gorm:"column:contract_address"`
+	BlockNumber     uint64              `json:"block_number" gorm:"column:block_number"` // 已处理到的高度（含），EVM 为区块号
+	BlockHash       string              `json:"block_hash" gorm:"column:block_hash"`     // 已处理到的区块哈希，用于检测链重组
+	UpdateAt        time.Time           `json:"update_time" gorm:"column:updated_at;autoUpdateTime"`
+	BaseModel       `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (IndexerCheckpointsModel) TableName() string {
+	return IndexerCheckpointsTableName
+}
+
+// BeforeSave 写入前规范化合约地址
+func (m *IndexerCheckpointsModel) BeforeSave(*gorm.DB) error {
+	return normalizeAddresses(m.Chain, &m.ContractAddress)
+}
+
+// NewIndexerCheckpointsModel 创建索引进度模型，传入事务时所有操作都在该事务内执行
+func NewIndexerCheckpointsModel(tx ...*gorm.DB) *IndexerCheckpointsModel {
+	m := &IndexerCheckpointsModel{}
+	m.BaseModel = newBaseModel(IndexerCheckpointsTableName, tx...).withAddressColumns("contract_address")
+	return m
+}
@@ -0,0 +1,36 @@
+// Package modeltest 测试用的 SQLite 数据库，替代 MySQL 运行 model 层的读写
+package modeltest
+
+import (
+	"RESTful-API/internal/model"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"path/filepath"
+	"testing"
+)
+
+// Open 在临时目录中创建 SQLite 数据库，按 models 建表并注册为默认连接，测试结束后恢复原来的连接。
+// 表结构由 gorm 按模型生成，不包含 sql 目录中 MySQL 建表语句的唯一索引等约束
+func Open(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+	// busy_timeout 让事务与事务外的查询在同一个文件上等待而不是直接报错
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := model.OrmMap
+	model.OrmMap = map[string]*gorm.DB{"default": db}
+	t.Cleanup(func() {
+		model.OrmMap = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
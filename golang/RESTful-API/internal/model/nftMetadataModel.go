@@ -0,0 +1,36 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const NftMetadataTableName = "nft_metadata" // NFT 元数据表名
+
+// NftMetadataModel nfts.metadata_uri 抓取并校验后的元数据，每个 NFT 一条记录
+type NftMetadataModel struct {
+	ID          int64               `json:"id" gorm:"primary_key;column:id"`
+	NftID       int64               `json:"nft_id" gorm:"column:nft_id"`
+	MetadataURI string              `json:"metadata_uri" gorm:"column:metadata_uri"`
+	ContentHash string              `json:"content_hash" gorm:"column:content_hash"` // Content 的 sha256（hex）
+	Name        string              `json:"name" gorm:"column:name"`
+	Description string              `json:"description" gorm:"column:description"`
+	Image       string              `json:"image" gorm:"column:image"`
+	Content     string              `json:"content" gorm:"column:content"`         // 规范化后的元数据 JSON
+	FetchError  string              `json:"fetch_error" gorm:"column:fetch_error"` // 最近一次抓取失败的原因
+	FetchedAt   time.Time           `json:"fetched_at" gorm:"column:fetched_at"`
+	UpdateAt    time.Time           `json:"update_time" gorm:"column:updated_at;autoUpdateTime"`
+	BaseModel   `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (NftMetadataModel) TableName() string {
+	return NftMetadataTableName
+}
+
+// NewNftMetadataModel 创建 NFT 元数据模型，传入事务时所有操作都在该事务内执行
+func NewNftMetadataModel(tx ...*gorm.DB) *NftMetadataModel {
+	m := &NftMetadataModel{}
+	m.BaseModel = newBaseModel(NftMetadataTableName, tx...)
+	return m
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"RESTful-API/internal/money"
+	"gorm.io/gorm"
+	"time"
+)
+
+const NftTransactionsTableName = "nft_transactions" // NFT 交易记录表名
+
+// NftTransactionsModel NFT 成交记录，price 对应 DECIMAL(30,10)
+type NftTransactionsModel struct {
+	ID                  int64               `json:"id" gorm:"primary_key;column:id"`
+	NftID               int64               `json:"nft_id" gorm:"column:nft_id"`
+	BuyerWalletAddress  string              `json:"buyer_wallet_address" gorm:"column:buyer_wallet_address"`
+	SellerWalletAddress string              `json:"seller_wallet_address" gorm:"column:seller_wallet_address"`
+	Price               money.Decimal       `json:"price" gorm:"column:price;type:decimal(30,10)"`
+	TxHash              string              `json:"tx_hash" gorm:"column:tx_hash"`
+	Chain               string              `json:"chain" gorm:"column:chain"`
+	BlockNumber         uint64              `json:"block_number" gorm:"column:block_number"` // 交易所在区块，链重组时按区块回滚
+	CreateAt            time.Time           `json:"create_time" gorm:"column:created_at;autoCreateTime"`
+	BaseModel           `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (NftTransactionsModel) TableName() string {
+	return NftTransactionsTableName
+}
+
+// BeforeSave 写入前规范化买卖双方的钱包地址
+func (m *NftTransactionsModel) BeforeSave(*gorm.DB) error {
+	return normalizeAddresses(m.Chain, &m.BuyerWalletAddress, &m.SellerWalletAddress)
+}
+
+// NewNftTransactionsModel 创建 NFT 交易记录模型，传入事务时所有操作都在该事务内执行
+func NewNftTransactionsModel(tx ...*gorm.DB) *NftTransactionsModel {
+	m := &NftTransactionsModel{}
+	m.BaseModel = newBaseModel(NftTransactionsTableName, tx...).withAddressColumns("buyer_wallet_address", "seller_wallet_address")
+	return m
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+const NftTransfersTableName = "nft_transfers" // NFT 转移记录表名
+
+// NftTransfersModel 索引器记录的链上 NFT 转移，链重组回滚后按剩余记录恢复拥有者
+type NftTransfersModel struct {
+	ID              int64               `json:"id" gorm:"primary_key;column:id"`
+	Chain           string              `json:"chain" gorm:"column:chain"`
+	ContractAddress string              `json:"contract_address" gorm:"column:contract_address"`
+	TokenID         string              `json:"token_id" gorm:"column:token_id"`
+	FromAddress     string              `json:"from_address" gorm:"column:from_address"` // 为空表示铸造
+	ToAddress       string              `json:"to_address" gorm:"column:to_address"`     // 为空表示销毁
+	BlockNumber     uint64              `json:"block_number" gorm:"column:block_number"`
+	BlockHash       string              `json:"block_hash" gorm:"column:block_hash"`
+	TxHash          string              `json:"tx_hash" gorm:"column:tx_hash"`
+	LogIndex        uint                `json:"log_index" gorm:"column:log_index"`
+	BaseModel       `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (NftTransfersModel) TableName() string {
+	return NftTransfersTableName
+}
+
+// BeforeSave 写入前规范化合约和转移双方的地址
+func (m *NftTransfersModel) BeforeSave(*gorm.DB) error {
+	return normalizeAddresses(m.Chain, &m.ContractAddress, &m.FromAddress, &m.ToAddress)
+}
+
+// NewNftTransfersModel 创建 NFT 转移记录模型，传入事务时所有操作都在该事务内执行
+func NewNftTransfersModel(tx ...*gorm.DB) *NftTransfersModel {
+	m := &NftTransfersModel{}
+	m.BaseModel = newBaseModel(NftTransfersTableName, tx...).withAddressColumns("contract_address", "from_address", "to_address")
+	return m
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const NftVouchersTableName = "nft_vouchers" // 懒铸造凭证表名
+
+const (
+	NftVoucherStatusPending = "pending" // 等待买家铸造
+	NftVoucherStatusMinted  = "minted"  // 索引器已发现铸造，草稿已转为正式 NFT
+)
+
+// NftVouchersModel 懒铸造凭证，每个草稿 NFT 一条记录
+type NftVouchersModel struct {
+	ID                   int64               `json:"id" gorm:"primary_key;column:id"`
+	NftID                int64               `json:"nft_id" gorm:"column:nft_id"`
+	Chain                string              `json:"chain" gorm:"column:chain"`
+	ContractAddress      string              `json:"contract_address" gorm:"column:contract_address"`
+	TokenID              string              `json:"token_id" gorm:"column:token_id"`
+	CreatorUserID        int64               `json:"creator_user_id" gorm:"column:creator_user_id"`
+	CreatorWalletAddress string              `json:"creator_wallet_address" gorm:"column:creator_wallet_address"`
+	MetadataURI          string              `json:"metadata_uri" gorm:"column:metadata_uri"`
+	MinPrice             string              `json:"min_price" gorm:"column:min_price"` // 单位 wei
+	SignerAddress        string              `json:"signer_address" gorm:"column:signer_address"`
+	Signature            string              `json:"signature" gorm:"column:signature"`
+	Status               string              `json:"status" gorm:"column:status;default:pending"`
+	CreateAt             time.Time           `json:"create_time" gorm:"column:created_at;autoCreateTime"`
+	UpdateAt             time.Time           `json:"update_time" gorm:"column:updated_at;autoUpdateTime"`
+	BaseModel            `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (NftVouchersModel) TableName() string {
+	return NftVouchersTableName
+}
+
+// BeforeSave 写入前规范化合约地址和创作者地址
+func (m *NftVouchersModel) BeforeSave(*gorm.DB) error {
+	return normalizeAddresses(m.Chain, &m.ContractAddress, &m.CreatorWalletAddress)
+}
+
+// NewNftVouchersModel 创建懒铸造凭证模型，传入事务时所有操作都在该事务内执行
+func NewNftVouchersModel(tx ...*gorm.DB) *NftVouchersModel {
+	m := &NftVouchersModel{}
+	m.BaseModel = newBaseModel(NftVouchersTableName, tx...).withAddressColumns("contract_address", "creator_wallet_address")
+	return m
+}
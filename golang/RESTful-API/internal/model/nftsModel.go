@@ -0,0 +1,42 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+const NftsTableName = "nfts" // NFT 资产表名
+
+const (
+	NftStatusListed   = "listed"   // 上架中
+	NftStatusUnlisted = "unlisted" // 未上架
+	NftStatusSold     = "sold"     // 已售出
+)
+
+// NftsModel NFT 资产，归属于钱包地址而不是用户
+type NftsModel struct {
+	ID                 int64               `json:"id" gorm:"primary_key;column:id"`
+	ContractAddress    string              `json:"contract_address" gorm:"column:contract_address"`
+	OwnerWalletAddress string              `json:"owner_wallet_address" gorm:"column:owner_wallet_address"`
+	Chain              string              `json:"chain" gorm:"column:chain"`
+	TokenID            string              `json:"token_id" gorm:"column:token_id;default:null"` // 懒铸造的草稿为空
+	MetadataURI        string              `json:"metadata_uri" gorm:"column:metadata_uri"`
+	Status             string              `json:"status" gorm:"column:status;default:unlisted"`
+	BaseModel          `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (NftsModel) TableName() string {
+	return NftsTableName
+}
+
+// BeforeSave 写入前规范化合约地址和拥有者地址
+func (m *NftsModel) BeforeSave(*gorm.DB) error {
+	return normalizeAddresses(m.Chain, &m.ContractAddress, &m.OwnerWalletAddress)
+}
+
+// NewNftsModel 创建 NFT 模型，传入事务时所有操作都在该事务内执行
+func NewNftsModel(tx ...*gorm.DB) *NftsModel {
+	m := &NftsModel{}
+	m.BaseModel = newBaseModel(NftsTableName, tx...).withAddressColumns("contract_address", "owner_wallet_address")
+	return m
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const RefreshTokensTableName = "user_refresh_tokens" // 刷新令牌表名
+
+// RefreshTokensModel 已签发的 refresh token 记录
+type RefreshTokensModel struct {
+	ID         int64               `json:"id" gorm:"primary_key;column:id"`
+	UserID     int64               `json:"user_id" gorm:"column:user_id"`
+	TokenID    string              `json:"token_id" gorm:"column:token_id"`
+	FamilyID   string              `json:"family_id" gorm:"column:family_id"`
+	ReplacedBy string              `json:"replaced_by" gorm:"column:replaced_by"`
+	ExpiresAt  time.Time           `json:"expires_at" gorm:"column:expires_at"`
+	SessionExp time.Time           `json:"session_expires_at" gorm:"column:session_expires_at"`
+	RevokedAt  *time.Time          `json:"revoked_at" gorm:"column:revoked_at"`
+	CreateAt   time.Time           `json:"create_time" gorm:"column:created_at;autoCreateTime"`
+	BaseModel  `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (RefreshTokensModel) TableName() string {
+	return RefreshTokensTableName
+}
+
+// NewRefreshTokensModel 创建刷新令牌模型，传入事务时所有操作都在该事务内执行
+func NewRefreshTokensModel(tx ...*gorm.DB) *RefreshTokensModel {
+	m := &RefreshTokensModel{}
+	m.BaseModel = newBaseModel(RefreshTokensTableName, tx...)
+	return m
+}
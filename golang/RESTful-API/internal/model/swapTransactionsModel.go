@@ -0,0 +1,41 @@
+package model
+
+import (
+	"RESTful-API/internal/money"
+	"gorm.io/gorm"
+	"time"
+)
+
+const SwapTransactionsTableName = "swap_transactions" // swap 交易记录表名
+
+// SwapTransactionsModel swap 记录，数量对应 DECIMAL(18,8)
+type SwapTransactionsModel struct {
+	ID               int64               `json:"id" gorm:"primary_key;column:id"`
+	WalletID         int64               `json:"wallet_id" gorm:"column:wallet_id"`
+	FromAssetAddress string              `json:"from_asset_address" gorm:"column:from_asset_address"`
+	ToAssetAddress   string              `json:"to_asset_address" gorm:"column:to_asset_address"`
+	FromAmount       money.Decimal       `json:"from_amount" gorm:"column:from_amount;type:decimal(18,8)"`
+	ToAmount         money.Decimal       `json:"to_amount" gorm:"column:to_amount;type:decimal(18,8)"`
+	Chain            string              `json:"chain" gorm:"column:chain"`
+	TransactionHash  string              `json:"transaction_hash" gorm:"column:transaction_hash"`
+	BlockNumber      uint64              `json:"block_number" gorm:"column:block_number"` // 交易所在区块，链重组时按区块回滚
+	CreateAt         time.Time           `json:"create_time" gorm:"column:created_at;autoCreateTime"`
+	BaseModel        `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (SwapTransactionsModel) TableName() string {
+	return SwapTransactionsTableName
+}
+
+// BeforeSave 写入前规范化兑换前后的代币合约地址
+func (m *SwapTransactionsModel) BeforeSave(*gorm.DB) error {
+	return normalizeAddresses(m.Chain, &m.FromAssetAddress, &m.ToAssetAddress)
+}
+
+// NewSwapTransactionsModel 创建 swap 记录模型，传入事务时所有操作都在该事务内执行
+func NewSwapTransactionsModel(tx ...*gorm.DB) *SwapTransactionsModel {
+	m := &SwapTransactionsModel{}
+	m.BaseModel = newBaseModel(SwapTransactionsTableName, tx...).withAddressColumns("from_asset_address", "to_asset_address")
+	return m
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const UserWalletsTableName = "user_wallets" // 用户钱包表名
+
+// UserWalletsModel 用户在各条链上绑定的钱包
+type UserWalletsModel struct {
+	ID            int64               `json:"id" gorm:"primary_key;column:id"`
+	UserID        int64               `json:"user_id" gorm:"column:user_id"`
+	Chain         string              `json:"chain" gorm:"column:chain"`
+	WalletAddress string              `json:"wallet_address" gorm:"column:wallet_address"`
+	CreateAt      time.Time           `json:"create_time" gorm:"column:created_at;autoCreateTime"`
+	BaseModel     `json:"-" gorm:"-"` // 继承基础模型
+}
+
+// TableName 指定 gorm 使用的表名
+func (UserWalletsModel) TableName() string {
+	return UserWalletsTableName
+}
+
+// BeforeSave 写入前规范化钱包地址
+func (m *UserWalletsModel) BeforeSave(*gorm.DB) error {
+	return normalizeAddresses(m.Chain, &m.WalletAddress)
+}
+
+// NewUserWalletsModel 创建用户钱包模型，传入事务时所有操作都在该事务内执行
+func NewUserWalletsModel(tx ...*gorm.DB) *UserWalletsModel {
+	m := &UserWalletsModel{}
+	m.BaseModel = newBaseModel(UserWalletsTableName, tx...).withAddressColumns("wallet_address")
+	return m
+}
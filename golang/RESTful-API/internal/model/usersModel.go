@@ -1,17 +1,39 @@
 package model
 
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const UsersTableName = "users" // 用户表名
+
 type UsersModel struct {
 	// gorm.Model // 引入模板结构体  ID, CreatedAt, UpdatedAt, DeletedAt
-	ID           int64  `json:"id" gorm:"primary_key;column:id"`
-	UserName     int64  `json:"username" gorm:"column:username"`
-	Email        string `json:"email" gorm:"column:email"`
-	PasswordHash string `json:"password_hash" gorm:"column:password_hash"`
-	//Status        int8    `json:"status" gorm:"column:status"`
+	ID           int64      `json:"id" gorm:"primary_key;column:id"`
+	UserName     string     `json:"username" gorm:"column:username"`
+	Email        string     `json:"email" gorm:"column:email;default:null"` // 没有邮箱时写入 NULL，email 有唯一索引
+	PasswordHash string     `json:"-" gorm:"column:password_hash"`
+	PasswordTry  uint       `json:"-" gorm:"column:password_try"`                     // 连续密码错误次数
+	LockedUntil  *time.Time `json:"locked_until" gorm:"column:locked_until"`          // 锁定截止时间，为空表示未锁定
+	AuthGroup    string     `json:"auth_level" gorm:"column:auth_group;default:user"` // 角色，见 rbac.Role
+	Status       string     `json:"status" gorm:"column:status;default:active"`       // 账号状态
 	//EntBalance    float64 `json:"ent_balance" gorm:"column:ent_balance"`
 	//ArtBalance    float64 `json:"art_balance" gorm:"column:art_balance"`
 	//UserType      int8    `json:"user_type" gorm:"column:user_type"`
 	//LoginIP       string  `json:"login_ip" gorm:"column:login_ip"`
-	CreateAt  string              `json:"create_time" gorm:"column:create_at"`
-	UpdateAt  string              `json:"update_time" gorm:"column:update_at"`
+	CreateAt  time.Time           `json:"create_time" gorm:"column:created_at;autoCreateTime"`
+	UpdateAt  time.Time           `json:"update_time" gorm:"column:updated_at;autoUpdateTime"`
 	BaseModel `json:"-" gorm:"-"` // 继承基础模型
 }
+
+// TableName 指定 gorm 使用的表名
+func (UsersModel) TableName() string {
+	return UsersTableName
+}
+
+// NewUsersModel 创建用户模型，传入事务时所有操作都在该事务内执行
+func NewUsersModel(tx ...*gorm.DB) *UsersModel {
+	m := &UsersModel{}
+	m.BaseModel = newBaseModel(UsersTableName, tx...)
+	return m
+}
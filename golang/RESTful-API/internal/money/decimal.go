@@ -0,0 +1,168 @@
+// Package money 价格、数量等金额的精确十进制类型
+//
+// 数据库中的 DECIMAL 列、接口中的金额一律使用 Decimal，不使用 float64：
+//   - 读写数据库时以字符串传递，与 DECIMAL 列精确对应
+//   - JSON 序列化为字符串，例如 "1.5"；反序列化只接受字符串，
+//     避免 jsoniter 的模糊解码把数字当作 float64 处理而丢失精度
+//   - 链上金额为整数的最小单位，通过 FromBaseUnits / BaseUnits 按代币精度换算
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"math/big"
+)
+
+var (
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	ErrNotString      = errors.New("decimal must be a json string")
+	ErrPrecision      = errors.New("amount has more decimal places than the token supports")
+)
+
+// Decimal 精确十进制数，零值为 0
+type Decimal struct {
+	d decimal.Decimal
+}
+
+// Zero 0
+var Zero = Decimal{}
+
+// Parse 解析十进制字符串，不接受科学计数法以外的非法格式
+func Parse(s string) (Decimal, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return Zero, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	return Decimal{d: d}, nil
+}
+
+// MustParse 解析十进制字符串，失败时 panic，只用于常量
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewFromInt 整数转为 Decimal
+func NewFromInt(v int64) Decimal {
+	return Decimal{d: decimal.NewFromInt(v)}
+}
+
+// FromBaseUnits 链上最小单位转为可读金额，例如 1500000000000000000 wei、18 位精度 => 1.5
+func FromBaseUnits(units *big.Int, decimals int32) Decimal {
+	if units == nil {
+		return Zero
+	}
+	return Decimal{d: decimal.NewFromBigInt(units, -decimals)}
+}
+
+// FromBaseUnitsString 同 FromBaseUnits，units 为十进制整数字符串
+func FromBaseUnitsString(units string, decimals int32) (Decimal, error) {
+	i, ok := new(big.Int).SetString(units, 10)
+	if !ok {
+		return Zero, fmt.Errorf("%w: %q", ErrInvalidDecimal, units)
+	}
+	return FromBaseUnits(i, decimals), nil
+}
+
+// BaseUnits 可读金额转为链上最小单位，小数位超过代币精度时返回 ErrPrecision，不做四舍五入
+func (x Decimal) BaseUnits(decimals int32) (*big.Int, error) {
+	shifted := x.d.Shift(decimals)
+	if !shifted.Equal(shifted.Truncate(0)) {
+		return nil, ErrPrecision
+	}
+	return shifted.BigInt(), nil
+}
+
+func (x Decimal) Add(y Decimal) Decimal { return Decimal{d: x.d.Add(y.d)} }
+
+func (x Decimal) Sub(y Decimal) Decimal { return Decimal{d: x.d.Sub(y.d)} }
+
+func (x Decimal) Mul(y Decimal) Decimal { return Decimal{d: x.d.Mul(y.d)} }
+
+// Cmp 比较大小：x < y 返回 -1，相等返回 0，x > y 返回 1
+func (x Decimal) Cmp(y Decimal) int { return x.d.Cmp(y.d) }
+
+func (x Decimal) Equal(y Decimal) bool { return x.d.Equal(y.d) }
+
+func (x Decimal) Sign() int { return x.d.Sign() }
+
+func (x Decimal) IsZero() bool { return x.d.IsZero() }
+
+// Round 按小数位四舍五入，用于写入精度固定的 DECIMAL 列之前
+func (x Decimal) Round(places int32) Decimal { return Decimal{d: x.d.Round(places)} }
+
+// Float64 近似的浮点值，只用于监控指标等不要求精确的场景
+func (x Decimal) Float64() float64 {
+	f, _ := x.d.Float64()
+	return f
+}
+
+// String 不带指数的十进制字符串
+func (x Decimal) String() string {
+	return x.d.String()
+}
+
+// MarshalJSON 序列化为 JSON 字符串
+func (x Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + x.d.String() + `"`), nil
+}
+
+// UnmarshalJSON 只接受 JSON 字符串，null 视为 0
+func (x *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*x = Zero
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return ErrNotString
+	}
+	d, err := Parse(string(data[1 : len(data)-1]))
+	if err != nil {
+		return err
+	}
+	*x = d
+	return nil
+}
+
+// Value 写入数据库时使用字符串，保证 DECIMAL 列精确
+func (x Decimal) Value() (driver.Value, error) {
+	return x.d.String(), nil
+}
+
+// Scan 从数据库读取 DECIMAL 列，MySQL 驱动返回 []byte
+func (x *Decimal) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*x = Zero
+		return nil
+	case []byte:
+		return x.scanString(string(v))
+	case string:
+		return x.scanString(v)
+	case int64:
+		*x = NewFromInt(v)
+		return nil
+	default:
+		// float64 等其他类型交给 decimal 处理，可能已经损失精度，尽量避免
+		return x.d.Scan(value)
+	}
+}
+
+func (x *Decimal) scanString(s string) error {
+	d, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*x = d
+	return nil
+}
+
+// GormDataType gorm 建表时使用的类型
+func (Decimal) GormDataType() string {
+	return "decimal"
+}
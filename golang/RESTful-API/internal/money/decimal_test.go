@@ -0,0 +1,162 @@
+package money
+
+import (
+	"RESTful-API/utils/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"1.5", "1.5", nil},
+		{"-0.000000000000000001", "-0.000000000000000001", nil},
+		{"123456789012345678901234567890.0123456789", "123456789012345678901234567890.0123456789", nil},
+		{"1e3", "1000", nil},
+		{"0.10", "0.1", nil},
+		{"", "", ErrInvalidDecimal},
+		{"abc", "", ErrInvalidDecimal},
+		{"1.2.3", "", ErrInvalidDecimal},
+		{"0x10", "", ErrInvalidDecimal},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q): got err %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestUnmarshalJSON 经过 utils/json（jsoniter 并注册了模糊解码）解码，与接口收到的请求一致。
+// jsoniter 不包装 UnmarshalJSON 返回的错误，只能按错误信息判断
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{`{"price":"1.5"}`, "1.5", nil},
+		{`{"price":"0.1000000000000000001"}`, "0.1000000000000000001", nil},
+		{`{"price":null}`, "0", nil},
+		{`{}`, "0", nil},
+		// 模糊解码会把数字当作 float64 处理，必须拒绝
+		{`{"price":1.5}`, "", ErrNotString},
+		{`{"price":0.1000000000000000001}`, "", ErrNotString},
+		{`{"price":15}`, "", ErrNotString},
+		{`{"price":true}`, "", ErrNotString},
+		{`{"price":"1,5"}`, "", ErrInvalidDecimal},
+	}
+	for _, tt := range tests {
+		var v struct {
+			Price Decimal `json:"price"`
+		}
+		err := json.Unmarshal([]byte(tt.in), &v)
+		if (err == nil) != (tt.err == nil) || err != nil && !strings.Contains(err.Error(), tt.err.Error()) {
+			t.Errorf("%s: got err %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && v.Price.String() != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.in, v.Price, tt.want)
+		}
+	}
+
+	out, err := json.Marshal(struct {
+		Price Decimal `json:"price"`
+	}{MustParse("1.50")})
+	if err != nil || string(out) != `{"price":"1.5"}` {
+		t.Fatalf("marshal: %s, %v", out, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+		err  error
+	}{
+		{[]byte("12.3400000000"), "12.34", nil}, // MySQL 驱动返回的 DECIMAL(30,10)
+		{"0.000000000000000001", "0.000000000000000001", nil},
+		{int64(-7), "-7", nil},
+		{nil, "0", nil},
+		{[]byte("abc"), "", ErrInvalidDecimal},
+		{"", "", ErrInvalidDecimal},
+	}
+	for _, tt := range tests {
+		d := MustParse("99") // 确认 Scan 覆盖原来的值
+		err := d.Scan(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Scan(%#v): got err %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && d.String() != tt.want {
+			t.Errorf("Scan(%#v) = %s, want %s", tt.in, d, tt.want)
+		}
+	}
+
+	v, err := MustParse("0.1000000000000000001").Value()
+	if err != nil || v != "0.1000000000000000001" {
+		t.Fatalf("Value() = %#v, %v", v, err)
+	}
+}
+
+func TestBaseUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int32
+		units    string
+		err      error
+	}{
+		{"1.5", 18, "1500000000000000000", nil},
+		{"0.000000000000000001", 18, "1", nil},
+		{"3000", 6, "3000000000", nil},
+		{"2.05", 9, "2050000000", nil},
+		{"1", 0, "1", nil},
+		{"-0.5", 1, "-5", nil},
+		{"0", 18, "0", nil},
+		// 不做四舍五入
+		{"0.0000000000000000001", 18, "", ErrPrecision},
+		{"1.0000001", 6, "", ErrPrecision},
+		{"1.5", 0, "", ErrPrecision},
+	}
+	for _, tt := range tests {
+		units, err := MustParse(tt.amount).BaseUnits(tt.decimals)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("BaseUnits(%s, %d): got err %v, want %v", tt.amount, tt.decimals, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if units.String() != tt.units {
+			t.Errorf("BaseUnits(%s, %d) = %s, want %s", tt.amount, tt.decimals, units, tt.units)
+		}
+		// 往返换算得到原来的金额
+		if back := FromBaseUnits(units, tt.decimals); !back.Equal(MustParse(tt.amount)) {
+			t.Errorf("FromBaseUnits(%s, %d) = %s, want %s", units, tt.decimals, back, tt.amount)
+		}
+		if back, err := FromBaseUnitsString(tt.units, tt.decimals); err != nil || !back.Equal(MustParse(tt.amount)) {
+			t.Errorf("FromBaseUnitsString(%s, %d) = %s, %v", tt.units, tt.decimals, back, err)
+		}
+	}
+
+	if d := FromBaseUnits(nil, 18); !d.IsZero() {
+		t.Errorf("FromBaseUnits(nil) = %s", d)
+	}
+	// 超过 uint256 的金额也能精确换算
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890", 10)
+	if units, err := FromBaseUnits(huge, 18).BaseUnits(18); err != nil || units.Cmp(huge) != 0 {
+		t.Errorf("huge round trip: %s, %v", units, err)
+	}
+	if _, err := FromBaseUnitsString("1.5", 18); !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("FromBaseUnitsString(1.5): got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package password 密码哈希与校验
+//
+// 新密码统一使用 argon2id，存储为 PHC 格式：
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+// 同时兼容校验历史遗留的 bcrypt 哈希，登录成功后通过 NeedsRehash 升级。
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+var (
+	ErrMismatch      = errors.New("password mismatch")
+	ErrInvalidHash   = errors.New("invalid password hash")
+	ErrIncompatible  = errors.New("incompatible argon2 version")
+	ErrEmptyPassword = errors.New("empty password")
+)
+
+// Params argon2id 参数
+type Params struct {
+	Memory      uint32 // 内存开销，单位 KiB
+	Iterations  uint32 // 迭代次数
+	Parallelism uint8  // 并行度
+	SaltLength  uint32 // 盐长度
+	KeyLength   uint32 // 哈希长度
+}
+
+// DefaultParams OWASP 推荐的 argon2id 参数
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Hash 使用 argon2id 计算密码哈希
+func Hash(plain string, p Params) (string, error) {
+	if plain == "" {
+		return "", ErrEmptyPassword
+	}
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(plain), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify 校验密码，支持 argon2id 和 bcrypt
+func Verify(plain, encoded string) error {
+	if isBcrypt(encoded) {
+		if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(plain)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrMismatch
+			}
+			return ErrInvalidHash
+		}
+		return nil
+	}
+
+	p, salt, key, err := decode(encoded)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(plain), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// NeedsRehash 哈希算法或参数与当前配置不一致时返回 true
+func NeedsRehash(encoded string, p Params) bool {
+	if isBcrypt(encoded) {
+		return true
+	}
+	old, salt, key, err := decode(encoded)
+	if err != nil {
+		return true
+	}
+	return old.Memory != p.Memory ||
+		old.Iterations != p.Iterations ||
+		old.Parallelism != p.Parallelism ||
+		uint32(len(salt)) != p.SaltLength ||
+		uint32(len(key)) != p.KeyLength
+}
+
+// isBcrypt 判断是否为 bcrypt 哈希
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// decode 解析 PHC 格式的 argon2id 哈希
+func decode(encoded string) (Params, []byte, []byte, error) {
+	var p Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return p, nil, nil, ErrIncompatible
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrInvalidHash
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
@@ -0,0 +1,102 @@
+package password
+
+import (
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+// testParams 比默认参数小，只为加快测试
+var testParams = Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashAndVerify(t *testing.T) {
+	hash, err := Hash("correct horse", testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("unexpected hash format %s", hash)
+	}
+	if other, _ := Hash("correct horse", testParams); other == hash {
+		t.Fatal("hashes of the same password should use different salts")
+	}
+	if err = Verify("correct horse", hash); err != nil {
+		t.Fatalf("Verify = %v", err)
+	}
+	if err = Verify("correct horse!", hash); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("wrong password: got %v, want ErrMismatch", err)
+	}
+	if _, err = Hash("", testParams); !errors.Is(err, ErrEmptyPassword) {
+		t.Fatalf("empty password: got %v, want ErrEmptyPassword", err)
+	}
+}
+
+func TestVerifyBcrypt(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("legacy"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Verify("legacy", string(legacy)); err != nil {
+		t.Fatalf("Verify = %v", err)
+	}
+	if err = Verify("legacy2", string(legacy)); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("wrong password: got %v, want ErrMismatch", err)
+	}
+	if err = Verify("legacy", "$2a$04$broken"); !errors.Is(err, ErrInvalidHash) {
+		t.Fatalf("broken bcrypt: got %v, want ErrInvalidHash", err)
+	}
+}
+
+func TestVerifyInvalidHash(t *testing.T) {
+	tests := []struct {
+		encoded string
+		err     error
+	}{
+		{"", ErrInvalidHash},
+		{"plaintext", ErrInvalidHash},
+		{"$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA", ErrInvalidHash},
+		{"$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA", ErrIncompatible},
+		{"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA", ErrInvalidHash},
+		{"$argon2id$v=19$m=1024,t=1,p=1$!!!$aGFzaA", ErrInvalidHash},
+		{"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$", ErrInvalidHash},
+	}
+	for _, tt := range tests {
+		if err := Verify("anything", tt.encoded); !errors.Is(err, tt.err) {
+			t.Errorf("Verify(%q) = %v, want %v", tt.encoded, err, tt.err)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current, err := Hash("pw", testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stronger := testParams
+	stronger.Iterations = 2
+	longer := testParams
+	longer.KeyLength = 64
+
+	tests := []struct {
+		name    string
+		encoded string
+		params  Params
+		want    bool
+	}{
+		{"same params", current, testParams, false},
+		{"more iterations", current, stronger, true},
+		{"longer key", current, longer, true},
+		{"bcrypt", string(legacy), testParams, true},
+		{"invalid", "plaintext", testParams, true},
+	}
+	for _, tt := range tests {
+		if got := NeedsRehash(tt.encoded, tt.params); got != tt.want {
+			t.Errorf("%s: NeedsRehash = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
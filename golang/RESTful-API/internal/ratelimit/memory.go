@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// bucket 单个 key 的令牌桶，记录最近一次使用的策略，清理时按桶自己的策略判断是否已装满
+type bucket struct {
+	tokens float64
+	last   time.Time
+	policy Policy
+}
+
+// MemoryStore 进程内存储，只适合单实例部署
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	lastGC  time.Time
+}
+
+// NewMemoryStore 创建进程内存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.gc(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = refill(b.tokens, now.Sub(b.last), policy)
+	b.last = now
+	b.policy = policy
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return newResult(allowed, b.tokens, policy), nil
+}
+
+// gc 每分钟清理一次已经装满的桶，避免 key 无限增长
+func (s *MemoryStore) gc(now time.Time) {
+	if now.Sub(s.lastGC) < time.Minute {
+		return
+	}
+	s.lastGC = now
+	for key, b := range s.buckets {
+		if refill(b.tokens, now.Sub(b.last), b.policy) >= float64(b.policy.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
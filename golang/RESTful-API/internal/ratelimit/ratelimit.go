@@ -0,0 +1,74 @@
+// Package ratelimit 令牌桶限流，支持进程内存储和 Redis 兼容存储
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInvalidPolicy 策略的速率或桶容量不是正数
+var ErrInvalidPolicy = errors.New("invalid rate limit policy")
+
+// Policy 令牌桶策略
+type Policy struct {
+	Rate  float64 // 每秒补充的令牌数
+	Burst int     // 桶容量，也就是允许的瞬时并发
+}
+
+// Validate 速率必须大于 0，否则桶永远不会补充，Redis 脚本计算过期时间时也会除以 0；桶容量至少为 1
+func (p Policy) Validate() error {
+	if !(p.Rate > 0) || p.Burst < 1 { // !(p.Rate > 0) 同时排除 NaN
+		return fmt.Errorf("%w: rate %v, burst %d", ErrInvalidPolicy, p.Rate, p.Burst)
+	}
+	return nil
+}
+
+// Window 桶从空到满需要的时间，用于 RateLimit-Policy 头
+func (p Policy) Window() time.Duration {
+	if p.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(p.Burst) / p.Rate * float64(time.Second))
+}
+
+// Result 一次取令牌的结果
+type Result struct {
+	Allowed    bool
+	Limit      int           // 桶容量
+	Remaining  int           // 剩余令牌
+	Reset      time.Duration // 桶重新装满需要的时间
+	RetryAfter time.Duration // 被拒绝时，多久之后可以重试
+}
+
+// Store 令牌桶存储
+type Store interface {
+	// Take 从 key 对应的桶中取一个令牌
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// refill 按经过的时间补充令牌
+func refill(tokens float64, elapsed time.Duration, policy Policy) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(policy.Burst), tokens+elapsed.Seconds()*policy.Rate)
+}
+
+// newResult 根据取令牌后的剩余量计算返回结果
+func newResult(allowed bool, tokens float64, policy Policy) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     policy.Burst,
+		Remaining: int(math.Floor(tokens)),
+	}
+	if policy.Rate > 0 {
+		r.Reset = time.Duration((float64(policy.Burst) - tokens) / policy.Rate * float64(time.Second))
+		if !allowed {
+			r.RetryAfter = time.Duration((1 - tokens) / policy.Rate * float64(time.Second))
+		}
+	}
+	return r
+}
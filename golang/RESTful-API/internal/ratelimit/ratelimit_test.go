@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+// clock 测试用的时钟，两种存储共用
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// testStore 在 store 上按同一组步骤检查令牌桶的行为
+func testStore(t *testing.T, store Store, c *clock) {
+	t.Helper()
+	ctx := context.Background()
+	policy := Policy{Rate: 1, Burst: 3}
+
+	steps := []struct {
+		advance    time.Duration
+		key        string
+		allowed    bool
+		remaining  int
+		reset      time.Duration
+		retryAfter time.Duration
+	}{
+		{0, "a", true, 2, time.Second, 0},
+		{0, "a", true, 1, 2 * time.Second, 0},
+		{0, "a", true, 0, 3 * time.Second, 0},
+		{0, "a", false, 0, 3 * time.Second, time.Second},
+		// 每个 key 有自己的桶
+		{0, "b", true, 2, time.Second, 0},
+		{500 * time.Millisecond, "a", false, 0, 2500 * time.Millisecond, 500 * time.Millisecond},
+		{500 * time.Millisecond, "a", true, 0, 3 * time.Second, 0},
+		// 补充的令牌不超过桶容量
+		{10 * time.Second, "a", true, 2, time.Second, 0},
+	}
+	for i, s := range steps {
+		c.advance(s.advance)
+		r, err := store.Take(ctx, s.key, policy)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		want := Result{Allowed: s.allowed, Limit: 3, Remaining: s.remaining, Reset: s.reset, RetryAfter: s.retryAfter}
+		if r != want {
+			t.Errorf("step %d: got %+v, want %+v", i, r, want)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	c := &clock{t: time.Unix(1700000000, 0)}
+	store := NewMemoryStore()
+	store.now = c.now
+	testStore(t, store, c)
+
+	// 一分钟后清理已经装满的桶
+	c.advance(time.Minute)
+	if _, err := store.Take(context.Background(), "c", Policy{Rate: 1, Burst: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.buckets) != 1 {
+		t.Fatalf("buckets after gc: %d, want 1", len(store.buckets))
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	c := &clock{t: time.Unix(1700000000, 0)}
+	store := NewRedisStore(client, "test:")
+	store.now = c.now
+	testStore(t, store, c)
+
+	// 过期时间为桶从空到满的时间再加 1 秒
+	if ttl := mr.TTL("test:a"); ttl != 4*time.Second {
+		t.Fatalf("ttl %v, want 4s", ttl)
+	}
+	if mr.Exists("a") {
+		t.Fatal("key without prefix")
+	}
+
+	mr.SetError("connection refused")
+	if _, err := store.Take(context.Background(), "a", Policy{Rate: 1, Burst: 3}); err == nil {
+		t.Fatal("want error from redis")
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		policy Policy
+		valid  bool
+	}{
+		{Policy{Rate: 2, Burst: 60}, true},
+		{Policy{Rate: 0.01, Burst: 1}, true},
+		{Policy{Rate: 0, Burst: 60}, false},
+		{Policy{Rate: -1, Burst: 60}, false},
+		{Policy{Rate: 1, Burst: 0}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.policy.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%+v: got %v", tt.policy, err)
+		}
+	}
+}
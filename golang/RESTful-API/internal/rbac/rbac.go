@@ -0,0 +1,77 @@
+// Package rbac 基于角色的权限控制，角色保存在 users.auth_group 中
+package rbac
+
+// Role 用户角色
+type Role string
+
+// Permission 路由上声明的权限
+type Permission string
+
+const (
+	RoleUser      Role = "user"      // 普通用户
+	RoleCreator   Role = "creator"   // 创作者，可以创建 NFT
+	RoleModerator Role = "moderator" // 审核员，可以下架内容
+	RoleAdmin     Role = "admin"     // 管理员
+
+	StatusActive   = "active"   // 正常
+	StatusDisabled = "disabled" // 已禁用，所有需要权限的操作都会被拒绝
+)
+
+const (
+	PermProfileRead   Permission = "profile:read"   // 查看自己的资料
+	PermWalletManage  Permission = "wallet:manage"  // 绑定、解绑钱包
+	PermNFTCreate     Permission = "nft:create"     // 创建 NFT
+	PermNFTTrade      Permission = "nft:trade"      // 挂单、购买 NFT
+	PermUpload        Permission = "upload"         // 上传文件
+	PermContentReview Permission = "content:review" // 审核、下架内容
+	PermRoleManage    Permission = "role:manage"    // 分配角色
+	PermSystemAdmin   Permission = "system:admin"   // 系统管理，如诊断接口
+)
+
+// rolePermissions 每个角色拥有的权限，高级角色包含低级角色的全部权限
+var rolePermissions = map[Role][]Permission{}
+
+func init() {
+	user := []Permission{PermProfileRead, PermWalletManage, PermNFTTrade}
+	creator := append(append([]Permission{}, user...), PermNFTCreate, PermUpload)
+	moderator := append(append([]Permission{}, creator...), PermContentReview)
+	admin := append(append([]Permission{}, moderator...), PermRoleManage, PermSystemAdmin)
+
+	rolePermissions[RoleUser] = user
+	rolePermissions[RoleCreator] = creator
+	rolePermissions[RoleModerator] = moderator
+	rolePermissions[RoleAdmin] = admin
+}
+
+// ParseRole 解析角色名，空值视为普通用户
+func ParseRole(s string) (Role, bool) {
+	if s == "" {
+		return RoleUser, true
+	}
+	r := Role(s)
+	_, ok := rolePermissions[r]
+	return r, ok
+}
+
+// Roles 所有角色
+func Roles() []Role {
+	return []Role{RoleUser, RoleCreator, RoleModerator, RoleAdmin}
+}
+
+// Can 判断角色是否拥有全部指定权限
+func Can(role Role, perms ...Permission) bool {
+	granted := rolePermissions[role]
+	for _, p := range perms {
+		found := false
+		for _, g := range granted {
+			if g == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
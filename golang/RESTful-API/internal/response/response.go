@@ -0,0 +1,45 @@
+// Package response 统一的接口返回结构
+package response
+
+import (
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/utils/logs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Response 接口统一返回的信封结构
+type Response struct {
+	Code      int64       `json:"code"`
+	Msg       string      `json:"msg"`
+	Data      interface{} `json:"data"`
+	RequestID string      `json:"request_id,omitempty"` // 与响应头 X-Request-ID 一致，方便排查问题
+}
+
+// Success 返回成功结果
+func Success(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, Response{
+		Code:      constants.DefaultSuccessCode,
+		Msg:       constants.DefaultSuccessMsg,
+		Data:      data,
+		RequestID: logs.RequestIDFromContext(c.Request.Context()),
+	})
+}
+
+// Fail 返回业务错误，HTTP 状态码固定为 200，错误信息通过 code 区分
+func Fail(c *gin.Context, e *errno.ErrMsg) {
+	FailWithStatus(c, http.StatusOK, e)
+}
+
+// FailWithStatus 返回业务错误并指定 HTTP 状态码，同时终止后续中间件
+func FailWithStatus(c *gin.Context, status int, e *errno.ErrMsg) {
+	if e == nil {
+		e = errno.ErrServer
+	}
+	c.AbortWithStatusJSON(status, Response{
+		Code:      e.Code,
+		Msg:       e.Msg,
+		RequestID: logs.RequestIDFromContext(c.Request.Context()),
+	})
+}
@@ -0,0 +1,73 @@
+// Package router 注册 HTTP 路由
+package router
+
+import (
+	"RESTful-API/internal/handler"
+	"RESTful-API/internal/middleware"
+	"RESTful-API/internal/rbac"
+	"RESTful-API/internal/storage"
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/logs"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// NewRouter 创建 gin 引擎并注册所有路由
+func NewRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Tracing(), middleware.Metrics(), gin.Recovery())
+	// 只信任配置中的代理转发的 X-Forwarded-For，否则客户端可以伪造 IP 绕过限流
+	if err := r.SetTrustedProxies(viper.GetStringSlice("server.trustedProxies")); err != nil {
+		logs.Error("set trusted proxies error: %v", err)
+	}
+
+	// Prometheus 监控指标，与业务接口同端口对外暴露，dev 以外必须配置访问令牌
+	if config.GetConfig("metrics.enable").MustBool(false) {
+		if config.GetConfig("metrics.token").String() == "" && !config.IsDev() {
+			logs.Error("metrics endpoint not registered: metrics.token is required outside dev mode")
+		} else {
+			r.GET(config.GetConfig("metrics.path").MustString("/metrics"), handler.Metrics())
+		}
+	}
+
+	// 使用本地存储时由本服务提供上传的文件
+	if prefix, dir, ok := storage.LocalStatic(); ok {
+		r.Static(prefix, dir)
+	}
+
+	api := r.Group("/api/v1")
+
+	// 登录鉴权
+	auth := api.Group("/auth")
+	{
+		auth.POST("/register", middleware.RateLimit(middleware.RateLimitLogin), handler.Register)
+		auth.POST("/login", middleware.RateLimit(middleware.RateLimitLogin), handler.PasswordLogin)
+		auth.GET("/ton/payload", middleware.RateLimit(middleware.RateLimitNonce), handler.TonPayload)
+		auth.POST("/ton/login", middleware.RateLimit(middleware.RateLimitLogin), handler.TonLogin)
+		auth.POST("/refresh", middleware.RateLimit(middleware.RateLimitLogin), handler.RefreshToken)
+		auth.POST("/logout", middleware.RateLimit(middleware.RateLimitDefault), handler.Logout)
+	}
+
+	// 用户中心，需要登录
+	user := api.Group("/user", middleware.Auth(), middleware.RateLimit(middleware.RateLimitDefault))
+	{
+		user.GET("/me", middleware.Require(rbac.PermProfileRead), handler.Me)
+		user.GET("/wallets", middleware.Require(rbac.PermProfileRead), handler.ListWallets)
+		user.GET("/wallets/nonce", middleware.Require(rbac.PermWalletManage), middleware.RateLimit(middleware.RateLimitNonce), handler.WalletNonce)
+		user.POST("/wallets", middleware.Require(rbac.PermWalletManage), handler.LinkWallet)
+		user.DELETE("/wallets/:chain", middleware.Require(rbac.PermWalletManage), handler.UnlinkWallet)
+		user.POST("/transactions/nft", middleware.Require(rbac.PermNFTTrade), handler.SubmitTrade)
+		user.POST("/transactions/swap", middleware.Require(rbac.PermNFTTrade), handler.SubmitSwap)
+		user.POST("/upload/image", middleware.Require(rbac.PermUpload), handler.UploadImage)
+		user.POST("/nfts", middleware.Require(rbac.PermNFTCreate), handler.CreateNFT)
+		user.GET("/nfts/:id/voucher", middleware.Require(rbac.PermNFTTrade), handler.NftVoucher)
+	}
+
+	// 管理后台
+	admin := api.Group("/admin", middleware.Auth(), middleware.Require(rbac.PermSystemAdmin))
+	{
+		admin.GET("/logs", handler.TailLogs)
+	}
+
+	return r
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/metrics"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/password"
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/logs"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"net/mail"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+var userNameRegExp = regexp.MustCompile(`^[a-zA-Z0-9_]{3,50}$`)
+
+// RegisterReq 用户名密码注册参数
+type RegisterReq struct {
+	UserName string `json:"username" binding:"required"`
+	Email    string `json:"email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// PasswordLoginReq 用户名或邮箱登录参数
+type PasswordLoginReq struct {
+	Account  string `json:"account" binding:"required"` // 用户名或邮箱
+	Password string `json:"password" binding:"required"`
+}
+
+// Register 用户名密码注册，注册成功后直接登录
+func Register(ctx context.Context, req *RegisterReq) (*LoginResult, *errno.ErrMsg) {
+	req.UserName = strings.TrimSpace(req.UserName)
+	if !userNameRegExp.MatchString(req.UserName) {
+		return nil, errno.ErrUserNameInvalidError
+	}
+	if req.Email != "" {
+		email, ok := normalizeEmail(req.Email)
+		if !ok {
+			return nil, errno.ErrInvalidEmailAddressError
+		}
+		req.Email = email
+	}
+	if !passwordStrong(req.Password) {
+		return nil, errno.ErrPasswordTooWeakError
+	}
+
+	if e := checkAccountExists(ctx, req.UserName, req.Email); e != nil {
+		return nil, e
+	}
+
+	hash, err := password.Hash(req.Password, passwordParams())
+	if err != nil {
+		logs.ErrorCtx(ctx, "hash password error: %v", err)
+		return nil, errno.ErrServer
+	}
+
+	user := &model.UsersModel{UserName: req.UserName, Email: req.Email, PasswordHash: hash}
+	if err = model.NewUsersModel().WithContext(ctx).Create(user); err != nil {
+		if model.IsUniqueErr(err) { // 并发注册时由唯一索引兜底，重新检查是用户名还是邮箱冲突
+			if e := checkAccountExists(ctx, req.UserName, req.Email); e != nil {
+				return nil, e
+			}
+			return nil, errno.ErrUserNameExistsError
+		}
+		logs.ErrorCtx(ctx, "create user error: %v", err)
+		return nil, errno.ErrUpdate
+	}
+	return withToken(ctx, &LoginResult{User: user})
+}
+
+// PasswordLogin 用户名或邮箱加密码登录，连续失败后渐进式锁定账号
+func PasswordLogin(ctx context.Context, req *PasswordLoginReq) (*LoginResult, *errno.ErrMsg) {
+	filters := map[string]interface{}{"username = ?": strings.TrimSpace(req.Account)}
+	if strings.Contains(req.Account, "@") {
+		email, ok := normalizeEmail(req.Account)
+		if !ok {
+			return nil, errno.ErrInvalidEmailAddressError
+		}
+		filters = map[string]interface{}{"email = ?": email}
+	}
+
+	user := &model.UsersModel{}
+	if err := model.NewUsersModel().WithContext(ctx).QueryOne(filters, user); err != nil {
+		logs.ErrorCtx(ctx, "query user error: %v", err)
+		return nil, errno.ErrQuery
+	}
+	// 钱包注册的用户没有密码，与账号不存在一样返回密码错误，避免暴露账号是否存在；
+	// 同样校验一次假哈希，使响应时间与密码错误一致
+	if user.ID == constants.DefaultZero || user.PasswordHash == constants.DefaultEmptyString {
+		if hash := dummyPasswordHash(); hash != "" {
+			_ = password.Verify(req.Password, hash)
+		}
+		return nil, errno.ErrPasswordError
+	}
+
+	if e := checkPassword(ctx, user, req.Password); e != nil {
+		return nil, e
+	}
+	metrics.Login(metrics.LoginPassword, "")
+	return withToken(ctx, &LoginResult{User: user})
+}
+
+// checkPassword 校验用户密码，登录和敏感操作前的重新验证共用：
+// 锁定期内直接拒绝，密码错误时累加失败次数，成功后清零并在需要时升级哈希
+func checkPassword(ctx context.Context, user *model.UsersModel, plain string) *errno.ErrMsg {
+	now := time.Now()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return errno.ErrAccountLockedError
+	}
+
+	err := password.Verify(plain, user.PasswordHash)
+	if errors.Is(err, password.ErrMismatch) {
+		return recordPasswordFailure(ctx, user, now)
+	}
+	if err != nil {
+		logs.ErrorCtx(ctx, "verify password error, user: %d, err: %v", user.ID, err)
+		return errno.ErrServer
+	}
+
+	data := map[string]interface{}{"password_try": 0, "locked_until": nil}
+	dirty := user.PasswordTry > 0 || user.LockedUntil != nil
+	// 哈希参数调整后，在密码验证通过时顺便升级旧哈希
+	if params := passwordParams(); password.NeedsRehash(user.PasswordHash, params) {
+		if hash, err := password.Hash(plain, params); err == nil {
+			data["password_hash"] = hash
+			dirty = true
+		} else {
+			logs.ErrorCtx(ctx, "rehash password error, user: %d, err: %v", user.ID, err)
+		}
+	}
+	if dirty {
+		if _, err = model.NewUsersModel().WithContext(ctx).Update(data, map[string]interface{}{"id = ?": user.ID}); err != nil {
+			logs.ErrorCtx(ctx, "reset password try error, user: %d, err: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// recordPasswordFailure 记录一次密码错误，超过阈值后按次数翻倍锁定。
+// 次数在数据库中原子递增并在同一事务中读回，并发的错误登录不会丢失计数
+func recordPasswordFailure(ctx context.Context, user *model.UsersModel, now time.Time) *errno.ErrMsg {
+	tries, err := incrPasswordTry(ctx, user.ID, now)
+	if err != nil {
+		logs.ErrorCtx(ctx, "record password try error, user: %d, err: %v", user.ID, err)
+		return errno.ErrPasswordError
+	}
+	if tries >= uint(config.GetConfig("auth.lock.maxTry").MustInt(5)) {
+		logs.WarnCtx(ctx, "account locked after %d failed password attempts, user: %d", tries, user.ID)
+		return errno.ErrAccountLockedError
+	}
+	return errno.ErrPasswordError
+}
+
+// incrPasswordTry 递增密码错误次数，达到阈值时写入锁定时间，返回递增后的次数
+func incrPasswordTry(ctx context.Context, userID int64, now time.Time) (uint, error) {
+	tx, err := model.TxBegin()
+	if err != nil {
+		return 0, err
+	}
+	filters := map[string]interface{}{"id = ?": userID}
+	// UPDATE 会锁住这一行，事务提交前其他请求的递增会等待
+	if _, err = model.NewUsersModel(tx).WithContext(ctx).Update(map[string]interface{}{
+		"password_try": gorm.Expr("password_try + ?", 1),
+	}, filters); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	user := &model.UsersModel{}
+	if err = model.NewUsersModel(tx).WithContext(ctx).QueryOne(filters, user); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	maxTry := uint(config.GetConfig("auth.lock.maxTry").MustInt(5))
+	if user.PasswordTry >= maxTry {
+		if _, err = model.NewUsersModel(tx).WithContext(ctx).Update(map[string]interface{}{
+			"locked_until": now.Add(lockDuration(user.PasswordTry - maxTry)),
+		}, filters); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	return user.PasswordTry, model.TxCommit(tx)
+}
+
+// lockDuration 第 n 次超出阈值时的锁定时长：base * 2^n，不超过 max
+func lockDuration(n uint) time.Duration {
+	base := time.Duration(config.GetConfig("auth.lock.baseMinutes").MustInt(1)) * time.Minute
+	max := time.Duration(config.GetConfig("auth.lock.maxMinutes").MustInt(1440)) * time.Minute
+	d := base
+	for i := uint(0); i < n && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// checkAccountExists 检查用户名和邮箱是否已被占用
+func checkAccountExists(ctx context.Context, userName, email string) *errno.ErrMsg {
+	users := model.NewUsersModel().WithContext(ctx)
+	count, err := users.Count(map[string]interface{}{"username = ?": userName})
+	if err != nil {
+		logs.ErrorCtx(ctx, "count user error: %v", err)
+		return errno.ErrQuery
+	}
+	if count > 0 {
+		return errno.ErrUserNameExistsError
+	}
+	if email == "" {
+		return nil
+	}
+	count, err = users.Count(map[string]interface{}{"email = ?": email})
+	if err != nil {
+		logs.ErrorCtx(ctx, "count user error: %v", err)
+		return errno.ErrQuery
+	}
+	if count > 0 {
+		return errno.ErrEmailExistsError
+	}
+	return nil
+}
+
+// normalizeEmail 校验邮箱格式并统一转为小写
+func normalizeEmail(email string) (string, bool) {
+	email = strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(email)
+	// 只接受纯地址，不接受 "Name <a@b.c>" 这种带显示名的格式
+	if err != nil || addr.Address != email || len(email) > 100 {
+		return "", false
+	}
+	at := strings.LastIndex(email, "@")
+	if !strings.Contains(email[at+1:], ".") {
+		return "", false
+	}
+	return strings.ToLower(email), true
+}
+
+// passwordStrong 密码 8-64 位，至少包含字母和数字
+func passwordStrong(plain string) bool {
+	if len(plain) < 8 || len(plain) > 64 {
+		return false
+	}
+	var letter, digit bool
+	for _, r := range plain {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return letter && digit
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash 用当前参数计算的假哈希，账号不存在时用于校验，计算失败时返回空
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		hash, err := password.Hash("dummy-password-for-timing", passwordParams())
+		if err != nil {
+			logs.Error("hash dummy password error: %v", err)
+			return
+		}
+		dummyHash = hash
+	})
+	return dummyHash
+}
+
+// passwordParams 读取 argon2id 参数配置
+func passwordParams() password.Params {
+	p := password.DefaultParams
+	p.Memory = uint32(config.GetConfig("auth.password.memory").MustUint(uint(p.Memory)))
+	p.Iterations = uint32(config.GetConfig("auth.password.iterations").MustUint(uint(p.Iterations)))
+	p.Parallelism = uint8(config.GetConfig("auth.password.parallelism").MustUint(uint(p.Parallelism)))
+	return p
+}
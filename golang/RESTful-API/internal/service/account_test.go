@@ -0,0 +1,165 @@
+package service
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/model/modeltest"
+	"RESTful-API/internal/password"
+	"context"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+	"time"
+)
+
+// useAccountDB 建好账号相关的表
+func useAccountDB(t *testing.T) {
+	t.Helper()
+	modeltest.Open(t, &model.UsersModel{}, &model.UserWalletsModel{}, &model.RefreshTokensModel{})
+}
+
+// createUser 直接写入一个带密码哈希的用户
+func createUser(t *testing.T, name, hash string) *model.UsersModel {
+	t.Helper()
+	user := &model.UsersModel{UserName: name, PasswordHash: hash}
+	if err := model.NewUsersModel().WithContext(context.Background()).Create(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func loadUser(t *testing.T, id int64) *model.UsersModel {
+	t.Helper()
+	user := &model.UsersModel{}
+	if err := model.NewUsersModel().WithContext(context.Background()).QueryOne(map[string]interface{}{"id = ?": id}, user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// expireLock 把锁定时间改到过去，模拟锁定期结束
+func expireLock(t *testing.T, id int64) {
+	t.Helper()
+	if _, err := model.NewUsersModel().WithContext(context.Background()).Update(map[string]interface{}{
+		"locked_until": time.Now().Add(-time.Second),
+	}, map[string]interface{}{"id = ?": id}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hashPassword(t *testing.T, plain string) string {
+	t.Helper()
+	hash, err := password.Hash(plain, passwordParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestPasswordLoginLockout(t *testing.T) {
+	useAccountDB(t)
+	ctx := context.Background()
+	user := createUser(t, "alice", hashPassword(t, "right password"))
+	login := func(pw string) *errno.ErrMsg {
+		_, e := PasswordLogin(ctx, &PasswordLoginReq{Account: "alice", Password: pw})
+		return e
+	}
+
+	// 前 4 次错误只返回密码错误，第 5 次开始锁定 1 分钟
+	for i := 1; i < 5; i++ {
+		if e := login("wrong"); e != errno.ErrPasswordError {
+			t.Fatalf("attempt %d: got %v, want ErrPasswordError", i, e)
+		}
+	}
+	if e := login("wrong"); e != errno.ErrAccountLockedError {
+		t.Fatalf("attempt 5: got %v, want ErrAccountLockedError", e)
+	}
+	locked := loadUser(t, user.ID)
+	if locked.PasswordTry != 5 || locked.LockedUntil == nil {
+		t.Fatalf("after 5 failures: try %d, locked until %v", locked.PasswordTry, locked.LockedUntil)
+	}
+	if d := time.Until(*locked.LockedUntil); d < 50*time.Second || d > time.Minute {
+		t.Fatalf("first lock lasts %v, want 1m", d)
+	}
+	// 锁定期内正确的密码也被拒绝，且不增加次数
+	if e := login("right password"); e != errno.ErrAccountLockedError {
+		t.Fatalf("correct password while locked: got %v", e)
+	}
+	if got := loadUser(t, user.ID).PasswordTry; got != 5 {
+		t.Fatalf("try %d after locked attempt, want 5", got)
+	}
+
+	// 锁定结束后再错一次，锁定时长翻倍
+	expireLock(t, user.ID)
+	if e := login("wrong"); e != errno.ErrAccountLockedError {
+		t.Fatalf("attempt 6: got %v, want ErrAccountLockedError", e)
+	}
+	if d := time.Until(*loadUser(t, user.ID).LockedUntil); d < 110*time.Second || d > 2*time.Minute {
+		t.Fatalf("second lock lasts %v, want 2m", d)
+	}
+
+	// 锁定结束后登录成功，次数和锁定时间清零
+	expireLock(t, user.ID)
+	result, e := PasswordLogin(ctx, &PasswordLoginReq{Account: "alice", Password: "right password"})
+	if e != nil || result.Token == nil {
+		t.Fatalf("login after lock: %+v, %v", result, e)
+	}
+	if reset := loadUser(t, user.ID); reset.PasswordTry != 0 || reset.LockedUntil != nil {
+		t.Fatalf("after login: try %d, locked until %v", reset.PasswordTry, reset.LockedUntil)
+	}
+}
+
+func TestPasswordLoginUnknownAccount(t *testing.T) {
+	useAccountDB(t)
+	ctx := context.Background()
+	createUser(t, "wallet-only", "")
+	for _, account := range []string{"nobody", "wallet-only", "nobody@example.com"} {
+		if _, e := PasswordLogin(ctx, &PasswordLoginReq{Account: account, Password: "pw"}); e != errno.ErrPasswordError {
+			t.Errorf("%s: got %v, want ErrPasswordError", account, e)
+		}
+	}
+}
+
+func TestPasswordLoginRehash(t *testing.T) {
+	useAccountDB(t)
+	ctx := context.Background()
+	legacy, err := bcrypt.GenerateFromPassword([]byte("old scheme"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weak := password.DefaultParams
+	weak.Memory, weak.Iterations = 1024, 1
+	weakHash, err := password.Hash("weak params", weak)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, plain, hash string
+	}{
+		{"bcrypt", "old scheme", string(legacy)},
+		{"argon2id with old params", "weak params", weakHash},
+	}
+	for _, tt := range tests {
+		user := createUser(t, tt.name, tt.hash)
+		if _, e := PasswordLogin(ctx, &PasswordLoginReq{Account: tt.name, Password: tt.plain}); e != nil {
+			t.Fatalf("%s: login: %v", tt.name, e)
+		}
+		stored := loadUser(t, user.ID).PasswordHash
+		if stored == tt.hash || password.NeedsRehash(stored, passwordParams()) {
+			t.Fatalf("%s: hash not upgraded: %s", tt.name, stored)
+		}
+		if err = password.Verify(tt.plain, stored); err != nil {
+			t.Fatalf("%s: upgraded hash does not verify: %v", tt.name, err)
+		}
+	}
+
+	// 已是当前参数的哈希不会被改写
+	current := hashPassword(t, "current")
+	user := createUser(t, "current", current)
+	if _, e := PasswordLogin(ctx, &PasswordLoginReq{Account: "current", Password: "current"}); e != nil {
+		t.Fatal(e)
+	}
+	if stored := loadUser(t, user.ID).PasswordHash; stored != current {
+		t.Fatal("hash with current params should not be rewritten")
+	}
+}
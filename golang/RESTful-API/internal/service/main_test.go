@@ -0,0 +1,15 @@
+package service
+
+import (
+	"github.com/spf13/viper"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// 测试中不加载 config.yaml，登录签发 token 使用测试专用的密钥
+	viper.Set("token.issuer", "test")
+	viper.Set("token.activeKid", "test")
+	viper.Set("token.keys", map[string]interface{}{"test": "service-test-signing-key"})
+	os.Exit(m.Run())
+}
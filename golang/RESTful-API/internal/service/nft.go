@@ -0,0 +1,251 @@
+package service
+
+import (
+	"RESTful-API/internal/auth"
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/lazymint"
+	"RESTful-API/internal/metadata"
+	"RESTful-API/internal/metrics"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/money"
+	"RESTful-API/utils/logs"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"mime/multipart"
+	"strings"
+	"time"
+)
+
+// weiDecimals 原生币 ETH 的精度
+const weiDecimals = 18
+
+// CreateNFTReq 创建 NFT 的表单，图片在 file 字段；attributes 为 JSON 数组，min_price 为 ETH 数量
+type CreateNFTReq struct {
+	Name        string `form:"name" binding:"required"`
+	Description string `form:"description"`
+	Attributes  string `form:"attributes"`
+	MinPrice    string `form:"min_price"`
+}
+
+// NftVoucher 懒铸造凭证，TypedData 为 eth_signTypedData_v4 格式，买家连同 Signature 提交给合约兑换
+type NftVoucher struct {
+	NftID     int64                  `json:"nft_id"`
+	Contract  string                 `json:"contract_address"`
+	TokenID   string                 `json:"token_id"`
+	MinPrice  string                 `json:"min_price"` // 单位 wei
+	URI       string                 `json:"uri"`
+	Creator   string                 `json:"creator"`
+	Signer    string                 `json:"signer"`
+	Signature string                 `json:"signature"`
+	Status    string                 `json:"status"`
+	TypedData map[string]interface{} `json:"typed_data"`
+}
+
+// CreatedNFT 创建的草稿 NFT
+type CreatedNFT struct {
+	Nft     *model.NftsModel `json:"nft"`
+	Image   *UploadedImage   `json:"image"`
+	Voucher *NftVoucher      `json:"voucher"`
+}
+
+// CreateNFT 懒铸造：保存图片，固定图片和元数据，写入没有 token_id 的草稿 NFT，并签发 EIP-712 铸造凭证。
+// 草稿的拥有者为创作者的 EVM 钱包，买家兑换凭证铸造后由索引器转为正式 NFT
+func CreateNFT(ctx context.Context, user *auth.CurrentUser, req *CreateNFTReq, file *multipart.FileHeader) (*CreatedNFT, *errno.ErrMsg) {
+	domain, signer, err := lazymint.FromConfig()
+	if errors.Is(err, lazymint.ErrDisabled) {
+		return nil, errno.ErrNFTCreateDisabledError
+	}
+	if err != nil {
+		logs.ErrorCtx(ctx, "lazy mint config error: %v", err)
+		return nil, errno.ErrServer
+	}
+	wallet := user.Wallet(constants.ChainEVM)
+	if wallet == nil {
+		return nil, errno.ErrWalletNotLinkedError
+	}
+	minPrice := money.NewFromInt(constants.DefaultZero)
+	if strings.TrimSpace(req.MinPrice) != "" {
+		if minPrice, err = money.Parse(req.MinPrice); err != nil || minPrice.Sign() < constants.DefaultZero {
+			return nil, errno.ErrParam
+		}
+	}
+	minPriceWei, err := minPrice.BaseUnits(weiDecimals)
+	if err != nil {
+		return nil, errno.ErrParam
+	}
+
+	img, e := processImage(ctx, file)
+	if e != nil {
+		return nil, e
+	}
+	pinner, err := metadata.PinnerFromConfig()
+	if err != nil {
+		logs.ErrorCtx(ctx, "metadata pinner error: %v", err)
+		return nil, errno.ErrServer
+	}
+	// 先校验元数据，内容不合法时不保存任何文件
+	doc, e := buildMetadata(req, "")
+	if e != nil {
+		return nil, e
+	}
+	uploaded, e := saveImage(ctx, img)
+	if e != nil {
+		return nil, e
+	}
+	imageURI, err := pinner.Pin(ctx, img.ContentType, img.Data)
+	if err != nil {
+		logs.ErrorCtx(ctx, "pin image error: %v", err)
+		return nil, errno.ErrUploadErrorError
+	}
+	if doc, e = buildMetadata(req, imageURI); e != nil {
+		return nil, e
+	}
+	metadataURI, err := pinner.Pin(ctx, "application/json", doc.Content)
+	if err != nil {
+		logs.ErrorCtx(ctx, "pin metadata error: %v", err)
+		return nil, errno.ErrUploadErrorError
+	}
+
+	nft, voucher, e := createDraft(ctx, user, wallet.WalletAddress, metadataURI, minPriceWei, domain, signer)
+	if e != nil {
+		return nil, e
+	}
+	// 元数据已经在本地，直接保存，不必等定时刷新抓取
+	if err = metadata.NewDBStore().Save(ctx, metadata.Result{
+		NftID: nft.ID, URI: metadataURI, Document: doc, FetchedAt: time.Now(),
+	}); err != nil {
+		logs.ErrorCtx(ctx, "save nft metadata error: %v", err)
+	}
+	metrics.NFTDrafted(constants.ChainEVM)
+	logs.With(logs.Int64("user", user.ID()), logs.Int64("nft", nft.ID), logs.String("token_id", voucher.TokenID)).Info("nft draft created")
+	return &CreatedNFT{Nft: nft, Image: uploaded, Voucher: newNftVoucher(domain, voucher)}, nil
+}
+
+// NftVoucherOf 查询草稿 NFT 的铸造凭证
+func NftVoucherOf(ctx context.Context, nftID int64) (*NftVoucher, *errno.ErrMsg) {
+	domain, _, err := lazymint.FromConfig()
+	if errors.Is(err, lazymint.ErrDisabled) {
+		return nil, errno.ErrNFTCreateDisabledError
+	}
+	if err != nil {
+		logs.ErrorCtx(ctx, "lazy mint config error: %v", err)
+		return nil, errno.ErrServer
+	}
+	voucher := &model.NftVouchersModel{}
+	if err = model.NewNftVouchersModel().WithContext(ctx).QueryOne(map[string]interface{}{"nft_id = ?": nftID}, voucher); err != nil {
+		logs.ErrorCtx(ctx, "query nft voucher error: %v", err)
+		return nil, errno.ErrQuery
+	}
+	if voucher.ID == constants.DefaultZero {
+		return nil, errno.ErrRecordNotFoundError
+	}
+	return newNftVoucher(domain, voucher), nil
+}
+
+// buildMetadata 生成并校验元数据 JSON，校验规则与抓取链上元数据时一致
+func buildMetadata(req *CreateNFTReq, image string) (*metadata.Document, *errno.ErrMsg) {
+	raw := map[string]interface{}{
+		"name":        req.Name,
+		"description": req.Description,
+		"image":       image,
+	}
+	if strings.TrimSpace(req.Attributes) != "" {
+		if !json.Valid([]byte(req.Attributes)) {
+			return nil, errno.ErrMetadataInvalidError
+		}
+		raw["attributes"] = json.RawMessage(req.Attributes)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, errno.ErrMetadataInvalidError
+	}
+	doc, err := metadata.Parse(data)
+	if err != nil {
+		return nil, errno.ErrMetadataInvalidError
+	}
+	return doc, nil
+}
+
+// createDraft 在一个事务中写入草稿 NFT 和铸造凭证；tokenId 由创作者地址和草稿 id 生成，所以要先写入草稿
+func createDraft(ctx context.Context, user *auth.CurrentUser, creator, metadataURI string, minPrice *big.Int,
+	domain lazymint.Domain, signer *lazymint.Signer) (*model.NftsModel, *model.NftVouchersModel, *errno.ErrMsg) {
+	tx, err := model.TxBegin()
+	if err != nil {
+		logs.ErrorCtx(ctx, "begin tx error: %v", err)
+		return nil, nil, errno.ErrServer
+	}
+
+	nft := &model.NftsModel{
+		ContractAddress:    domain.VerifyingContract,
+		OwnerWalletAddress: creator,
+		Chain:              string(chain.EVM),
+		MetadataURI:        metadataURI,
+		Status:             model.NftStatusUnlisted,
+	}
+	if err = model.NewNftsModel(tx).WithContext(ctx).Create(nft); err != nil {
+		tx.Rollback()
+		logs.ErrorCtx(ctx, "create nft draft error: %v", err)
+		return nil, nil, errno.ErrUpdate
+	}
+
+	tokenID, err := lazymint.TokenID(creator, nft.ID)
+	if err != nil {
+		tx.Rollback()
+		logs.ErrorCtx(ctx, "voucher token id error: %v", err)
+		return nil, nil, errno.ErrServer
+	}
+	signature, err := signer.Sign(domain, lazymint.Voucher{TokenID: tokenID, MinPrice: minPrice, URI: metadataURI, Creator: creator})
+	if err != nil {
+		tx.Rollback()
+		logs.ErrorCtx(ctx, "sign voucher error: %v", err)
+		return nil, nil, errno.ErrServer
+	}
+	voucher := &model.NftVouchersModel{
+		NftID:                nft.ID,
+		Chain:                string(chain.EVM),
+		ContractAddress:      domain.VerifyingContract,
+		TokenID:              tokenID.String(),
+		CreatorUserID:        user.ID(),
+		CreatorWalletAddress: creator,
+		MetadataURI:          metadataURI,
+		MinPrice:             minPrice.String(),
+		SignerAddress:        signer.Address(),
+		Signature:            signature,
+		Status:               model.NftVoucherStatusPending,
+	}
+	if err = model.NewNftVouchersModel(tx).WithContext(ctx).Create(voucher); err != nil {
+		tx.Rollback()
+		logs.ErrorCtx(ctx, "create nft voucher error: %v", err)
+		return nil, nil, errno.ErrUpdate
+	}
+	if err = model.TxCommit(tx); err != nil {
+		logs.ErrorCtx(ctx, "commit nft draft error: %v", err)
+		return nil, nil, errno.ErrUpdate
+	}
+	return nft, voucher, nil
+}
+
+// newNftVoucher 按当前的签名域生成返回给前端的凭证，verifyingContract 使用凭证签发时的合约
+func newNftVoucher(domain lazymint.Domain, v *model.NftVouchersModel) *NftVoucher {
+	domain.VerifyingContract = v.ContractAddress
+	tokenID, _ := new(big.Int).SetString(v.TokenID, 10)
+	minPrice, _ := new(big.Int).SetString(v.MinPrice, 10)
+	return &NftVoucher{
+		NftID:     v.NftID,
+		Contract:  v.ContractAddress,
+		TokenID:   v.TokenID,
+		MinPrice:  v.MinPrice,
+		URI:       v.MetadataURI,
+		Creator:   v.CreatorWalletAddress,
+		Signer:    v.SignerAddress,
+		Signature: v.Signature,
+		Status:    v.Status,
+		TypedData: lazymint.TypedData(domain, lazymint.Voucher{
+			TokenID: tokenID, MinPrice: minPrice, URI: v.MetadataURI, Creator: v.CreatorWalletAddress,
+		}),
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"RESTful-API/internal/constants"
+	"RESTful-API/utils/config"
+	"crypto/rand"
+	"math/big"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const nonceAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+var nonceRegExp = regexp.MustCompile(constants.DefaultNonceRegExp)
+
+// NonceStore 一次性 nonce 存储，签发后只能被消费一次
+type NonceStore interface {
+	Issue() (string, error)
+	Consume(nonce string) bool
+}
+
+// memoryNonceStore 进程内 nonce 存储
+type memoryNonceStore struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	nonces map[string]time.Time // nonce -> 过期时间
+}
+
+// Nonces 默认的 nonce 存储
+var Nonces NonceStore = NewMemoryNonceStore(nonceTTL())
+
+// NewMemoryNonceStore 创建进程内 nonce 存储
+func NewMemoryNonceStore(ttl time.Duration) NonceStore {
+	return &memoryNonceStore{ttl: ttl, nonces: make(map[string]time.Time)}
+}
+
+// Issue 生成一个新的 nonce
+func (s *memoryNonceStore) Issue() (string, error) {
+	nonce, err := randomNonce()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, expire := range s.nonces { // 顺便清理过期的 nonce
+		if now.After(expire) {
+			delete(s.nonces, k)
+		}
+	}
+	s.nonces[nonce] = now.Add(s.ttl)
+	return nonce, nil
+}
+
+// Consume 消费 nonce，不存在或已过期时返回 false
+func (s *memoryNonceStore) Consume(nonce string) bool {
+	if !nonceRegExp.MatchString(nonce) {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expire, ok := s.nonces[nonce]
+	if !ok {
+		return false
+	}
+	delete(s.nonces, nonce)
+	return time.Now().Before(expire)
+}
+
+// randomNonce 生成符合 DefaultNonceRegExp 的随机串
+func randomNonce() (string, error) {
+	buf := make([]byte, 16)
+	max := big.NewInt(int64(len(nonceAlphabet)))
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		buf[i] = nonceAlphabet[n.Int64()]
+	}
+	return string(buf), nil
+}
+
+// nonceTTL 读取 nonce 有效期配置，默认 5 分钟
+func nonceTTL() time.Duration {
+	seconds := config.GetConfig("auth.nonceTTL").MustInt(300)
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/rbac"
+	"RESTful-API/utils/logs"
+	"context"
+)
+
+// GrantRole 为用户分配角色，每个用户同一时间只有一个角色
+func GrantRole(ctx context.Context, userID int64, role rbac.Role) *errno.ErrMsg {
+	if _, ok := rbac.ParseRole(string(role)); !ok {
+		return errno.ErrParam
+	}
+	rows, err := model.NewUsersModel().WithContext(ctx).Update(map[string]interface{}{"auth_group": string(role)}, map[string]interface{}{"id = ?": userID})
+	if err != nil {
+		logs.ErrorCtx(ctx, "grant role error, user: %d, role: %s, err: %v", userID, role, err)
+		return errno.ErrUpdate
+	}
+	if rows == constants.DefaultZero {
+		// 角色没有变化时 MySQL 也会返回 0，需要区分用户是否存在
+		count, err := model.NewUsersModel().WithContext(ctx).Count(map[string]interface{}{"id = ?": userID})
+		if err != nil {
+			logs.ErrorCtx(ctx, "count user error: %v", err)
+			return errno.ErrQuery
+		}
+		if count == constants.DefaultZero {
+			return errno.ErrRecordNotFoundError
+		}
+	}
+	logs.InfoCtx(ctx, "user %d granted role %s", userID, role)
+	return nil
+}
+
+// RevokeRole 收回用户角色，恢复为普通用户
+func RevokeRole(ctx context.Context, userID int64) *errno.ErrMsg {
+	return GrantRole(ctx, userID, rbac.RoleUser)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/session"
+	"RESTful-API/utils/logs"
+	"context"
+	"errors"
+)
+
+// withToken 为登录成功的用户签发 token
+func withToken(ctx context.Context, result *LoginResult) (*LoginResult, *errno.ErrMsg) {
+	manager, err := session.Default()
+	if err != nil {
+		logs.ErrorCtx(ctx, "session manager init error: %v", err)
+		return nil, errno.ErrServer
+	}
+	result.Token, err = manager.Issue(ctx, result.User.ID)
+	if err != nil {
+		logs.ErrorCtx(ctx, "issue token error, user: %d, err: %v", result.User.ID, err)
+		return nil, errno.ErrServer
+	}
+	return result, nil
+}
+
+// RefreshToken 使用 refresh token 续签
+func RefreshToken(ctx context.Context, refreshToken string) (*session.TokenPair, *errno.ErrMsg) {
+	manager, err := session.Default()
+	if err != nil {
+		logs.ErrorCtx(ctx, "session manager init error: %v", err)
+		return nil, errno.ErrServer
+	}
+	pair, err := manager.Refresh(ctx, refreshToken)
+	if err != nil {
+		return nil, sessionErr(ctx, err)
+	}
+	return pair, nil
+}
+
+// Logout 退出登录，吊销当前会话的 refresh token
+func Logout(ctx context.Context, refreshToken string) *errno.ErrMsg {
+	manager, err := session.Default()
+	if err != nil {
+		logs.ErrorCtx(ctx, "session manager init error: %v", err)
+		return errno.ErrServer
+	}
+	if err = manager.Revoke(ctx, refreshToken); err != nil {
+		return sessionErr(ctx, err)
+	}
+	return nil
+}
+
+// sessionErr 将会话错误转换为接口错误码
+func sessionErr(ctx context.Context, err error) *errno.ErrMsg {
+	switch {
+	case errors.Is(err, session.ErrTokenReused):
+		logs.WarnCtx(ctx, "refresh token reuse detected, session family revoked")
+		return errno.ErrTokenVerificationError
+	case errors.Is(err, session.ErrTokenInvalid), errors.Is(err, session.ErrTokenExpired):
+		return errno.ErrTokenVerificationError
+	default:
+		logs.ErrorCtx(ctx, "session error: %v", err)
+		return errno.ErrServer
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"RESTful-API/internal/auth"
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/metrics"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/money"
+	"RESTful-API/internal/settlement"
+	"RESTful-API/utils/logs"
+	"context"
+	"errors"
+	"strings"
+)
+
+// 写入数据库的金额精度，与表中的 DECIMAL 列一致
+const (
+	nftPricePlaces   = 10 // nft_transactions.price DECIMAL(30,10)
+	swapAmountPlaces = 8  // swap_transactions.*_amount DECIMAL(18,8)
+)
+
+// SubmitTradeReq 提交 NFT 成交的交易哈希，price 为原生币数量
+type SubmitTradeReq struct {
+	Chain  string        `json:"chain" binding:"required"`
+	TxHash string        `json:"tx_hash" binding:"required"`
+	NftID  int64         `json:"nft_id" binding:"required"`
+	Buyer  string        `json:"buyer_wallet_address" binding:"required"`
+	Seller string        `json:"seller_wallet_address" binding:"required"`
+	Price  money.Decimal `json:"price"`
+}
+
+// SubmitSwapReq 提交 swap 的交易哈希，原生币的资产地址为空；数量不为 0 时必须与链上一致
+type SubmitSwapReq struct {
+	Chain      string        `json:"chain" binding:"required"`
+	TxHash     string        `json:"transaction_hash" binding:"required"`
+	FromAsset  string        `json:"from_asset_address"`
+	ToAsset    string        `json:"to_asset_address"`
+	FromAmount money.Decimal `json:"from_amount"`
+	ToAmount   money.Decimal `json:"to_amount"`
+}
+
+// SubmitTrade 通过链上交易校验 NFT 成交后写入 nft_transactions：
+// 提交者必须是买方或卖方，链上的买卖双方和成交价与提交的一致，且交易已达到所需确认数；写入的价格以链上为准
+func SubmitTrade(ctx context.Context, user *auth.CurrentUser, req *SubmitTradeReq) (*model.NftTransactionsModel, *errno.ErrMsg) {
+	c, ok := chain.Parse(req.Chain)
+	if !ok {
+		return nil, errno.ErrChainIDInvalidError
+	}
+	wallet := user.Wallet(req.Chain)
+	if wallet == nil {
+		return nil, errno.ErrWalletNotLinkedError
+	}
+	buyer, err := chain.NormalizeAddress(c, req.Buyer)
+	if err != nil {
+		return nil, errno.ErrAddressInvalidError
+	}
+	seller, err := chain.NormalizeAddress(c, req.Seller)
+	if err != nil {
+		return nil, errno.ErrAddressInvalidError
+	}
+	if wallet.WalletAddress != buyer && wallet.WalletAddress != seller {
+		return nil, errno.ErrHandleInvalid
+	}
+	if req.Price.Sign() <= constants.DefaultZero {
+		return nil, errno.ErrParam
+	}
+
+	nft := &model.NftsModel{}
+	if err = model.NewNftsModel().WithContext(ctx).QueryOne(map[string]interface{}{"id = ?": req.NftID}, nft); err != nil {
+		logs.ErrorCtx(ctx, "query nft error: %v", err)
+		return nil, errno.ErrQuery
+	}
+	if nft.ID == constants.DefaultZero {
+		return nil, errno.ErrRecordNotFoundError
+	}
+	if nft.Chain != req.Chain {
+		return nil, errno.ErrChainIDInvalidError
+	}
+
+	hash := canonicalTxHash(c, req.TxHash)
+	if e := checkTxHashUnused(ctx, model.NewNftTransactionsModel().WithContext(ctx), "tx_hash = ?", hash); e != nil {
+		return nil, e
+	}
+	client, e := settlementClient(ctx, c)
+	if e != nil {
+		return nil, e
+	}
+	trade, err := settlement.DecodeTrade(ctx, client, hash, nft.ContractAddress, nft.TokenID)
+	if e = settlementErr(ctx, c, hash, err); e != nil {
+		return nil, e
+	}
+	price := trade.Price.Round(nftPricePlaces)
+	if trade.Buyer != buyer || trade.Seller != seller || !req.Price.Round(nftPricePlaces).Equal(price) {
+		logs.WarnCtx(ctx, "%s trade %s mismatch, buyer: %s/%s, seller: %s/%s, price: %s/%s",
+			c, hash, trade.Buyer, buyer, trade.Seller, seller, trade.Price, req.Price)
+		return nil, errno.ErrTxMismatchError
+	}
+	// TON 可以提交 trace 中任意一笔交易的哈希，按解码出的哈希重新查重
+	if canonical := canonicalTxHash(c, trade.TxHash); canonical != hash {
+		hash = canonical
+		if e = checkTxHashUnused(ctx, model.NewNftTransactionsModel().WithContext(ctx), "tx_hash = ?", hash); e != nil {
+			return nil, e
+		}
+	}
+	if e = checkConfirmations(ctx, c, hash, trade.Confirmations); e != nil {
+		return nil, e
+	}
+
+	row := &model.NftTransactionsModel{
+		NftID:               nft.ID,
+		BuyerWalletAddress:  buyer,
+		SellerWalletAddress: seller,
+		Price:               price,
+		TxHash:              hash,
+		Chain:               req.Chain,
+		BlockNumber:         trade.BlockNumber,
+	}
+	if err = model.NewNftTransactionsModel().WithContext(ctx).Create(row); err != nil {
+		if model.IsUniqueErr(err) { // 并发提交时由 tx_hash 唯一索引兜底
+			return nil, errno.ErrHashSubmitRepeatError
+		}
+		logs.ErrorCtx(ctx, "create nft transaction error: %v", err)
+		return nil, errno.ErrUpdate
+	}
+	metrics.NFTSold(req.Chain, row.Price)
+	logs.InfoCtx(ctx, "user %d submitted %s trade %s, nft: %d, price: %s", user.ID(), c, hash, nft.ID, row.Price)
+	return row, nil
+}
+
+// SubmitSwap 通过链上交易解码当前用户钱包的 swap，达到所需确认数后按链上的资产和数量写入 swap_transactions
+func SubmitSwap(ctx context.Context, user *auth.CurrentUser, req *SubmitSwapReq) (*model.SwapTransactionsModel, *errno.ErrMsg) {
+	c, ok := chain.Parse(req.Chain)
+	if !ok {
+		return nil, errno.ErrChainIDInvalidError
+	}
+	wallet := user.Wallet(req.Chain)
+	if wallet == nil {
+		return nil, errno.ErrWalletNotLinkedError
+	}
+	fromAsset, toAsset := req.FromAsset, req.ToAsset
+	for _, asset := range []*string{&fromAsset, &toAsset} {
+		if *asset == settlement.NativeAsset {
+			continue
+		}
+		normalized, err := chain.NormalizeAddress(c, *asset)
+		if err != nil {
+			return nil, errno.ErrAddressInvalidError
+		}
+		*asset = normalized
+	}
+
+	hash := canonicalTxHash(c, req.TxHash)
+	if e := checkTxHashUnused(ctx, model.NewSwapTransactionsModel().WithContext(ctx), "transaction_hash = ?", hash); e != nil {
+		return nil, e
+	}
+	client, e := settlementClient(ctx, c)
+	if e != nil {
+		return nil, e
+	}
+	swap, err := settlement.DecodeSwap(ctx, client, hash, wallet.WalletAddress)
+	if e = settlementErr(ctx, c, hash, err); e != nil {
+		return nil, e
+	}
+	fromAmount, toAmount := swap.FromAmount.Round(swapAmountPlaces), swap.ToAmount.Round(swapAmountPlaces)
+	if swap.FromAsset != fromAsset || swap.ToAsset != toAsset ||
+		!req.FromAmount.IsZero() && !req.FromAmount.Round(swapAmountPlaces).Equal(fromAmount) ||
+		!req.ToAmount.IsZero() && !req.ToAmount.Round(swapAmountPlaces).Equal(toAmount) {
+		logs.WarnCtx(ctx, "%s swap %s mismatch, from: %s %s/%s %s, to: %s %s/%s %s", c, hash,
+			swap.FromAmount, swap.FromAsset, req.FromAmount, fromAsset, swap.ToAmount, swap.ToAsset, req.ToAmount, toAsset)
+		return nil, errno.ErrTxMismatchError
+	}
+	if e = checkConfirmations(ctx, c, hash, swap.Confirmations); e != nil {
+		return nil, e
+	}
+
+	row := &model.SwapTransactionsModel{
+		WalletID:         wallet.ID,
+		FromAssetAddress: swap.FromAsset,
+		ToAssetAddress:   swap.ToAsset,
+		FromAmount:       fromAmount,
+		ToAmount:         toAmount,
+		Chain:            req.Chain,
+		TransactionHash:  hash,
+		BlockNumber:      swap.BlockNumber,
+	}
+	if err = model.NewSwapTransactionsModel().WithContext(ctx).Create(row); err != nil {
+		if model.IsUniqueErr(err) {
+			return nil, errno.ErrHashSubmitRepeatError
+		}
+		logs.ErrorCtx(ctx, "create swap transaction error: %v", err)
+		return nil, errno.ErrUpdate
+	}
+	metrics.Swapped(req.Chain)
+	logs.InfoCtx(ctx, "user %d submitted %s swap %s, %s %s -> %s %s", user.ID(), c, hash, fromAmount, swap.FromAsset, toAmount, swap.ToAsset)
+	return row, nil
+}
+
+// canonicalTxHash EVM 的交易哈希不区分大小写，统一为小写后再查重和写入
+func canonicalTxHash(c chain.Chain, hash string) string {
+	hash = strings.TrimSpace(hash)
+	if c == chain.EVM {
+		return strings.ToLower(hash)
+	}
+	return hash
+}
+
+// checkTxHashUnused 交易哈希已经记录过时返回 ErrHashSubmitRepeatError
+func checkTxHashUnused(ctx context.Context, m *model.BaseModel, column, hash string) *errno.ErrMsg {
+	count, err := m.Count(map[string]interface{}{column: hash})
+	if err != nil {
+		logs.ErrorCtx(ctx, "count transaction hash error: %v", err)
+		return errno.ErrQuery
+	}
+	if count > constants.DefaultZero {
+		return errno.ErrHashSubmitRepeatError
+	}
+	return nil
+}
+
+func settlementClient(ctx context.Context, c chain.Chain) (chain.Client, *errno.ErrMsg) {
+	client, err := chain.Get(c)
+	if err != nil {
+		logs.ErrorCtx(ctx, "get %s chain client error: %v", c, err)
+		return nil, errno.ErrServer
+	}
+	return client, nil
+}
+
+// settlementErr 解码交易的错误转为接口错误
+func settlementErr(ctx context.Context, c chain.Chain, hash string, err error) *errno.ErrMsg {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, chain.ErrTxNotFound):
+		return errno.ErrHashNotExists
+	case errors.Is(err, settlement.ErrUnsupported):
+		return errno.ErrChainIDInvalidError
+	case errors.Is(err, settlement.ErrTxPending):
+		return errno.ErrTxPendingError
+	case errors.Is(err, settlement.ErrTxFailed), errors.Is(err, settlement.ErrNoTrade),
+		errors.Is(err, settlement.ErrNoSwap), errors.Is(err, settlement.ErrBundle),
+		errors.Is(err, settlement.ErrPaymentToken), errors.Is(err, chain.ErrNotFound):
+		return errno.ErrTxMismatchError
+	}
+	logs.ErrorCtx(ctx, "decode %s transaction %s error: %v", c, hash, err)
+	return errno.ErrQuery
+}
+
+// checkConfirmations 确认数未达到 chain.ini 中的要求时返回 ErrTxPendingError，客户端稍后重新提交
+func checkConfirmations(ctx context.Context, c chain.Chain, hash string, confirmations uint64) *errno.ErrMsg {
+	required := chain.EndpointFromConfig(c).Confirmations
+	if confirmations < required {
+		logs.InfoCtx(ctx, "%s transaction %s has %d/%d confirmations", c, hash, confirmations, required)
+		return errno.ErrTxPendingError
+	}
+	return nil
+}
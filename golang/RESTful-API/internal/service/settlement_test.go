@@ -0,0 +1,175 @@
+package service
+
+import (
+	"RESTful-API/internal/auth"
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/evm/evmtest"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/indexer"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/model/modeltest"
+	"RESTful-API/internal/money"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	evmSeller   = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+	evmMarket   = "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"
+	evmContract = "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb"
+	evmUSDC     = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+)
+
+// settlementFixture 替身 EVM 节点、注册好的客户端和绑定了 evmWallet 的用户
+type settlementFixture struct {
+	node *evmtest.Node
+	user *auth.CurrentUser
+	nft  *model.NftsModel
+}
+
+func newSettlementFixture(t *testing.T) *settlementFixture {
+	t.Helper()
+	modeltest.Open(t, &model.UsersModel{}, &model.UserWalletsModel{}, &model.NftsModel{},
+		&model.NftTransactionsModel{}, &model.SwapTransactionsModel{})
+	ctx := context.Background()
+	node := evmtest.NewNode(1)
+	t.Cleanup(node.Close)
+	chain.Register(evm.New(chain.Endpoint{URL: node.URL(), ChainID: 1, PollInterval: 10 * time.Millisecond}))
+	node.SetDecimals(evmUSDC, 6)
+	node.Mine(1)
+
+	user := createUser(t, "alice", "")
+	if err := model.NewUserWalletsModel().WithContext(ctx).Create(&model.UserWalletsModel{
+		UserID: user.ID, Chain: string(chain.EVM), WalletAddress: evmWallet,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nft := &model.NftsModel{ContractAddress: evmContract, OwnerWalletAddress: evmWallet, Chain: string(chain.EVM), TokenID: "7"}
+	if err := model.NewNftsModel().WithContext(ctx).Create(nft); err != nil {
+		t.Fatal(err)
+	}
+	return &settlementFixture{node: node, user: currentUser(t, user.ID), nft: nft}
+}
+
+func addressTopic(addr string) string {
+	return "0x" + strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(addr, "0x"))
+}
+
+// addSale evmWallet 用 1.5 ETH 从 evmSeller 买下 token 7
+func (f *settlementFixture) addSale(success bool) string {
+	return f.node.AddTransaction(evmWallet, evmMarket, big.NewInt(15e17), success, evm.Log{
+		Address: evmContract,
+		Topics:  []string{indexer.TopicTransfer, addressTopic(evmSeller), addressTopic(evmWallet), fmt.Sprintf("0x%064x", 7)},
+	})
+}
+
+// addSwap evmWallet 用 1 ETH 换到 3000 USDC
+func (f *settlementFixture) addSwap(success bool) string {
+	return f.node.AddTransaction(evmWallet, evmMarket, big.NewInt(1e18), success, evm.Log{
+		Address: evmUSDC,
+		Topics:  []string{indexer.TopicTransfer, addressTopic(evmMarket), addressTopic(evmWallet)},
+		Data:    big.NewInt(3000e6).FillBytes(make([]byte, 32)),
+	})
+}
+
+func (f *settlementFixture) tradeReq(hash, price string) *SubmitTradeReq {
+	return &SubmitTradeReq{
+		Chain: string(chain.EVM), TxHash: hash, NftID: f.nft.ID,
+		Buyer: evmWallet, Seller: evmSeller, Price: money.MustParse(price),
+	}
+}
+
+func (f *settlementFixture) swapReq(hash, to string) *SubmitSwapReq {
+	return &SubmitSwapReq{Chain: string(chain.EVM), TxHash: hash, ToAsset: to}
+}
+
+func TestSubmitTrade(t *testing.T) {
+	f := newSettlementFixture(t)
+	ctx := context.Background()
+	sale := f.addSale(true)
+	failed := f.addSale(false)
+	pending := f.node.AddPendingTransaction(evmWallet, evmMarket, big.NewInt(15e17))
+	// chain.ini 要求 12 个确认，出块前只有 1 个
+	if _, e := SubmitTrade(ctx, f.user, f.tradeReq(sale, "1.5")); e != errno.ErrTxPendingError {
+		t.Fatalf("1 confirmation: got %v, want ErrTxPendingError", e)
+	}
+	f.node.Mine(11)
+
+	tests := []struct {
+		name string
+		req  *SubmitTradeReq
+		want *errno.ErrMsg
+	}{
+		{"price", f.tradeReq(sale, "1.4"), errno.ErrTxMismatchError},
+		{"seller", &SubmitTradeReq{Chain: string(chain.EVM), TxHash: sale, NftID: f.nft.ID, Buyer: evmWallet, Seller: evmMarket, Price: money.MustParse("1.5")}, errno.ErrTxMismatchError},
+		{"failed", f.tradeReq(failed, "1.5"), errno.ErrTxMismatchError},
+		{"pending", f.tradeReq(pending, "1.5"), errno.ErrTxPendingError},
+		{"unknown", f.tradeReq("0x"+strings.Repeat("ab", 32), "1.5"), errno.ErrHashNotExists},
+	}
+	for _, tt := range tests {
+		if _, e := SubmitTrade(ctx, f.user, tt.req); e != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, e, tt.want)
+		}
+	}
+
+	row, e := SubmitTrade(ctx, f.user, f.tradeReq(sale, "1.5"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if row.TxHash != sale || row.BuyerWalletAddress != evmWallet || row.SellerWalletAddress != evmSeller ||
+		!row.Price.Equal(money.MustParse("1.5")) || row.BlockNumber != 1 {
+		t.Fatalf("unexpected row %+v", row)
+	}
+	// 哈希大小写不同也视为同一笔交易
+	if _, e = SubmitTrade(ctx, f.user, f.tradeReq("0x"+strings.ToUpper(sale[2:]), "1.5")); e != errno.ErrHashSubmitRepeatError {
+		t.Fatalf("duplicate: got %v, want ErrHashSubmitRepeatError", e)
+	}
+}
+
+func TestSubmitSwap(t *testing.T) {
+	f := newSettlementFixture(t)
+	ctx := context.Background()
+	swap := f.addSwap(true)
+	failed := f.addSwap(false)
+	pending := f.node.AddPendingTransaction(evmWallet, evmMarket, big.NewInt(1e18))
+	if _, e := SubmitSwap(ctx, f.user, f.swapReq(swap, evmUSDC)); e != errno.ErrTxPendingError {
+		t.Fatalf("1 confirmation: got %v, want ErrTxPendingError", e)
+	}
+	f.node.Mine(11)
+
+	tests := []struct {
+		name string
+		req  *SubmitSwapReq
+		want *errno.ErrMsg
+	}{
+		{"asset", f.swapReq(swap, evmContract), errno.ErrTxMismatchError},
+		{"amount", &SubmitSwapReq{Chain: string(chain.EVM), TxHash: swap, ToAsset: evmUSDC, ToAmount: money.MustParse("2999")}, errno.ErrTxMismatchError},
+		{"failed", f.swapReq(failed, evmUSDC), errno.ErrTxMismatchError},
+		{"pending", f.swapReq(pending, evmUSDC), errno.ErrTxPendingError},
+		{"asset address", f.swapReq(swap, "0x1234"), errno.ErrAddressInvalidError},
+	}
+	for _, tt := range tests {
+		if _, e := SubmitSwap(ctx, f.user, tt.req); e != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, e, tt.want)
+		}
+	}
+
+	req := f.swapReq(swap, strings.ToLower(evmUSDC))
+	req.FromAmount = money.MustParse("1")
+	row, e := SubmitSwap(ctx, f.user, req)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if row.TransactionHash != swap || row.WalletID != f.user.Wallet(string(chain.EVM)).ID || row.FromAssetAddress != "" ||
+		row.ToAssetAddress != evmUSDC || !row.FromAmount.Equal(money.MustParse("1")) || !row.ToAmount.Equal(money.MustParse("3000")) {
+		t.Fatalf("unexpected row %+v", row)
+	}
+	if _, e = SubmitSwap(ctx, f.user, f.swapReq(swap, evmUSDC)); e != errno.ErrHashSubmitRepeatError {
+		t.Fatalf("duplicate: got %v, want ErrHashSubmitRepeatError", e)
+	}
+}
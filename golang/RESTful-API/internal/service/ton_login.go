@@ -0,0 +1,131 @@
+// Package service 业务逻辑层，供 handler 调用
+package service
+
+import (
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/metrics"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/session"
+	"RESTful-API/internal/verifier"
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/logs"
+	"context"
+	"time"
+)
+
+// LoginResult 登录成功后返回给前端的信息
+type LoginResult struct {
+	User   *model.UsersModel       `json:"user"`
+	Wallet *model.UserWalletsModel `json:"wallet,omitempty"`
+	Token  *session.TokenPair      `json:"token"`
+}
+
+// TonPayload 签发 ton_proof 使用的一次性 payload
+func TonPayload() (string, *errno.ErrMsg) {
+	payload, err := Nonces.Issue()
+	if err != nil {
+		logs.Error("issue ton payload error: %v", err)
+		return "", errno.ErrServer
+	}
+	return payload, nil
+}
+
+// TonLogin 校验 ton_proof 并登录，钱包首次登录时自动注册用户
+func TonLogin(ctx context.Context, proof *verifier.TonProof) (*LoginResult, *errno.ErrMsg) {
+	// payload 必须是本服务签发且未使用过的 nonce，它包含在签名消息中，签名校验通过即说明钱包签的就是这个 nonce
+	if !Nonces.Consume(proof.Proof.Payload) {
+		return nil, errno.ErrTonLoginAuthError
+	}
+
+	addr, _, err := verifier.VerifyTonProof(proof, tonProofOptions())
+	if err != nil {
+		logs.WarnCtx(ctx, "ton proof verify failed, address: %s, err: %v", proof.Address, err)
+		return nil, errno.ErrTonLoginAuthError
+	}
+
+	result, e := loginByWallet(ctx, constants.ChainTON, addr.StringRaw())
+	if e != nil {
+		return nil, e
+	}
+	metrics.Login(metrics.LoginWallet, constants.ChainTON)
+	return withToken(ctx, result)
+}
+
+// tonProofOptions 按配置组装 ton_proof 校验参数。
+// payload 已由调用方通过 Nonces.Consume 校验，这里不再重复比较
+func tonProofOptions() verifier.TonProofOptions {
+	return verifier.TonProofOptions{
+		Domains: config.GetConfig("auth.ton.domains").Strings(","),
+		TTL:     time.Duration(config.GetConfig("auth.ton.proofTTL").MustInt(900)) * time.Second,
+		Network: config.GetConfig("auth.ton.network").MustString(verifier.TonNetworkMainnet),
+	}
+}
+
+// loginByWallet 根据链上地址查找用户，不存在时创建用户并绑定钱包
+func loginByWallet(ctx context.Context, chain, walletAddress string) (*LoginResult, *errno.ErrMsg) {
+	wallet := &model.UserWalletsModel{}
+	err := model.NewUserWalletsModel().WithContext(ctx).QueryOne(map[string]interface{}{
+		"chain = ?":          chain,
+		"wallet_address = ?": walletAddress,
+	}, wallet)
+	if err != nil {
+		logs.ErrorCtx(ctx, "query user wallet error: %v", err)
+		return nil, errno.ErrQuery
+	}
+
+	if wallet.ID > constants.DefaultZero {
+		user := &model.UsersModel{}
+		if err = model.NewUsersModel().WithContext(ctx).QueryOne(map[string]interface{}{"id = ?": wallet.UserID}, user); err != nil {
+			logs.ErrorCtx(ctx, "query user error: %v", err)
+			return nil, errno.ErrQuery
+		}
+		if user.ID == constants.DefaultZero {
+			return nil, errno.ErrRecordNotFoundError
+		}
+		return &LoginResult{User: user, Wallet: wallet}, nil
+	}
+
+	return registerByWallet(ctx, chain, walletAddress)
+}
+
+// registerByWallet 在一个事务内创建用户和钱包记录
+func registerByWallet(ctx context.Context, chain, walletAddress string) (*LoginResult, *errno.ErrMsg) {
+	tx, err := model.TxBegin()
+	if err != nil {
+		logs.ErrorCtx(ctx, "begin tx error: %v", err)
+		return nil, errno.ErrServer
+	}
+
+	user := &model.UsersModel{UserName: walletUserName(chain, walletAddress)}
+	if err = model.NewUsersModel(tx).WithContext(ctx).Create(user); err != nil {
+		tx.Rollback()
+		logs.ErrorCtx(ctx, "create user error: %v", err)
+		return nil, errno.ErrUpdate
+	}
+
+	wallet := &model.UserWalletsModel{UserID: user.ID, Chain: chain, WalletAddress: walletAddress}
+	if err = model.NewUserWalletsModel(tx).WithContext(ctx).Create(wallet); err != nil {
+		tx.Rollback()
+		if model.IsUniqueErr(err) {
+			return nil, errno.ErrAddressSubmitRepeatError
+		}
+		logs.ErrorCtx(ctx, "create user wallet error: %v", err)
+		return nil, errno.ErrUpdate
+	}
+
+	if err = model.TxCommit(tx); err != nil {
+		logs.ErrorCtx(ctx, "commit tx error: %v", err)
+		return nil, errno.ErrServer
+	}
+	return &LoginResult{User: user, Wallet: wallet}, nil
+}
+
+// walletUserName 钱包注册用户的默认用户名，格式为 链_地址，超长时截断
+func walletUserName(chain, walletAddress string) string {
+	name := chain + "_" + walletAddress
+	if len(name) > 50 { // users.username 最长 50
+		name = name[:50]
+	}
+	return name
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"RESTful-API/internal/auth"
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/storage"
+	"RESTful-API/internal/upload"
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/logs"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"strconv"
+)
+
+// UploadedThumb 缩略图的访问地址，Size 为配置的最长边
+type UploadedThumb struct {
+	Size   int    `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// UploadedImage 上传后的图片
+type UploadedImage struct {
+	Key         string          `json:"key"`
+	URL         string          `json:"url"`
+	ContentType string          `json:"content_type"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Size        int             `json:"size"`
+	Hash        string          `json:"hash"` // 处理后文件的 sha256
+	Thumbs      []UploadedThumb `json:"thumbs"`
+}
+
+// UploadMaxSize 上传文件的最大字节数，见 upload.ini
+func UploadMaxSize() int64 {
+	return config.GetConfig("upload.maxSize").MustInt64(10 << 20)
+}
+
+// uploadOptions 读取 upload.ini 中的图片限制
+func uploadOptions() upload.Options {
+	var sizes []int
+	for _, s := range config.GetConfig("upload.thumbSizes").Strings(",") {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			sizes = append(sizes, n)
+		}
+	}
+	return upload.Options{
+		MaxSize:    UploadMaxSize(),
+		MaxWidth:   config.GetConfig("upload.maxWidth").MustInt(8000),
+		MaxHeight:  config.GetConfig("upload.maxHeight").MustInt(8000),
+		MaxFrames:  config.GetConfig("upload.maxFrames").MustInt(300),
+		MaxPixels:  config.GetConfig("upload.maxPixels").MustInt64(100000000),
+		ThumbSizes: sizes,
+		Quality:    config.GetConfig("upload.jpegQuality").MustInt(90),
+	}
+}
+
+// UploadImage 校验、处理并保存上传的图片
+func UploadImage(ctx context.Context, user *auth.CurrentUser, file *multipart.FileHeader) (*UploadedImage, *errno.ErrMsg) {
+	img, e := processImage(ctx, file)
+	if e != nil {
+		return nil, e
+	}
+	out, e := saveImage(ctx, img)
+	if e != nil {
+		return nil, e
+	}
+	logs.With(logs.Int64("user", user.ID()), logs.String("key", out.Key), logs.Int("size", out.Size)).Info("image uploaded")
+	return out, nil
+}
+
+// processImage 读取上传的文件，校验类型、大小和尺寸后重新编码并生成缩略图
+func processImage(ctx context.Context, file *multipart.FileHeader) (*upload.Image, *errno.ErrMsg) {
+	maxSize := UploadMaxSize()
+	if file.Size > maxSize {
+		return nil, errno.ErrFileTooLargeError
+	}
+	f, err := file.Open()
+	if err != nil {
+		logs.ErrorCtx(ctx, "open upload file error: %v", err)
+		return nil, errno.ErrUploadErrorError
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		logs.ErrorCtx(ctx, "read upload file error: %v", err)
+		return nil, errno.ErrUploadErrorError
+	}
+
+	img, err := upload.Process(data, uploadOptions())
+	switch {
+	case errors.Is(err, upload.ErrTooLarge):
+		return nil, errno.ErrFileTooLargeError
+	case errors.Is(err, upload.ErrUnsupportedType), errors.Is(err, upload.ErrMalformed):
+		return nil, errno.ErrFileTypeError
+	case errors.Is(err, upload.ErrDimensions):
+		return nil, errno.ErrImageDimensionError
+	case err != nil:
+		logs.ErrorCtx(ctx, "process image error: %v", err)
+		return nil, errno.ErrUploadErrorError
+	}
+	return img, nil
+}
+
+// saveImage 保存处理后的图片。
+// 文件按处理后内容的哈希命名，相同的图片只保存一份；缩略图在原文件名后加 _thumb_<尺寸>
+func saveImage(ctx context.Context, img *upload.Image) (*UploadedImage, *errno.ErrMsg) {
+	store, err := storage.Default()
+	if err != nil {
+		logs.ErrorCtx(ctx, "storage error: %v", err)
+		return nil, errno.ErrUploadErrorError
+	}
+	sum := sha256.Sum256(img.Data)
+	hash := hex.EncodeToString(sum[:])
+	name := "images/" + hash[:2] + "/" + hash
+	out := &UploadedImage{
+		Key:         name + img.Ext,
+		ContentType: img.ContentType,
+		Width:       img.Width,
+		Height:      img.Height,
+		Size:        len(img.Data),
+		Hash:        hash,
+		Thumbs:      make([]UploadedThumb, 0, len(img.Thumbs)),
+	}
+	for _, t := range img.Thumbs {
+		key := name + constants.UploadGoodsThumbImageSuffix + "_" + strconv.Itoa(t.Size) + t.Ext
+		if err = store.Put(ctx, key, t.ContentType, t.Data); err != nil {
+			logs.ErrorCtx(ctx, "store thumb %s error: %v", key, err)
+			return nil, errno.ErrUploadErrorError
+		}
+		out.Thumbs = append(out.Thumbs, UploadedThumb{Size: t.Size, Width: t.Width, Height: t.Height, URL: store.URL(key)})
+	}
+	// 原图最后写入，原图存在即表示缩略图也已经写好
+	if err = store.Put(ctx, out.Key, img.ContentType, img.Data); err != nil {
+		logs.ErrorCtx(ctx, "store image %s error: %v", out.Key, err)
+		return nil, errno.ErrUploadErrorError
+	}
+	out.URL = store.URL(out.Key)
+	return out, nil
+}
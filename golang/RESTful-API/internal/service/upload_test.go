@@ -0,0 +1,120 @@
+package service
+
+import (
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/storage"
+	"RESTful-API/internal/storage/storagetest"
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+// fileHeader 按浏览器提交表单的方式构造上传的文件
+func fileHeader(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	w.Close()
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+func pngData(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// useS3 把默认存储替换为 storagetest.S3，测试结束后恢复
+func useS3(t *testing.T) *storagetest.S3 {
+	t.Helper()
+	fake := storagetest.NewS3("uploads")
+	t.Cleanup(fake.Close)
+	storage.Register(fake.Client())
+	t.Cleanup(func() { storage.Register(nil) })
+	return fake
+}
+
+func TestUploadImage(t *testing.T) {
+	fake := useS3(t)
+	ctx := context.Background()
+
+	// 客户端声称的文件名不影响识别的类型
+	img, e := processImage(ctx, fileHeader(t, "photo.jpg", pngData(t, 600, 300)))
+	if e != nil {
+		t.Fatal(e)
+	}
+	out, e := saveImage(ctx, img)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if !strings.HasPrefix(out.Key, "images/"+out.Hash[:2]+"/"+out.Hash) || !strings.HasSuffix(out.Key, ".png") ||
+		out.URL != fake.URL()+"/uploads/"+out.Key {
+		t.Fatalf("key %s, url %s", out.Key, out.URL)
+	}
+	if data, contentType, ok := fake.Object(out.Key); !ok || contentType != "image/png" || len(data) != out.Size {
+		t.Fatalf("stored %d bytes of %q, %v", len(data), contentType, ok)
+	}
+	// upload.ini 中配置了 256 和 512 两种缩略图
+	if len(out.Thumbs) != 2 || out.Thumbs[0].Width != 256 || out.Thumbs[1].Height != 256 {
+		t.Fatalf("thumbs %+v", out.Thumbs)
+	}
+	for _, th := range out.Thumbs {
+		key := strings.TrimPrefix(th.URL, fake.URL()+"/uploads/")
+		if !strings.Contains(key, "_thumb_") {
+			t.Fatalf("thumb key %s", key)
+		}
+		if _, _, ok := fake.Object(key); !ok {
+			t.Fatalf("thumb %s not stored", key)
+		}
+	}
+
+	// 相同内容只保存一份
+	again, _ := saveImage(ctx, img)
+	if again.Key != out.Key || fake.Len() != 3 {
+		t.Fatalf("key %s, %d objects", again.Key, fake.Len())
+	}
+}
+
+func TestUploadImageErrors(t *testing.T) {
+	ctx := context.Background()
+	if _, e := processImage(ctx, fileHeader(t, "a.png", []byte("<svg></svg>"))); e != errno.ErrFileTypeError {
+		t.Fatalf("got %v, want ErrFileTypeError", e)
+	}
+
+	// 存储拒绝写入时返回上传失败
+	fake := useS3(t)
+	opts := fake.Options()
+	opts.SecretKey = "wrong"
+	s, err := storage.NewS3(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Register(s)
+	img, e := processImage(ctx, fileHeader(t, "a.png", pngData(t, 8, 8)))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, e = saveImage(ctx, img); e != errno.ErrUploadErrorError {
+		t.Fatalf("got %v, want ErrUploadErrorError", e)
+	}
+	if n := fake.Len(); n != 0 {
+		t.Fatalf("got %d objects, want 0", n)
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"RESTful-API/internal/auth"
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/model"
+	"RESTful-API/utils/logs"
+	"context"
+)
+
+// LoadCurrentUser 加载用户及其绑定的钱包，用于鉴权中间件
+func LoadCurrentUser(ctx context.Context, userID int64) (*auth.CurrentUser, *errno.ErrMsg) {
+	user := &model.UsersModel{}
+	if err := model.NewUsersModel().WithContext(ctx).QueryOne(map[string]interface{}{"id = ?": userID}, user); err != nil {
+		logs.ErrorCtx(ctx, "query user error: %v", err)
+		return nil, errno.ErrQuery
+	}
+	if user.ID == constants.DefaultZero {
+		return nil, errno.ErrServerLoginAuthError
+	}
+
+	var wallets []*model.UserWalletsModel
+	if err := model.NewUserWalletsModel().WithContext(ctx).ListNoPage(map[string]interface{}{"user_id = ?": userID}, &wallets, "id ASC"); err != nil {
+		logs.ErrorCtx(ctx, "query user wallets error: %v", err)
+		return nil, errno.ErrQuery
+	}
+	return &auth.CurrentUser{User: user, Wallets: wallets}, nil
+}
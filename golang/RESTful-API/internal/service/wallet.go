@@ -0,0 +1,193 @@
+package service
+
+import (
+	"RESTful-API/internal/auth"
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/model"
+	"RESTful-API/internal/verifier"
+	"RESTful-API/utils/logs"
+	"context"
+	"fmt"
+)
+
+// WalletChallenge 绑定或解绑钱包前需要签名的内容
+type WalletChallenge struct {
+	Nonce   string `json:"nonce"`   // TON 钱包作为 ton_proof 的 payload 使用
+	Message string `json:"message"` // EVM / Solana 钱包需要签名的原文
+}
+
+// WalletProof 钱包所有权证明
+type WalletProof struct {
+	Chain     string             `json:"chain" binding:"required"`
+	Address   string             `json:"address" binding:"required"`
+	Nonce     string             `json:"nonce"`
+	Signature string             `json:"signature"` // EVM 为 0x 开头的 hex，Solana 为 base58
+	TonProof  *verifier.TonProof `json:"ton_proof"` // 仅 TON 使用
+}
+
+// UnlinkWalletReq 解绑钱包需要重新验证身份：提供账号密码，或者用要解绑的钱包重新签名
+type UnlinkWalletReq struct {
+	Password string       `json:"password"`
+	Proof    *WalletProof `json:"proof"`
+}
+
+// WalletNonce 签发绑定钱包使用的 nonce 和签名原文
+func WalletNonce(ctx context.Context, user *auth.CurrentUser, chainName, address string) (*WalletChallenge, *errno.ErrMsg) {
+	c, ok := chain.Parse(chainName)
+	if !ok {
+		return nil, errno.ErrChainIDInvalidError
+	}
+	address, err := chain.NormalizeAddress(c, address)
+	if err != nil {
+		return nil, errno.ErrAddressInvalidError
+	}
+	nonce, err := Nonces.Issue()
+	if err != nil {
+		logs.ErrorCtx(ctx, "issue wallet nonce error: %v", err)
+		return nil, errno.ErrServer
+	}
+	return &WalletChallenge{Nonce: nonce, Message: walletMessage(user.ID(), c, address, nonce)}, nil
+}
+
+// ListWallets 当前用户绑定的钱包列表
+func ListWallets(user *auth.CurrentUser) []*model.UserWalletsModel {
+	if user.Wallets == nil {
+		return []*model.UserWalletsModel{}
+	}
+	return user.Wallets
+}
+
+// LinkWallet 校验钱包签名后绑定到当前用户，每条链只能绑定一个钱包
+func LinkWallet(ctx context.Context, user *auth.CurrentUser, proof *WalletProof) (*model.UserWalletsModel, *errno.ErrMsg) {
+	if user.Wallet(proof.Chain) != nil {
+		return nil, errno.ErrWalletChainBoundError
+	}
+
+	address, e := verifyWalletProof(ctx, user.ID(), proof)
+	if e != nil {
+		return nil, e
+	}
+
+	// 同一个地址已经被其他用户绑定
+	count, err := model.NewUserWalletsModel().WithContext(ctx).Count(map[string]interface{}{
+		"chain = ?":          proof.Chain,
+		"wallet_address = ?": address,
+	})
+	if err != nil {
+		logs.ErrorCtx(ctx, "count user wallet error: %v", err)
+		return nil, errno.ErrQuery
+	}
+	if count > 0 {
+		return nil, errno.ErrAddressSubmitRepeatError
+	}
+
+	wallet := &model.UserWalletsModel{UserID: user.ID(), Chain: proof.Chain, WalletAddress: address}
+	if err = model.NewUserWalletsModel().WithContext(ctx).Create(wallet); err != nil {
+		// 并发绑定时由 (user_id, chain) 和 (chain, wallet_address) 两个唯一索引兜底
+		if model.IsUniqueErr(err) {
+			return nil, errno.ErrAddressSubmitRepeatError
+		}
+		logs.ErrorCtx(ctx, "create user wallet error: %v", err)
+		return nil, errno.ErrUpdate
+	}
+	logs.InfoCtx(ctx, "user %d linked %s wallet %s", user.ID(), proof.Chain, address)
+	return wallet, nil
+}
+
+// UnlinkWallet 解绑当前用户在指定链上的钱包
+func UnlinkWallet(ctx context.Context, user *auth.CurrentUser, chainName string, req *UnlinkWalletReq) *errno.ErrMsg {
+	wallet := user.Wallet(chainName)
+	if wallet == nil {
+		return errno.ErrWalletNotLinkedError
+	}
+	// 没有密码且只剩这一个钱包时，解绑后将无法再登录
+	if user.User.PasswordHash == constants.DefaultEmptyString && len(user.Wallets) <= constants.DefaultOne {
+		return errno.ErrWalletLastLoginMethodError
+	}
+
+	if e := reauthenticate(ctx, user, wallet, req); e != nil {
+		return e
+	}
+
+	if err := model.NewUserWalletsModel().WithContext(ctx).Delete(map[string]interface{}{
+		"id = ?":      wallet.ID,
+		"user_id = ?": user.ID(),
+	}, &model.UserWalletsModel{}); err != nil {
+		logs.ErrorCtx(ctx, "delete user wallet error: %v", err)
+		return errno.ErrUpdate
+	}
+	logs.InfoCtx(ctx, "user %d unlinked %s wallet %s", user.ID(), chainName, wallet.WalletAddress)
+	return nil
+}
+
+// reauthenticate 解绑前重新验证身份
+func reauthenticate(ctx context.Context, user *auth.CurrentUser, wallet *model.UserWalletsModel, req *UnlinkWalletReq) *errno.ErrMsg {
+	if req.Password != "" {
+		if user.User.PasswordHash == constants.DefaultEmptyString {
+			return errno.ErrPasswordError
+		}
+		// 与密码登录共用失败计数和锁定，避免通过解绑接口绕过锁定暴力猜测密码
+		return checkPassword(ctx, user.User, req.Password)
+	}
+
+	if req.Proof == nil || req.Proof.Chain != wallet.Chain {
+		return errno.ErrSignVerifyError
+	}
+	address, e := verifyWalletProof(ctx, user.ID(), req.Proof)
+	if e != nil {
+		return e
+	}
+	if !chain.SameAddress(chain.Chain(wallet.Chain), address, wallet.WalletAddress) {
+		return errno.ErrSignVerifyError
+	}
+	return nil
+}
+
+// verifyWalletProof 按链校验钱包签名，返回规范化后的地址
+func verifyWalletProof(ctx context.Context, userID int64, proof *WalletProof) (string, *errno.ErrMsg) {
+	c, ok := chain.Parse(proof.Chain)
+	if !ok {
+		return "", errno.ErrChainIDInvalidError
+	}
+
+	switch c {
+	case chain.TON:
+		if proof.TonProof == nil || !Nonces.Consume(proof.TonProof.Proof.Payload) {
+			return "", errno.ErrSignVerifyError
+		}
+		addr, _, err := verifier.VerifyTonProof(proof.TonProof, tonProofOptions())
+		if err != nil || !chain.SameAddress(c, addr.StringRaw(), proof.Address) {
+			logs.WarnCtx(ctx, "ton wallet proof verify failed, address: %s, err: %v", proof.Address, err)
+			return "", errno.ErrSignVerifyError
+		}
+		return addr.StringRaw(), nil
+
+	default: // chain.EVM, chain.Solana
+		address, err := chain.NormalizeAddress(c, proof.Address)
+		if err != nil {
+			return "", errno.ErrAddressInvalidError
+		}
+		if !Nonces.Consume(proof.Nonce) {
+			return "", errno.ErrSignVerifyError
+		}
+		// 签名原文中的地址为 WalletNonce 返回的规范格式
+		message := walletMessage(userID, c, address, proof.Nonce)
+		if c == chain.EVM {
+			err = verifier.VerifyEVMPersonalSign(address, message, proof.Signature)
+		} else {
+			err = verifier.VerifySolanaSignMessage(address, message, proof.Signature)
+		}
+		if err != nil {
+			logs.WarnCtx(ctx, "%s wallet signature verify failed, address: %s, err: %v", c, address, err)
+			return "", errno.ErrSignVerifyError
+		}
+		return address, nil
+	}
+}
+
+// walletMessage 钱包绑定的签名原文，包含用户 id，防止签名被其他账号拿去使用
+func walletMessage(userID int64, c chain.Chain, address, nonce string) string {
+	return fmt.Sprintf("MarketDAO wallet verification\n\nUser: %d\nChain: %s\nAddress: %s\nNonce: %s", userID, c, address, nonce)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"RESTful-API/internal/auth"
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/errno"
+	"RESTful-API/internal/model"
+	"context"
+	"testing"
+)
+
+const evmWallet = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+// currentUser 按请求中间件的方式从数据库加载当前用户和钱包
+func currentUser(t *testing.T, id int64) *auth.CurrentUser {
+	t.Helper()
+	var wallets []*model.UserWalletsModel
+	if err := model.NewUserWalletsModel().WithContext(context.Background()).ListNoPage(map[string]interface{}{"user_id = ?": id}, &wallets); err != nil {
+		t.Fatal(err)
+	}
+	return &auth.CurrentUser{User: loadUser(t, id), Wallets: wallets}
+}
+
+func TestUnlinkWalletPasswordLockout(t *testing.T) {
+	useAccountDB(t)
+	ctx := context.Background()
+	user := createUser(t, "alice", hashPassword(t, "right password"))
+	if err := model.NewUserWalletsModel().WithContext(ctx).Create(&model.UserWalletsModel{
+		UserID: user.ID, Chain: string(chain.EVM), WalletAddress: evmWallet,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	unlink := func(pw string) *errno.ErrMsg {
+		return UnlinkWallet(ctx, currentUser(t, user.ID), string(chain.EVM), &UnlinkWalletReq{Password: pw})
+	}
+
+	// 解绑时输错密码与登录共用失败计数：解绑错 4 次后，登录再错一次即被锁定
+	for i := 1; i < 5; i++ {
+		if e := unlink("wrong"); e != errno.ErrPasswordError {
+			t.Fatalf("unlink attempt %d: got %v, want ErrPasswordError", i, e)
+		}
+	}
+	if _, e := PasswordLogin(ctx, &PasswordLoginReq{Account: "alice", Password: "wrong"}); e != errno.ErrAccountLockedError {
+		t.Fatalf("login after 4 failed unlinks: got %v, want ErrAccountLockedError", e)
+	}
+	// 锁定期内解绑也被拒绝
+	if e := unlink("right password"); e != errno.ErrAccountLockedError {
+		t.Fatalf("unlink while locked: got %v, want ErrAccountLockedError", e)
+	}
+
+	expireLock(t, user.ID)
+	if e := unlink("right password"); e != nil {
+		t.Fatalf("unlink after lock: %v", e)
+	}
+	if got := currentUser(t, user.ID); len(got.Wallets) != 0 || got.User.PasswordTry != 0 || got.User.LockedUntil != nil {
+		t.Fatalf("after unlink: wallets %d, try %d, locked until %v", len(got.Wallets), got.User.PasswordTry, got.User.LockedUntil)
+	}
+}
@@ -0,0 +1,309 @@
+// Package session 登录会话：签发、刷新和校验 JWT
+//
+// 时长与 config.yaml 中 token 配置的对应关系：
+//   - access token 有效期为 refreshDuration，前端需要按这个间隔续签
+//   - refresh token 有效期为 shortDuration，超过这个时间没有续签视为无操作自动退出
+//   - 同一次登录轮换出来的 refresh token 最长存活 longDuration，之后必须重新登录
+package session
+
+import (
+	"RESTful-API/utils/config"
+	"RESTful-API/utils/logs"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TypeAccess  = "access"  // access token 类型
+	TypeRefresh = "refresh" // refresh token 类型
+)
+
+var (
+	ErrTokenInvalid = errors.New("token invalid")
+	ErrTokenExpired = errors.New("token expired")
+	ErrTokenReused  = errors.New("refresh token reused")
+	ErrNoSigningKey = errors.New("signing key not configured")
+)
+
+// Claims token 中携带的信息
+type Claims struct {
+	jwt.RegisteredClaims
+	UserID int64  `json:"uid"`
+	Type   string `json:"typ"`
+	Family string `json:"fam,omitempty"` // 仅 refresh token 使用
+}
+
+// TokenPair 登录或续签后返回给前端的 token
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	RefreshAt        time.Time `json:"refresh_at"` // 建议前端在这个时间点之后续签
+}
+
+// Options 会话配置
+type Options struct {
+	Issuer          string
+	ShortDuration   time.Duration     // refresh token 闲置有效期
+	RefreshDuration time.Duration     // access token 有效期
+	LongDuration    time.Duration     // 一次登录的最长有效期
+	Keys            map[string][]byte // kid -> 签名密钥
+	ActiveKid       string            // 当前用于签名的 kid
+}
+
+// Manager 会话管理
+type Manager struct {
+	opts  Options
+	store Store
+	now   func() time.Time
+}
+
+var (
+	defaultManager *Manager
+	defaultOnce    sync.Once
+	defaultErr     error
+)
+
+// Default 返回按 config.yaml 初始化的会话管理器。
+// dev 模式下没有配置签名密钥时使用随机生成的临时密钥，重启后之前签发的 token 全部失效
+func Default() (*Manager, error) {
+	defaultOnce.Do(func() {
+		opts := OptionsFromConfig()
+		if _, ok := opts.Keys[opts.ActiveKid]; !ok && config.IsDev() {
+			if opts.ActiveKid == "" {
+				opts.ActiveKid = "dev"
+			}
+			opts.Keys[opts.ActiveKid] = []byte(newID() + newID())
+			logs.Warn("token signing key %q not configured, using a temporary key in dev mode", opts.ActiveKid)
+		}
+		defaultManager, defaultErr = NewManager(opts, NewDBStore())
+	})
+	return defaultManager, defaultErr
+}
+
+// OptionsFromConfig 从 config.yaml 的 token 配置读取会话参数。
+// 签名密钥优先取环境变量 TOKEN_KEYS_<KID>，值为空的密钥视为未配置，token.retiredKids 中的密钥不会加载
+func OptionsFromConfig() Options {
+	minutes := func(key string, def int) time.Duration {
+		if !viper.IsSet(key) {
+			return time.Duration(def) * time.Minute
+		}
+		return time.Duration(viper.GetInt(key)) * time.Minute
+	}
+
+	// viper 读出的 map key 都是小写，kid 统一按小写处理
+	activeKid := strings.ToLower(viper.GetString("token.activeKid"))
+	secrets := viper.GetStringMapString("token.keys")
+	if _, ok := secrets[activeKid]; !ok && activeKid != "" {
+		secrets[activeKid] = ""
+	}
+	retired := make(map[string]bool)
+	for _, kid := range viper.GetStringSlice("token.retiredKids") {
+		retired[strings.ToLower(kid)] = true
+	}
+	keys := make(map[string][]byte)
+	for kid, secret := range secrets {
+		if retired[kid] {
+			logs.Warn("token signing key %q is retired and will not be loaded", kid)
+			continue
+		}
+		if env := os.Getenv("TOKEN_KEYS_" + strings.ToUpper(kid)); env != "" {
+			secret = env
+		}
+		if secret != "" {
+			keys[kid] = []byte(secret)
+		}
+	}
+	return Options{
+		Issuer:          viper.GetString("token.issuer"),
+		ShortDuration:   minutes("token.shortDuration", 30),
+		RefreshDuration: minutes("token.refreshDuration", 5),
+		LongDuration:    minutes("token.longDuration", 1440),
+		Keys:            keys,
+		ActiveKid:       activeKid,
+	}
+}
+
+// NewManager 创建会话管理器
+func NewManager(opts Options, store Store) (*Manager, error) {
+	if _, ok := opts.Keys[opts.ActiveKid]; !ok || opts.ActiveKid == "" {
+		return nil, ErrNoSigningKey
+	}
+	return &Manager{opts: opts, store: store, now: time.Now}, nil
+}
+
+// Issue 用户登录后签发一组新的 token，开启新的 refresh token family
+func (m *Manager) Issue(ctx context.Context, userID int64) (*TokenPair, error) {
+	pair, _, err := m.issueWithID(ctx, userID, newID(), m.now().Add(m.opts.LongDuration))
+	return pair, err
+}
+
+// Refresh 使用 refresh token 换取新的 token，旧 refresh token 同时作废。
+// 已作废的 refresh token 再次出现说明可能被盗用，整个 family 都会被吊销。
+func (m *Manager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := m.parse(refreshToken, TypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := m.store.Get(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.UserID != claims.UserID || record.FamilyID != claims.Family {
+		return nil, ErrTokenInvalid
+	}
+	if record.Revoked {
+		if err = m.store.RevokeFamily(ctx, record.FamilyID, m.now()); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenReused
+	}
+
+	pair, newJti, err := m.issueWithID(ctx, claims.UserID, claims.Family, record.SessionExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := m.store.Rotate(ctx, claims.ID, newJti, m.now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok { // 并发续签时只有一个请求能成功
+		if err = m.store.RevokeFamily(ctx, record.FamilyID, m.now()); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenReused
+	}
+	return pair, nil
+}
+
+// Revoke 退出登录，吊销 refresh token 所在的整个 family
+func (m *Manager) Revoke(ctx context.Context, refreshToken string) error {
+	claims, err := m.parse(refreshToken, TypeRefresh)
+	if err != nil {
+		return err
+	}
+	return m.store.RevokeFamily(ctx, claims.Family, m.now())
+}
+
+// ParseAccess 校验 access token 并返回其中的信息
+func (m *Manager) ParseAccess(token string) (*Claims, error) {
+	return m.parse(token, TypeAccess)
+}
+
+// issueWithID 签发 token 并记录 refresh token，返回 refresh token 的 jti
+func (m *Manager) issueWithID(ctx context.Context, userID int64, family string, sessionExpiresAt time.Time) (*TokenPair, string, error) {
+	now := m.now()
+	if !now.Before(sessionExpiresAt) {
+		return nil, "", ErrTokenExpired
+	}
+
+	accessExpiresAt := now.Add(m.opts.RefreshDuration)
+	refreshExpiresAt := now.Add(m.opts.ShortDuration)
+	if refreshExpiresAt.After(sessionExpiresAt) {
+		refreshExpiresAt = sessionExpiresAt
+	}
+	if accessExpiresAt.After(refreshExpiresAt) {
+		accessExpiresAt = refreshExpiresAt
+	}
+
+	access, err := m.sign(&Claims{
+		RegisteredClaims: m.registered(userID, newID(), now, accessExpiresAt),
+		UserID:           userID,
+		Type:             TypeAccess,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshID := newID()
+	refresh, err := m.sign(&Claims{
+		RegisteredClaims: m.registered(userID, refreshID, now, refreshExpiresAt),
+		UserID:           userID,
+		Type:             TypeRefresh,
+		Family:           family,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = m.store.Save(ctx, &Record{
+		TokenID:          refreshID,
+		FamilyID:         family,
+		UserID:           userID,
+		ExpiresAt:        refreshExpiresAt,
+		SessionExpiresAt: sessionExpiresAt,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshExpiresAt,
+		RefreshAt:        now.Add(m.opts.RefreshDuration / 2),
+	}, refreshID, nil
+}
+
+// registered 组装标准 claims
+func (m *Manager) registered(userID int64, id string, now, expiresAt time.Time) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    m.opts.Issuer,
+		Subject:   strconv.FormatInt(userID, 10),
+		ID:        id,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+}
+
+// sign 使用当前密钥签名，并在 header 中写入 kid
+func (m *Manager) sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = m.opts.ActiveKid
+	return token.SignedString(m.opts.Keys[m.opts.ActiveKid])
+}
+
+// parse 根据 header 中的 kid 选择密钥校验 token，并检查 token 类型
+func (m *Manager) parse(tokenString, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := m.opts.Keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.opts.Issuer),
+		jwt.WithTimeFunc(m.now),
+	)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrTokenExpired
+	}
+	if err != nil || claims.Type != tokenType {
+		return nil, ErrTokenInvalid
+	}
+	return claims, nil
+}
+
+// newID 生成随机的 token id
+func newID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
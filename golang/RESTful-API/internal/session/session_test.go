@@ -0,0 +1,372 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore 内存中的 Store，行为与 dbStore 一致
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]*Record)}
+}
+
+func (s *memoryStore) Save(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := *record
+	s.records[r.TokenID] = &r
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, tokenID string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[tokenID]
+	if !ok {
+		return nil, nil
+	}
+	c := *r
+	return &c, nil
+}
+
+func (s *memoryStore) Rotate(ctx context.Context, tokenID, replacedBy string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[tokenID]
+	if !ok || r.Revoked {
+		return false, nil
+	}
+	r.Revoked = true
+	return true, nil
+}
+
+func (s *memoryStore) RevokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.records {
+		if r.FamilyID == familyID {
+			r.Revoked = true
+		}
+	}
+	return nil
+}
+
+// 测试专用的密钥，与任何环境中的密钥无关
+var (
+	testKeyA = []byte("session-test-key-a-0123456789abcdef")
+	testKeyB = []byte("session-test-key-b-0123456789abcdef")
+)
+
+// clock 可手动推进的时钟
+type clock struct{ t time.Time }
+
+func newClock() *clock {
+	return &clock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func testOptions(keys map[string][]byte, active string) Options {
+	return Options{
+		Issuer:          "test",
+		ShortDuration:   30 * time.Minute,
+		RefreshDuration: 5 * time.Minute,
+		LongDuration:    24 * time.Hour,
+		Keys:            keys,
+		ActiveKid:       active,
+	}
+}
+
+func newTestManager(t *testing.T, opts Options, store Store, c *clock) *Manager {
+	t.Helper()
+	m, err := NewManager(opts, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = c.now
+	return m
+}
+
+// kidOf 读取 token header 中的 kid，不校验签名
+func kidOf(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestNewManagerRequiresActiveKey(t *testing.T) {
+	if _, err := NewManager(testOptions(map[string][]byte{"a": testKeyA}, "b"), newMemoryStore()); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("got %v, want ErrNoSigningKey", err)
+	}
+	if _, err := NewManager(testOptions(map[string][]byte{}, ""), newMemoryStore()); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("got %v, want ErrNoSigningKey", err)
+	}
+}
+
+func TestIssue(t *testing.T) {
+	c := newClock()
+	store := newMemoryStore()
+	m := newTestManager(t, testOptions(map[string][]byte{"a": testKeyA}, "a"), store, c)
+	ctx := context.Background()
+
+	pair, err := m.Issue(ctx, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pair.AccessExpiresAt.Equal(c.now().Add(5*time.Minute)) || !pair.RefreshExpiresAt.Equal(c.now().Add(30*time.Minute)) {
+		t.Fatalf("expires at %v / %v", pair.AccessExpiresAt, pair.RefreshExpiresAt)
+	}
+	if !pair.RefreshAt.Equal(c.now().Add(150 * time.Second)) {
+		t.Fatalf("refresh at %v", pair.RefreshAt)
+	}
+	if kidOf(t, pair.AccessToken) != "a" || kidOf(t, pair.RefreshToken) != "a" {
+		t.Fatal("tokens should be signed with the active kid")
+	}
+
+	claims, err := m.ParseAccess(pair.AccessToken)
+	if err != nil || claims.UserID != 42 || claims.Subject != "42" || claims.Type != TypeAccess {
+		t.Fatalf("ParseAccess = %+v, %v", claims, err)
+	}
+	// refresh token 不能当作 access token 使用
+	if _, err = m.ParseAccess(pair.RefreshToken); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("refresh as access: got %v, want ErrTokenInvalid", err)
+	}
+	if _, err = m.ParseAccess(pair.AccessToken[:len(pair.AccessToken)-2]); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("tampered token: got %v, want ErrTokenInvalid", err)
+	}
+
+	refresh, err := m.parse(pair.RefreshToken, TypeRefresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, _ := store.Get(ctx, refresh.ID)
+	if record == nil || record.UserID != 42 || record.FamilyID != refresh.Family || record.Revoked ||
+		!record.SessionExpiresAt.Equal(c.now().Add(24*time.Hour)) {
+		t.Fatalf("stored record %+v", record)
+	}
+
+	c.advance(5*time.Minute + time.Second)
+	if _, err = m.ParseAccess(pair.AccessToken); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("got %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	c := newClock()
+	m := newTestManager(t, testOptions(map[string][]byte{"a": testKeyA}, "a"), newMemoryStore(), c)
+	ctx := context.Background()
+
+	first, err := m.Issue(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.advance(4 * time.Minute)
+	second, err := m.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Fatal("refresh should issue new tokens")
+	}
+	a, _ := m.parse(first.RefreshToken, TypeRefresh)
+	b, _ := m.parse(second.RefreshToken, TypeRefresh)
+	if a.Family != b.Family || a.ID == b.ID {
+		t.Fatalf("family %s -> %s, jti %s -> %s", a.Family, b.Family, a.ID, b.ID)
+	}
+	if claims, err := m.ParseAccess(second.AccessToken); err != nil || claims.UserID != 7 {
+		t.Fatalf("ParseAccess = %+v, %v", claims, err)
+	}
+	// access token 不能用来续签
+	if _, err = m.Refresh(ctx, second.AccessToken); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("access as refresh: got %v, want ErrTokenInvalid", err)
+	}
+
+	// 闲置超过 shortDuration 后 refresh token 过期
+	c.advance(31 * time.Minute)
+	if _, err = m.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("idle refresh: got %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestRefreshSessionLimit(t *testing.T) {
+	c := newClock()
+	m := newTestManager(t, testOptions(map[string][]byte{"a": testKeyA}, "a"), newMemoryStore(), c)
+	ctx := context.Background()
+
+	pair, err := m.Issue(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := c.now()
+	// 持续续签也不能超过 longDuration
+	for c.now().Before(start.Add(24*time.Hour - 20*time.Minute)) {
+		c.advance(20 * time.Minute)
+		if pair, err = m.Refresh(ctx, pair.RefreshToken); err != nil {
+			t.Fatalf("refresh at %v: %v", c.now().Sub(start), err)
+		}
+	}
+	if pair.RefreshExpiresAt.After(start.Add(24 * time.Hour)) {
+		t.Fatalf("refresh token outlives the session: %v", pair.RefreshExpiresAt)
+	}
+	c.advance(20 * time.Minute)
+	if _, err = m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("got %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	c := newClock()
+	store := newMemoryStore()
+	m := newTestManager(t, testOptions(map[string][]byte{"a": testKeyA}, "a"), store, c)
+	ctx := context.Background()
+
+	stolen, err := m.Issue(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := m.Issue(ctx, 7) // 同一用户的另一次登录
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := m.Refresh(ctx, stolen.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := m.Refresh(ctx, rotated.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 已轮换掉的 token 再次出现，整个 family 被吊销，包括最新的 token
+	if _, err = m.Refresh(ctx, stolen.RefreshToken); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("reuse: got %v, want ErrTokenReused", err)
+	}
+	if _, err = m.Refresh(ctx, latest.RefreshToken); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("latest after reuse: got %v, want ErrTokenReused", err)
+	}
+	family, _ := m.parse(stolen.RefreshToken, TypeRefresh)
+	for _, r := range store.records {
+		if r.FamilyID == family.Family && !r.Revoked {
+			t.Fatalf("token %s of the reused family is not revoked", r.TokenID)
+		}
+	}
+	// 其他登录不受影响
+	if _, err = m.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Fatalf("other session: %v", err)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	c := newClock()
+	m := newTestManager(t, testOptions(map[string][]byte{"a": testKeyA}, "a"), newMemoryStore(), c)
+	ctx := context.Background()
+
+	pair, err := m.Issue(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Revoke(ctx, pair.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("got %v, want ErrTokenReused", err)
+	}
+}
+
+func TestKidRotation(t *testing.T) {
+	c := newClock()
+	store := newMemoryStore()
+	ctx := context.Background()
+
+	before := newTestManager(t, testOptions(map[string][]byte{"a": testKeyA}, "a"), store, c)
+	old, err := before.Issue(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新增密钥 b 并切换为当前密钥，a 保留到旧 token 过期
+	after := newTestManager(t, testOptions(map[string][]byte{"a": testKeyA, "b": testKeyB}, "b"), store, c)
+	if claims, err := after.ParseAccess(old.AccessToken); err != nil || claims.UserID != 7 {
+		t.Fatalf("old access token after rotation: %+v, %v", claims, err)
+	}
+	renewed, err := after.Refresh(ctx, old.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kidOf(t, renewed.AccessToken) != "b" || kidOf(t, renewed.RefreshToken) != "b" {
+		t.Fatal("renewed tokens should be signed with the new kid")
+	}
+
+	// 移除 a 之后用 a 签名的 token 不再有效
+	retired := newTestManager(t, testOptions(map[string][]byte{"b": testKeyB}, "b"), store, c)
+	if _, err = retired.ParseAccess(old.AccessToken); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("retired kid: got %v, want ErrTokenInvalid", err)
+	}
+	if _, err = retired.ParseAccess(renewed.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+
+	// header 中的 kid 被改成 b 时签名校验失败
+	forged := strings.Replace(old.AccessToken, strings.SplitN(old.AccessToken, ".", 2)[0], header(t, "b"), 1)
+	if _, err = after.ParseAccess(forged); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("forged kid: got %v, want ErrTokenInvalid", err)
+	}
+}
+
+// header 构造指定 kid 的 JWT header 段
+func header(t *testing.T, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(testKeyB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.SplitN(signed, ".", 2)[0]
+}
+
+func TestOptionsFromConfigSkipsRetiredKids(t *testing.T) {
+	for key, value := range map[string]interface{}{
+		"token.activeKid":   "k2",
+		"token.keys":        map[string]interface{}{"k1": "leaked", "k2": ""},
+		"token.retiredKids": []string{"K1"},
+	} {
+		viper.Set(key, value)
+	}
+	t.Cleanup(func() {
+		for _, key := range []string{"token.activeKid", "token.keys", "token.retiredKids"} {
+			viper.Set(key, nil)
+		}
+	})
+	t.Setenv("TOKEN_KEYS_K1", "leaked-too")
+	t.Setenv("TOKEN_KEYS_K2", "from-env")
+
+	opts := OptionsFromConfig()
+	if _, ok := opts.Keys["k1"]; ok {
+		t.Fatal("retired kid k1 should not be loaded")
+	}
+	if string(opts.Keys["k2"]) != "from-env" || opts.ActiveKid != "k2" {
+		t.Fatalf("keys %v, active %q", opts.Keys, opts.ActiveKid)
+	}
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"RESTful-API/internal/constants"
+	"RESTful-API/internal/model"
+	"context"
+	"time"
+)
+
+// Record 已签发的 refresh token
+type Record struct {
+	TokenID          string
+	FamilyID         string
+	UserID           int64
+	ExpiresAt        time.Time
+	SessionExpiresAt time.Time
+	Revoked          bool
+}
+
+// Store refresh token 的持久化存储
+type Store interface {
+	// Save 保存新签发的 refresh token
+	Save(ctx context.Context, record *Record) error
+	// Get 按 jti 查询，不存在时返回 nil
+	Get(ctx context.Context, tokenID string) (*Record, error)
+	// Rotate 吊销旧 token 并记录替换它的新 token，旧 token 已被吊销时返回 false
+	Rotate(ctx context.Context, tokenID, replacedBy string, now time.Time) (bool, error)
+	// RevokeFamily 吊销同一个 family 下的所有 token
+	RevokeFamily(ctx context.Context, familyID string, now time.Time) error
+}
+
+// dbStore 基于 user_refresh_tokens 表的存储
+type dbStore struct{}
+
+// NewDBStore 创建数据库存储
+func NewDBStore() Store {
+	return &dbStore{}
+}
+
+func (s *dbStore) Save(ctx context.Context, record *Record) error {
+	return model.NewRefreshTokensModel().WithContext(ctx).Create(&model.RefreshTokensModel{
+		UserID:     record.UserID,
+		TokenID:    record.TokenID,
+		FamilyID:   record.FamilyID,
+		ExpiresAt:  record.ExpiresAt,
+		SessionExp: record.SessionExpiresAt,
+	})
+}
+
+func (s *dbStore) Get(ctx context.Context, tokenID string) (*Record, error) {
+	dao := &model.RefreshTokensModel{}
+	if err := model.NewRefreshTokensModel().WithContext(ctx).QueryOne(map[string]interface{}{"token_id = ?": tokenID}, dao); err != nil {
+		return nil, err
+	}
+	if dao.ID == constants.DefaultZero {
+		return nil, nil
+	}
+	return &Record{
+		TokenID:          dao.TokenID,
+		FamilyID:         dao.FamilyID,
+		UserID:           dao.UserID,
+		ExpiresAt:        dao.ExpiresAt,
+		SessionExpiresAt: dao.SessionExp,
+		Revoked:          dao.RevokedAt != nil,
+	}, nil
+}
+
+func (s *dbStore) Rotate(ctx context.Context, tokenID, replacedBy string, now time.Time) (bool, error) {
+	// revoked_at IS NULL 作为条件，保证同一个 token 只能被轮换一次
+	rows, err := model.NewRefreshTokensModel().WithContext(ctx).Update(map[string]interface{}{
+		"revoked_at":  now,
+		"replaced_by": replacedBy,
+	}, map[string]interface{}{
+		"token_id = ? AND revoked_at IS NULL": tokenID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > constants.DefaultZero, nil
+}
+
+func (s *dbStore) RevokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	_, err := model.NewRefreshTokensModel().WithContext(ctx).Update(map[string]interface{}{
+		"revoked_at": now,
+	}, map[string]interface{}{
+		"family_id = ? AND revoked_at IS NULL": familyID,
+	})
+	return err
+}
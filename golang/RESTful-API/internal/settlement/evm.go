@@ -0,0 +1,191 @@
+package settlement
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/indexer"
+	"RESTful-API/internal/money"
+	"context"
+	"math/big"
+	"strings"
+)
+
+// decodeEVMTrade 从回执日志中找到 ERC-721/1155 转移，成交价为买家在交易中净支付的 ETH 和 WETH：
+// 买家作为发送方附带的 ETH，加上从买家转出的 WETH（卖家接受出价等由卖家发起的成交，买家通过授权用 WETH 付款）。
+// 买家转出其他 ERC-20 时无法按原生币计价，返回 ErrPaymentToken；
+// 一笔交易转移多个 NFT（bundle、批量购买）时无法拆分每个 NFT 的成交价，返回 ErrBundle。
+// 合约内部转出的 ETH 不产生日志，买家多付后由合约退回的 ETH 会计入成交价
+func decodeEVMTrade(ctx context.Context, c *evm.Client, hash, contract, tokenID string) (*Trade, error) {
+	tx, logs, err := evmReceipt(ctx, c, hash)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		trade *indexer.Transfer
+		items = map[string]bool{}
+	)
+	for _, l := range logs {
+		transfers, err := indexer.DecodeTransfers(l)
+		if err != nil {
+			continue
+		}
+		for i, t := range transfers {
+			// 铸造和销毁不是成交
+			if t.From == "" || t.To == "" {
+				continue
+			}
+			items[strings.ToLower(t.Contract)+"/"+t.TokenID] = true
+			if trade == nil && chain.SameAddress(chain.EVM, t.Contract, contract) && t.TokenID == tokenID {
+				trade = &transfers[i]
+			}
+		}
+	}
+	if trade == nil {
+		return nil, ErrNoTrade
+	}
+	if len(items) > 1 {
+		return nil, ErrBundle
+	}
+
+	paid := new(big.Int)
+	if tx.From == trade.To {
+		paid.Set(tx.Value)
+	}
+	for _, l := range logs {
+		if !isERC20Transfer(l) {
+			continue
+		}
+		from, to := topicAddress(l.Topics[1]), topicAddress(l.Topics[2])
+		if from != trade.To && to != trade.To {
+			continue
+		}
+		if l.Address != c.WETH() || c.WETH() == "" {
+			// 买家收到其他代币（例如返利）不影响成交价，转出则视为用该代币付款
+			if from == trade.To {
+				return nil, ErrPaymentToken
+			}
+			continue
+		}
+		value := new(big.Int).SetBytes(l.Data[:32])
+		if from == trade.To {
+			paid.Add(paid, value)
+		}
+		if to == trade.To {
+			paid.Sub(paid, value)
+		}
+	}
+	if paid.Sign() < 0 {
+		paid.SetInt64(0)
+	}
+	return &Trade{
+		Chain:         chain.EVM,
+		TxHash:        tx.Hash,
+		Contract:      trade.Contract,
+		TokenID:       trade.TokenID,
+		Seller:        trade.From,
+		Buyer:         trade.To,
+		Price:         money.FromBaseUnits(paid, evmNativeDecimals),
+		BlockNumber:   tx.BlockNumber,
+		Confirmations: tx.Confirmations,
+	}, nil
+}
+
+// decodeEVMSwap 钱包必须是交易的发送方，资产变化取自附带的 ETH 和 ERC-20 Transfer 日志。
+// 合约内部转出的 ETH 不产生日志，因此换出原生币的 swap 无法识别
+func decodeEVMSwap(ctx context.Context, c *evm.Client, hash, wallet string) (*Swap, error) {
+	wallet, err := chain.NormalizeAddress(chain.EVM, wallet)
+	if err != nil {
+		return nil, err
+	}
+	tx, logs, err := evmReceipt(ctx, c, hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx.From != wallet {
+		return nil, ErrNoSwap
+	}
+
+	amounts := map[string]*big.Int{}
+	add := func(asset string, v *big.Int) {
+		if amounts[asset] == nil {
+			amounts[asset] = new(big.Int)
+		}
+		amounts[asset].Add(amounts[asset], v)
+	}
+	if tx.Value.Sign() > 0 {
+		add(NativeAsset, new(big.Int).Neg(tx.Value))
+	}
+	for _, l := range logs {
+		if !isERC20Transfer(l) {
+			continue
+		}
+		value := new(big.Int).SetBytes(l.Data[:32])
+		if topicAddress(l.Topics[1]) == wallet {
+			add(l.Address, new(big.Int).Neg(value))
+		}
+		if topicAddress(l.Topics[2]) == wallet {
+			add(l.Address, value)
+		}
+	}
+
+	deltas := make([]assetDelta, 0, len(amounts))
+	for asset, amount := range amounts {
+		deltas = append(deltas, assetDelta{asset: asset, amount: amount})
+	}
+	from, to, ok := pickSwap(deltas)
+	if !ok {
+		return nil, ErrNoSwap
+	}
+	for _, d := range []*assetDelta{&from, &to} {
+		d.decimals = evmNativeDecimals
+		if d.asset != NativeAsset {
+			if d.decimals, err = c.Decimals(ctx, d.asset); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &Swap{
+		Chain:         chain.EVM,
+		TxHash:        tx.Hash,
+		Wallet:        wallet,
+		FromAsset:     from.asset,
+		ToAsset:       to.asset,
+		FromAmount:    money.FromBaseUnits(from.amount, from.decimals),
+		ToAmount:      money.FromBaseUnits(to.amount, to.decimals),
+		BlockNumber:   tx.BlockNumber,
+		Confirmations: tx.Confirmations,
+	}, nil
+}
+
+// evmReceipt 查询已打包且执行成功的交易及其日志
+func evmReceipt(ctx context.Context, c *evm.Client, hash string) (*chain.Transaction, []evm.Log, error) {
+	tx, err := c.Transaction(ctx, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tx.BlockNumber == 0 {
+		return nil, nil, ErrTxPending
+	}
+	if !tx.Success {
+		return nil, nil, ErrTxFailed
+	}
+	logs, err := c.TransactionLogs(ctx, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, logs, nil
+}
+
+// isERC20Transfer ERC-20 Transfer 有三个 topic，数量在 data 中；ERC-721 的 Transfer 有四个 topic
+func isERC20Transfer(l evm.Log) bool {
+	return len(l.Topics) == 3 && strings.ToLower(l.Topics[0]) == indexer.TopicTransfer && len(l.Data) >= 32
+}
+
+// topicAddress 取 topic 的低 20 字节作为地址
+func topicAddress(topic string) string {
+	topic = strings.TrimPrefix(topic, "0x")
+	if len(topic) != 64 {
+		return ""
+	}
+	return chain.ChecksumEVM(topic[24:])
+}
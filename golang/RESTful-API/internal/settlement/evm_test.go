@@ -0,0 +1,290 @@
+package settlement
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/evm/evmtest"
+	"RESTful-API/internal/indexer"
+	"RESTful-API/internal/money"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	evmSeller = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	evmBuyer  = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+	evmMarket = "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"
+	evmNFT    = "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb"
+	evmWETH   = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	evmUSDC   = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	evmDAI    = "0x6B175474E89094C44Da98b954EedeAC495271d0F"
+)
+
+func addressTopic(addr string) string {
+	return "0x" + strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(addr, "0x"))
+}
+
+// nftTransfer ERC-721 Transfer，tokenId 在第四个 topic 中
+func nftTransfer(contract, from, to string, tokenID int64) evm.Log {
+	return evm.Log{
+		Address: contract,
+		Topics:  []string{indexer.TopicTransfer, addressTopic(from), addressTopic(to), fmt.Sprintf("0x%064x", tokenID)},
+	}
+}
+
+// tokenTransfer ERC-20 Transfer，数量在 data 中
+func tokenTransfer(token, from, to string, amount *big.Int) evm.Log {
+	return evm.Log{
+		Address: token,
+		Topics:  []string{indexer.TopicTransfer, addressTopic(from), addressTopic(to)},
+		Data:    amount.FillBytes(make([]byte, 32)),
+	}
+}
+
+// ether 把 ETH 数量转为 wei
+func ether(s string) *big.Int {
+	wei, err := money.MustParse(s).BaseUnits(evmNativeDecimals)
+	if err != nil {
+		panic(err)
+	}
+	return wei
+}
+
+func evmClient(node *evmtest.Node) *evm.Client {
+	return evm.New(chain.Endpoint{URL: node.URL(), ChainID: 1, WETH: evmWETH, PollInterval: 10 * time.Millisecond})
+}
+
+func TestDecodeEVMTrade(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx := context.Background()
+	client := evmClient(node)
+	node.Mine(1)
+
+	tests := []struct {
+		name   string
+		from   string
+		value  *big.Int
+		logs   []evm.Log
+		price  string
+		seller string
+		err    error
+	}{
+		{
+			name: "buyer pays eth", from: evmBuyer, value: ether("1.5"),
+			logs:  []evm.Log{nftTransfer(evmNFT, evmSeller, evmBuyer, 7)},
+			price: "1.5", seller: evmSeller,
+		},
+		{
+			// 卖家接受出价：交易由卖家发起，买家通过授权转出 WETH，其中一部分是平台手续费
+			name: "seller accepts weth offer", from: evmSeller,
+			logs: []evm.Log{
+				nftTransfer(evmNFT, evmSeller, evmBuyer, 7),
+				tokenTransfer(evmWETH, evmBuyer, evmSeller, ether("1.95")),
+				tokenTransfer(evmWETH, evmBuyer, evmMarket, ether("0.05")),
+			},
+			price: "2", seller: evmSeller,
+		},
+		{
+			name: "eth and weth", from: evmBuyer, value: ether("1"),
+			logs: []evm.Log{
+				tokenTransfer(evmWETH, evmBuyer, evmMarket, ether("0.5")),
+				nftTransfer(evmNFT, evmSeller, evmBuyer, 7),
+			},
+			price: "1.5", seller: evmSeller,
+		},
+		{
+			name: "weth refunded to buyer", from: evmBuyer,
+			logs: []evm.Log{
+				tokenTransfer(evmWETH, evmBuyer, evmMarket, ether("3")),
+				nftTransfer(evmNFT, evmSeller, evmBuyer, 7),
+				tokenTransfer(evmWETH, evmMarket, evmBuyer, ether("1")),
+			},
+			price: "2", seller: evmSeller,
+		},
+		{
+			// 买家收到的其他代币不影响成交价
+			name: "buyer receives other token", from: evmBuyer, value: ether("1"),
+			logs: []evm.Log{
+				nftTransfer(evmNFT, evmSeller, evmBuyer, 7),
+				tokenTransfer(evmUSDC, evmMarket, evmBuyer, big.NewInt(5e6)),
+			},
+			price: "1", seller: evmSeller,
+		},
+		{
+			// 卖家发起的转移没有付款，成交价为 0，由调用方按提交的价格判定不一致
+			name: "gift", from: evmSeller,
+			logs:  []evm.Log{nftTransfer(evmNFT, evmSeller, evmBuyer, 7)},
+			price: "0", seller: evmSeller,
+		},
+		{
+			name: "paid in usdc", from: evmBuyer,
+			logs: []evm.Log{
+				tokenTransfer(evmUSDC, evmBuyer, evmSeller, big.NewInt(3000e6)),
+				nftTransfer(evmNFT, evmSeller, evmBuyer, 7),
+			},
+			err: ErrPaymentToken,
+		},
+		{
+			name: "bundle", from: evmBuyer, value: ether("3"),
+			logs: []evm.Log{
+				nftTransfer(evmNFT, evmSeller, evmBuyer, 7),
+				nftTransfer(evmNFT, evmSeller, evmBuyer, 8),
+			},
+			err: ErrBundle,
+		},
+		{
+			name: "mint", from: evmBuyer, value: ether("0.1"),
+			logs: []evm.Log{nftTransfer(evmNFT, "0x0000000000000000000000000000000000000000", evmBuyer, 7)},
+			err:  ErrNoTrade,
+		},
+		{
+			name: "other token", from: evmBuyer, value: ether("1"),
+			logs: []evm.Log{nftTransfer(evmNFT, evmSeller, evmBuyer, 9)},
+			err:  ErrNoTrade,
+		},
+	}
+	for _, tt := range tests {
+		hash := node.AddTransaction(strings.ToLower(tt.from), evmMarket, tt.value, true, tt.logs...)
+		trade, err := decodeEVMTrade(ctx, client, hash, strings.ToLower(evmNFT), "7")
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if trade.Chain != chain.EVM || trade.TxHash != hash || trade.Contract != evmNFT || trade.TokenID != "7" ||
+			trade.Seller != tt.seller || trade.Buyer != evmBuyer || !trade.Price.Equal(money.MustParse(tt.price)) {
+			t.Errorf("%s: unexpected trade %+v", tt.name, trade)
+		}
+	}
+}
+
+// TestDecodeEVMTradeWithoutWETH 未配置 WETH 时所有 ERC-20 付款都无法计价
+func TestDecodeEVMTradeWithoutWETH(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	node.Mine(1)
+	hash := node.AddTransaction(evmSeller, evmMarket, nil, true,
+		nftTransfer(evmNFT, evmSeller, evmBuyer, 7),
+		tokenTransfer(evmWETH, evmBuyer, evmSeller, ether("1")))
+	if _, err := decodeEVMTrade(context.Background(), node.Client(), hash, evmNFT, "7"); !errors.Is(err, ErrPaymentToken) {
+		t.Fatalf("got %v, want ErrPaymentToken", err)
+	}
+}
+
+func TestDecodeEVMTransactionState(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx := context.Background()
+	client := evmClient(node)
+
+	node.Mine(1)
+	failed := node.AddTransaction(evmBuyer, evmMarket, ether("1"), false)
+	included := node.AddTransaction(evmBuyer, evmMarket, ether("1"), true, nftTransfer(evmNFT, evmSeller, evmBuyer, 7))
+	pending := node.AddPendingTransaction(evmBuyer, evmMarket, ether("1"))
+	node.Mine(4)
+
+	tests := []struct {
+		hash string
+		err  error
+	}{
+		{failed, ErrTxFailed},
+		{pending, ErrTxPending},
+		{"0x" + strings.Repeat("ab", 32), chain.ErrTxNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := decodeEVMTrade(ctx, client, tt.hash, evmNFT, "7"); !errors.Is(err, tt.err) {
+			t.Errorf("trade %s: got %v, want %v", tt.hash, err, tt.err)
+		}
+		if _, err := decodeEVMSwap(ctx, client, tt.hash, evmBuyer); !errors.Is(err, tt.err) {
+			t.Errorf("swap %s: got %v, want %v", tt.hash, err, tt.err)
+		}
+	}
+
+	trade, err := decodeEVMTrade(ctx, client, included, evmNFT, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trade.BlockNumber != 1 || trade.Confirmations != 5 {
+		t.Fatalf("block %d, confirmations %d", trade.BlockNumber, trade.Confirmations)
+	}
+}
+
+func TestDecodeEVMSwap(t *testing.T) {
+	node := evmtest.NewNode(1)
+	defer node.Close()
+	ctx := context.Background()
+	client := evmClient(node)
+	node.SetDecimals(evmUSDC, 6)
+	node.SetDecimals(evmDAI, 18)
+	node.Mine(1)
+
+	tests := []struct {
+		name       string
+		from       string
+		value      *big.Int
+		logs       []evm.Log
+		fromAsset  string
+		fromAmount string
+		toAsset    string
+		toAmount   string
+		err        error
+	}{
+		{
+			name: "eth to usdc", from: evmBuyer, value: ether("1"),
+			logs:      []evm.Log{tokenTransfer(evmUSDC, evmMarket, evmBuyer, big.NewInt(3012_500000))},
+			fromAsset: NativeAsset, fromAmount: "1", toAsset: evmUSDC, toAmount: "3012.5",
+		},
+		{
+			// 附带的 ETH 视为手续费等开销
+			name: "usdc to dai", from: evmBuyer, value: ether("0.001"),
+			logs: []evm.Log{
+				tokenTransfer(evmUSDC, evmBuyer, evmMarket, big.NewInt(100_000000)),
+				tokenTransfer(evmDAI, evmMarket, evmBuyer, ether("99.95")),
+			},
+			fromAsset: evmUSDC, fromAmount: "100", toAsset: evmDAI, toAmount: "99.95",
+		},
+		{
+			// 合约内部转出的 ETH 没有日志，换出原生币无法识别
+			name: "usdc to eth", from: evmBuyer,
+			logs: []evm.Log{tokenTransfer(evmUSDC, evmBuyer, evmMarket, big.NewInt(100_000000))},
+			err:  ErrNoSwap,
+		},
+		{
+			name: "not sender", from: evmSeller, value: ether("1"),
+			logs: []evm.Log{tokenTransfer(evmUSDC, evmMarket, evmBuyer, big.NewInt(1))},
+			err:  ErrNoSwap,
+		},
+		{
+			name: "two assets in", from: evmBuyer,
+			logs: []evm.Log{
+				tokenTransfer(evmWETH, evmBuyer, evmMarket, ether("1")),
+				tokenTransfer(evmUSDC, evmMarket, evmBuyer, big.NewInt(1)),
+				tokenTransfer(evmDAI, evmMarket, evmBuyer, big.NewInt(1)),
+			},
+			err: ErrNoSwap,
+		},
+	}
+	for _, tt := range tests {
+		hash := node.AddTransaction(strings.ToLower(tt.from), evmMarket, tt.value, true, tt.logs...)
+		swap, err := decodeEVMSwap(ctx, client, hash, strings.ToLower(evmBuyer))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if swap.TxHash != hash || swap.Wallet != evmBuyer || swap.FromAsset != tt.fromAsset || swap.ToAsset != tt.toAsset ||
+			!swap.FromAmount.Equal(money.MustParse(tt.fromAmount)) || !swap.ToAmount.Equal(money.MustParse(tt.toAmount)) {
+			t.Errorf("%s: unexpected swap %+v", tt.name, swap)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package settlement 从链上交易中解码 NFT 成交和 swap，用于校验用户提交的交易哈希
+package settlement
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/evm"
+	"RESTful-API/internal/chain/solana"
+	"RESTful-API/internal/chain/ton"
+	"RESTful-API/internal/money"
+	"context"
+	"errors"
+	"math/big"
+)
+
+// NativeAsset 原生币（ETH、SOL）的资产地址
+const NativeAsset = ""
+
+// 原生币的精度
+const (
+	evmNativeDecimals    = 18 // wei
+	solanaNativeDecimals = 9  // lamports
+	tonNativeDecimals    = 9  // nanoton
+)
+
+var (
+	ErrUnsupported  = errors.New("settlement: not supported on this chain")
+	ErrTxPending    = errors.New("settlement: transaction not included in a block yet")
+	ErrTxFailed     = errors.New("settlement: transaction failed")
+	ErrNoTrade      = errors.New("settlement: no matching nft transfer in transaction")
+	ErrNoSwap       = errors.New("settlement: no swap by wallet in transaction")
+	ErrBundle       = errors.New("settlement: transaction transfers more than one nft")
+	ErrPaymentToken = errors.New("settlement: payment token not supported")
+)
+
+// Trade 交易中的一次 NFT 成交：NFT 从 Seller 转给 Buyer，Price 为 Buyer 在交易中支付的原生币数量（EVM 包含 WETH）
+type Trade struct {
+	Chain         chain.Chain
+	TxHash        string // 用于查重和入库的交易哈希，TON 为 trace 中 item 所有权变化的交易
+	Contract      string
+	TokenID       string
+	Seller        string
+	Buyer         string
+	Price         money.Decimal
+	BlockNumber   uint64
+	Confirmations uint64
+}
+
+// Swap 钱包在交易中用 FromAmount 个 FromAsset 换到 ToAmount 个 ToAsset，原生币的资产地址为 NativeAsset
+type Swap struct {
+	Chain         chain.Chain
+	TxHash        string
+	Wallet        string
+	FromAsset     string
+	ToAsset       string
+	FromAmount    money.Decimal
+	ToAmount      money.Decimal
+	BlockNumber   uint64
+	Confirmations uint64
+}
+
+// DecodeTrade 在交易中查找 contract/tokenID 的转移，解码为成交记录；
+// 交易不存在时返回 chain.ErrTxNotFound，不支持的客户端返回 ErrUnsupported
+func DecodeTrade(ctx context.Context, client chain.Client, hash, contract, tokenID string) (*Trade, error) {
+	switch c := client.(type) {
+	case *evm.Client:
+		return decodeEVMTrade(ctx, c, hash, contract, tokenID)
+	case *solana.Client:
+		return decodeSolanaTrade(ctx, c, hash, tokenID)
+	case *ton.Client:
+		return decodeTONTrade(ctx, c, hash, contract, tokenID)
+	}
+	return nil, ErrUnsupported
+}
+
+// DecodeSwap 汇总钱包在交易中各资产的净变化，恰好一种资产减少、一种资产增加时视为 swap；
+// 同时有代币变化时，原生币的变化视为手续费等开销而忽略
+func DecodeSwap(ctx context.Context, client chain.Client, hash, wallet string) (*Swap, error) {
+	switch c := client.(type) {
+	case *evm.Client:
+		return decodeEVMSwap(ctx, c, hash, wallet)
+	case *solana.Client:
+		return decodeSolanaSwap(ctx, c, hash, wallet)
+	}
+	return nil, ErrUnsupported
+}
+
+// assetDelta 钱包在一种资产上的净变化，单位为最小单位
+type assetDelta struct {
+	asset    string
+	amount   *big.Int
+	decimals int32
+}
+
+// pickSwap 从净变化中选出转出和转入的资产
+func pickSwap(deltas []assetDelta) (from, to assetDelta, ok bool) {
+	var out, in []assetDelta
+	for _, d := range deltas {
+		switch d.amount.Sign() {
+		case -1:
+			out = append(out, d)
+		case 1:
+			in = append(in, d)
+		}
+	}
+	out, in = withoutNative(out), withoutNative(in)
+	if len(out) != 1 || len(in) != 1 {
+		return assetDelta{}, assetDelta{}, false
+	}
+	from, to = out[0], in[0]
+	from.amount = new(big.Int).Neg(from.amount)
+	return from, to, true
+}
+
+// withoutNative 多于一种资产时去掉原生币
+func withoutNative(deltas []assetDelta) []assetDelta {
+	if len(deltas) <= 1 {
+		return deltas
+	}
+	result := deltas[:0:0]
+	for _, d := range deltas {
+		if d.asset != NativeAsset {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// confirmations head 与 block 之间的确认数，block 本身算一个确认
+func confirmations(head, block uint64) uint64 {
+	if block == 0 || head < block {
+		return 0
+	}
+	return head - block + 1
+}
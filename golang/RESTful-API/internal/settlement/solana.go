@@ -0,0 +1,162 @@
+package settlement
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/solana"
+	"RESTful-API/internal/money"
+	"context"
+	"math/big"
+)
+
+// decodeSolanaTrade 按交易前后的 token 余额找出 mint 的卖方和买方，
+// 成交价为买方减少的 lamports（买方支付手续费时扣除手续费，新建 token 账户的租金包含在内）
+func decodeSolanaTrade(ctx context.Context, c *solana.Client, signature, mint string) (*Trade, error) {
+	tx, head, err := solanaTransaction(ctx, c, signature)
+	if err != nil {
+		return nil, err
+	}
+	var seller, buyer string
+	for owner, delta := range tokenDeltas(tx, func(b solana.TokenBalance) bool { return b.Mint == mint }) {
+		switch delta.Sign() {
+		case -1:
+			seller = owner
+		case 1:
+			buyer = owner
+		}
+	}
+	if seller == "" || buyer == "" {
+		return nil, ErrNoTrade
+	}
+	return &Trade{
+		Chain:         chain.Solana,
+		TxHash:        signature,
+		Contract:      mint,
+		TokenID:       mint,
+		Seller:        seller,
+		Buyer:         buyer,
+		Price:         money.FromBaseUnits(lamportsSpent(tx, buyer), solanaNativeDecimals),
+		BlockNumber:   tx.Slot,
+		Confirmations: confirmations(head, tx.Slot),
+	}, nil
+}
+
+// decodeSolanaSwap 资产变化取自钱包名下 token 账户的余额变化和钱包的 SOL 变化
+func decodeSolanaSwap(ctx context.Context, c *solana.Client, signature, wallet string) (*Swap, error) {
+	wallet, err := chain.NormalizeAddress(chain.Solana, wallet)
+	if err != nil {
+		return nil, err
+	}
+	tx, head, err := solanaTransaction(ctx, c, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals := map[string]int32{}
+	var deltas []assetDelta
+	for _, balances := range [][]solana.TokenBalance{tx.Meta.PreTokenBalances, tx.Meta.PostTokenBalances} {
+		for _, b := range balances {
+			decimals[b.Mint] = int32(b.UITokenAmount.Decimals)
+		}
+	}
+	for mint := range decimals {
+		delta := tokenDeltas(tx, func(b solana.TokenBalance) bool { return b.Mint == mint && b.Owner == wallet })[wallet]
+		if delta != nil {
+			deltas = append(deltas, assetDelta{asset: mint, amount: delta, decimals: decimals[mint]})
+		}
+	}
+	if spent := lamportsSpent(tx, wallet); spent.Sign() > 0 {
+		deltas = append(deltas, assetDelta{asset: NativeAsset, amount: spent.Neg(spent), decimals: solanaNativeDecimals})
+	} else if received := lamportsReceived(tx, wallet); received.Sign() > 0 {
+		deltas = append(deltas, assetDelta{asset: NativeAsset, amount: received, decimals: solanaNativeDecimals})
+	}
+
+	from, to, ok := pickSwap(deltas)
+	if !ok {
+		return nil, ErrNoSwap
+	}
+	return &Swap{
+		Chain:         chain.Solana,
+		TxHash:        signature,
+		Wallet:        wallet,
+		FromAsset:     from.asset,
+		ToAsset:       to.asset,
+		FromAmount:    money.FromBaseUnits(from.amount, from.decimals),
+		ToAmount:      money.FromBaseUnits(to.amount, to.decimals),
+		BlockNumber:   tx.Slot,
+		Confirmations: confirmations(head, tx.Slot),
+	}, nil
+}
+
+// solanaTransaction 查询执行成功的交易和当前 slot
+func solanaTransaction(ctx context.Context, c *solana.Client, signature string) (*solana.Transaction, uint64, error) {
+	tx, err := c.RawTransaction(ctx, signature)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tx.Failed() || tx.Meta == nil {
+		return nil, 0, ErrTxFailed
+	}
+	head, err := c.BlockHeight(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tx, head, nil
+}
+
+// tokenDeltas 按 owner 汇总满足条件的 token 账户在交易前后的余额变化
+func tokenDeltas(tx *solana.Transaction, match func(solana.TokenBalance) bool) map[string]*big.Int {
+	deltas := map[string]*big.Int{}
+	apply := func(balances []solana.TokenBalance, sign int64) {
+		for _, b := range balances {
+			if !match(b) || b.Owner == "" {
+				continue
+			}
+			amount, ok := new(big.Int).SetString(b.UITokenAmount.Amount, 10)
+			if !ok {
+				continue
+			}
+			if deltas[b.Owner] == nil {
+				deltas[b.Owner] = new(big.Int)
+			}
+			deltas[b.Owner].Add(deltas[b.Owner], amount.Mul(amount, big.NewInt(sign)))
+		}
+	}
+	apply(tx.Meta.PreTokenBalances, -1)
+	apply(tx.Meta.PostTokenBalances, 1)
+	return deltas
+}
+
+// lamportsChange 账户在交易中的 SOL 变化，账户为手续费支付者时不计手续费
+func lamportsChange(tx *solana.Transaction, account string) *big.Int {
+	m := tx.Meta
+	for i, key := range tx.Transaction.Message.AccountKeys {
+		if key.Pubkey != account || i >= len(m.PreBalances) || i >= len(m.PostBalances) {
+			continue
+		}
+		change := new(big.Int).SetUint64(m.PostBalances[i])
+		change.Sub(change, new(big.Int).SetUint64(m.PreBalances[i]))
+		if i == 0 {
+			change.Add(change, new(big.Int).SetUint64(m.Fee))
+		}
+		return change
+	}
+	return new(big.Int)
+}
+
+// lamportsSpent 账户减少的 lamports，增加时为 0
+func lamportsSpent(tx *solana.Transaction, account string) *big.Int {
+	change := lamportsChange(tx, account)
+	if change.Sign() >= 0 {
+		return new(big.Int)
+	}
+	return change.Neg(change)
+}
+
+// lamportsReceived 账户增加的 lamports，减少时为 0
+func lamportsReceived(tx *solana.Transaction, account string) *big.Int {
+	change := lamportsChange(tx, account)
+	if change.Sign() <= 0 {
+		return new(big.Int)
+	}
+	return change
+}
@@ -0,0 +1,237 @@
+package settlement
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/solana"
+	"RESTful-API/internal/chain/solana/solanatest"
+	"RESTful-API/internal/money"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mr-tron/base58"
+	"testing"
+)
+
+// solanaKey 由同一个字节填充的 32 字节公钥，测试中用作各种地址
+func solanaKey(b byte) string {
+	return base58.Encode(bytes.Repeat([]byte{b}, 32))
+}
+
+var (
+	solMint      = solanaKey(1)
+	solUSDC      = solanaKey(2)
+	solBONK      = solanaKey(3)
+	solSeller    = solanaKey(4)
+	solBuyer     = solanaKey(5)
+	solSellerATA = solanaKey(6)
+	solBuyerATA  = solanaKey(7)
+	solPool      = solanaKey(8)
+)
+
+const (
+	solFee     = 5000
+	solATARent = 2039280 // 新建 token 账户的租金
+	sol        = 1000000000
+)
+
+// solAccount 交易涉及的账户及其交易前后的 lamports
+type solAccount struct {
+	key       string
+	pre, post uint64
+}
+
+func solBalance(index int, mint, owner, amount string, decimals int) solana.TokenBalance {
+	b := solana.TokenBalance{AccountIndex: index, Mint: mint, Owner: owner}
+	b.UITokenAmount.Amount, b.UITokenAmount.Decimals = amount, decimals
+	return b
+}
+
+// solTx 构造交易，accounts[0] 为手续费支付者
+func solTx(accounts []solAccount, pre, post []solana.TokenBalance) *solana.Transaction {
+	tx := &solana.Transaction{Meta: &solana.TransactionMeta{
+		Err:               json.RawMessage("null"),
+		Fee:               solFee,
+		PreTokenBalances:  pre,
+		PostTokenBalances: post,
+	}}
+	for i, a := range accounts {
+		tx.Transaction.Message.AccountKeys = append(tx.Transaction.Message.AccountKeys, solana.AccountKey{Pubkey: a.key, Signer: i == 0})
+		tx.Meta.PreBalances = append(tx.Meta.PreBalances, a.pre)
+		tx.Meta.PostBalances = append(tx.Meta.PostBalances, a.post)
+	}
+	return tx
+}
+
+// nftSale 卖方的 token 账户转出 mint，买方新建 token 账户收到
+func nftSale(mint string, accounts ...solAccount) *solana.Transaction {
+	return solTx(accounts,
+		[]solana.TokenBalance{solBalance(2, mint, solSeller, "1", 0)},
+		[]solana.TokenBalance{solBalance(2, mint, solSeller, "0", 0), solBalance(3, mint, solBuyer, "1", 0)})
+}
+
+func TestDecodeSolanaTrade(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	ctx := context.Background()
+	client := node.Client()
+
+	tests := []struct {
+		name  string
+		tx    *solana.Transaction
+		price string
+		err   error
+	}{
+		{
+			// 买方支付手续费，成交价包含为买方新建 token 账户的租金
+			name: "buyer pays",
+			tx: nftSale(solMint,
+				solAccount{solBuyer, 10 * sol, 8*sol - solATARent - solFee},
+				solAccount{solSeller, sol, 3 * sol},
+				solAccount{solSellerATA, solATARent, solATARent},
+				solAccount{solBuyerATA, 0, solATARent}),
+			price: "2.00203928",
+		},
+		{
+			name: "seller pays fee",
+			tx: nftSale(solMint,
+				solAccount{solSeller, sol, 2*sol - solFee},
+				solAccount{solBuyer, 10 * sol, 9 * sol},
+				solAccount{solSellerATA, solATARent, solATARent},
+				solAccount{solBuyerATA, solATARent, solATARent}),
+			price: "1",
+		},
+		{
+			name: "other mint",
+			tx: nftSale(solUSDC,
+				solAccount{solBuyer, 10 * sol, 8 * sol},
+				solAccount{solSeller, sol, 3 * sol}),
+			err: ErrNoTrade,
+		},
+		{
+			// 只有买方余额变化，找不到卖方
+			name: "mint to buyer",
+			tx: solTx([]solAccount{{solBuyer, sol, sol - solFee}}, nil,
+				[]solana.TokenBalance{solBalance(3, solMint, solBuyer, "1", 0)}),
+			err: ErrNoTrade,
+		},
+	}
+	for i, tt := range tests {
+		signature := solanaKey(byte(100 + i))
+		node.AddTransaction(signature, tt.tx)
+		head := node.Advance(3)
+		trade, err := decodeSolanaTrade(ctx, client, signature, solMint)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if trade.Chain != chain.Solana || trade.TxHash != signature || trade.Contract != solMint || trade.TokenID != solMint ||
+			trade.Seller != solSeller || trade.Buyer != solBuyer || !trade.Price.Equal(money.MustParse(tt.price)) ||
+			trade.BlockNumber != head-3 || trade.Confirmations != 4 {
+			t.Errorf("%s: unexpected trade %+v", tt.name, trade)
+		}
+	}
+}
+
+func TestDecodeSolanaTransactionState(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	ctx := context.Background()
+	client := node.Client()
+
+	failed := nftSale(solMint, solAccount{solBuyer, 10 * sol, 10*sol - solFee})
+	failed.Meta.Err = json.RawMessage(`{"InstructionError":[0,{"Custom":1}]}`)
+	node.AddTransaction(solanaKey(100), failed)
+
+	tests := []struct {
+		signature string
+		err       error
+	}{
+		{solanaKey(100), ErrTxFailed},
+		{solanaKey(101), chain.ErrTxNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := decodeSolanaTrade(ctx, client, tt.signature, solMint); !errors.Is(err, tt.err) {
+			t.Errorf("trade %s: got %v, want %v", tt.signature, err, tt.err)
+		}
+		if _, err := decodeSolanaSwap(ctx, client, tt.signature, solBuyer); !errors.Is(err, tt.err) {
+			t.Errorf("swap %s: got %v, want %v", tt.signature, err, tt.err)
+		}
+	}
+}
+
+func TestDecodeSolanaSwap(t *testing.T) {
+	node := solanatest.NewNode()
+	defer node.Close()
+	ctx := context.Background()
+	client := node.Client()
+
+	// 钱包的 USDC 和 BONK 账户分别为 1、2，池子的账户为 3、4；池子的余额变化不计入钱包
+	swapTx := func(lamports solAccount, usdcPre, usdcPost, bonkPre, bonkPost string) *solana.Transaction {
+		return solTx([]solAccount{lamports, {solBuyerATA, solATARent, solATARent}, {solSellerATA, solATARent, solATARent}, {solPool, 100 * sol, 100 * sol}},
+			[]solana.TokenBalance{
+				solBalance(1, solUSDC, solBuyer, usdcPre, 6), solBalance(2, solBONK, solBuyer, bonkPre, 5),
+				solBalance(3, solUSDC, solPool, "1000000000", 6), solBalance(4, solBONK, solPool, "100000000000", 5),
+			},
+			[]solana.TokenBalance{
+				solBalance(1, solUSDC, solBuyer, usdcPost, 6), solBalance(2, solBONK, solBuyer, bonkPost, 5),
+				solBalance(3, solUSDC, solPool, "1100000000", 6), solBalance(4, solBONK, solPool, "99500000000", 5),
+			})
+	}
+	tests := []struct {
+		name       string
+		tx         *solana.Transaction
+		fromAsset  string
+		fromAmount string
+		toAsset    string
+		toAmount   string
+		err        error
+	}{
+		{
+			// 钱包只支付了手续费，SOL 不计入
+			name:      "usdc to bonk",
+			tx:        swapTx(solAccount{solBuyer, sol, sol - solFee}, "100000000", "0", "0", "500000000"),
+			fromAsset: solUSDC, fromAmount: "100", toAsset: solBONK, toAmount: "5000",
+		},
+		{
+			name:      "sol to usdc",
+			tx:        swapTx(solAccount{solBuyer, 5 * sol, 4*sol - solFee}, "0", "150000000", "0", "0"),
+			fromAsset: NativeAsset, fromAmount: "1", toAsset: solUSDC, toAmount: "150",
+		},
+		{
+			name:      "bonk to sol",
+			tx:        swapTx(solAccount{solBuyer, sol, sol + sol/2 - solFee}, "0", "0", "500000000", "0"),
+			fromAsset: solBONK, fromAmount: "5000", toAsset: NativeAsset, toAmount: "0.5",
+		},
+		{
+			name: "nothing swapped",
+			tx:   swapTx(solAccount{solBuyer, sol, sol - solFee}, "1", "1", "0", "0"),
+			err:  ErrNoSwap,
+		},
+		{
+			// 两种代币都增加，没有转出的资产
+			name: "two assets in",
+			tx:   swapTx(solAccount{solSeller, sol, sol - solFee}, "0", "1", "0", "1"),
+			err:  ErrNoSwap,
+		},
+	}
+	for i, tt := range tests {
+		signature := solanaKey(byte(100 + i))
+		node.AddTransaction(signature, tt.tx)
+		swap, err := decodeSolanaSwap(ctx, client, signature, solBuyer)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if swap.TxHash != signature || swap.Wallet != solBuyer || swap.FromAsset != tt.fromAsset || swap.ToAsset != tt.toAsset ||
+			!swap.FromAmount.Equal(money.MustParse(tt.fromAmount)) || !swap.ToAmount.Equal(money.MustParse(tt.toAmount)) {
+			t.Errorf("%s: unexpected swap %+v", tt.name, swap)
+		}
+	}
+}
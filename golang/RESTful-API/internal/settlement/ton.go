@@ -0,0 +1,75 @@
+package settlement
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/ton"
+	"RESTful-API/internal/money"
+	"context"
+	"math/big"
+)
+
+// decodeTONTrade 在交易所在的 trace 中查找 item 的所有权变化，卖方和买方见 ton.DecodeItemTransfer；
+// 成交价为买方钱包在 trace 中转出的 TON 减去退回的部分（包含转发给合约的手续费）。
+// trace 中任意一笔交易的哈希都能查到同一笔成交，TxHash 统一取 item 所有权变化的那笔交易，与索引器写入的一致
+func decodeTONTrade(ctx context.Context, c *ton.Client, hash, collection, tokenID string) (*Trade, error) {
+	item, err := c.NFTItem(ctx, collection, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := c.Trace(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		seller, buyer string
+		itemTx        *ton.Transaction
+	)
+	for i := range txs {
+		if account, _ := chain.NormalizeAddress(chain.TON, txs[i].Account); account != item.Address {
+			continue
+		}
+		if from, to, ok := ton.DecodeItemTransfer(txs[i], item.Collection); ok && from != "" {
+			seller, buyer, itemTx = from, to, &txs[i]
+		}
+	}
+	if itemTx == nil {
+		return nil, ErrNoTrade
+	}
+
+	paid := new(big.Int)
+	for _, tx := range txs {
+		if account, _ := chain.NormalizeAddress(chain.TON, tx.Account); account != buyer {
+			continue
+		}
+		for _, msg := range tx.OutMsgs {
+			if v, ok := new(big.Int).SetString(msg.Value, 10); ok {
+				paid.Add(paid, v)
+			}
+		}
+		if tx.InMsg != nil && tx.InMsg.Source != "" {
+			if v, ok := new(big.Int).SetString(tx.InMsg.Value, 10); ok {
+				paid.Sub(paid, v)
+			}
+		}
+	}
+	if paid.Sign() < 0 {
+		paid.SetInt64(0)
+	}
+
+	head, err := c.BlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Trade{
+		Chain:         chain.TON,
+		TxHash:        itemTx.Hash,
+		Contract:      item.Collection,
+		TokenID:       item.Index,
+		Seller:        seller,
+		Buyer:         buyer,
+		Price:         money.FromBaseUnits(paid, tonNativeDecimals),
+		BlockNumber:   itemTx.McBlockSeqno,
+		Confirmations: confirmations(head, itemTx.McBlockSeqno),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package settlement
+
+import (
+	"RESTful-API/internal/chain"
+	"RESTful-API/internal/chain/ton"
+	"RESTful-API/internal/chain/ton/tontest"
+	"RESTful-API/internal/money"
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"strings"
+	"testing"
+)
+
+var (
+	tonCollection = "0:" + strings.Repeat("c1", 32)
+	tonItem       = "0:" + strings.Repeat("a0", 32)
+	tonSale       = "0:" + strings.Repeat("5a", 32) // 市场的销售合约
+	tonMarket     = "0:" + strings.Repeat("fe", 32)
+	tonSeller     = "0:" + strings.Repeat("e1", 32)
+	tonBuyer      = "0:" + strings.Repeat("e2", 32)
+)
+
+func tonBody(b *cell.Builder) string {
+	return base64.StdEncoding.EncodeToString(b.EndCell().ToBOC())
+}
+
+func transferBody(newOwner string) string {
+	return tonBody(cell.BeginCell().MustStoreUInt(uint64(ton.OpNFTTransfer), 32).MustStoreUInt(1, 64).
+		MustStoreAddr(address.MustParseRawAddr(newOwner)).MustStoreAddr(address.MustParseRawAddr(newOwner)))
+}
+
+func ownershipAssignedBody(prevOwner string) string {
+	return tonBody(cell.BeginCell().MustStoreUInt(uint64(ton.OpOwnershipAssigned), 32).MustStoreUInt(1, 64).
+		MustStoreAddr(address.MustParseRawAddr(prevOwner)).MustStoreBoolBit(false))
+}
+
+// tonMessage toncenter 返回大写的原始地址，source 为空时为外部消息
+func tonMessage(source, dest, value, body string) *ton.Message {
+	m := &ton.Message{Source: strings.ToUpper(source), Destination: strings.ToUpper(dest), Value: value}
+	m.MessageContent.Body = body
+	return m
+}
+
+// addSale 按销售合约的流程写入一个 trace：买方付款给销售合约，销售合约分账并让 item 转给买方，
+// item 通知买方并退回多余的 TON。返回买方钱包和 item 上的交易
+func addSale(api *tontest.API, traceID string, itemAborted bool) (buyerTx, itemTx ton.Transaction) {
+	buyerTx = api.AddTransaction(ton.Transaction{
+		Account: strings.ToUpper(tonBuyer),
+		TraceID: traceID,
+		InMsg:   tonMessage("", tonBuyer, "0", ""),
+		OutMsgs: []ton.Message{*tonMessage(tonBuyer, tonSale, "2100000000", "")},
+	})
+	api.AddTransaction(ton.Transaction{
+		Account: strings.ToUpper(tonSale),
+		TraceID: traceID,
+		InMsg:   tonMessage(tonBuyer, tonSale, "2100000000", ""),
+		OutMsgs: []ton.Message{
+			*tonMessage(tonSale, tonSeller, "1900000000", ""),
+			*tonMessage(tonSale, tonMarket, "100000000", ""),
+			*tonMessage(tonSale, tonItem, "100000000", transferBody(tonBuyer)),
+		},
+	})
+	item := ton.Transaction{
+		Account: strings.ToUpper(tonItem),
+		TraceID: traceID,
+		InMsg:   tonMessage(tonSale, tonItem, "100000000", transferBody(tonBuyer)),
+		OutMsgs: []ton.Message{*tonMessage(tonItem, tonBuyer, "50000000", ownershipAssignedBody(tonSeller))},
+	}
+	item.Description.Aborted = itemAborted
+	itemTx = api.AddTransaction(item)
+	api.AddTransaction(ton.Transaction{
+		Account: strings.ToUpper(tonBuyer),
+		TraceID: traceID,
+		InMsg:   tonMessage(tonItem, tonBuyer, "50000000", ownershipAssignedBody(tonSeller)),
+	})
+	return buyerTx, itemTx
+}
+
+func TestDecodeTONTrade(t *testing.T) {
+	api := tontest.NewAPI()
+	defer api.Close()
+	ctx := context.Background()
+	client := api.Client()
+	api.SetNFTItem(ton.NFTItem{Address: tonItem, Collection: tonCollection, Index: "3", Owner: tonBuyer})
+
+	api.Advance(1)
+	buyerTx, itemTx := addSale(api, "sale", false)
+	// 卖方直接转给买方，没有付款
+	gift := api.AddTransaction(ton.Transaction{
+		Account: strings.ToUpper(tonItem),
+		InMsg:   tonMessage(tonSeller, tonItem, "100000000", transferBody(tonBuyer)),
+		OutMsgs: []ton.Message{*tonMessage(tonItem, tonBuyer, "1", ownershipAssignedBody(tonSeller))},
+	})
+	abortedBuyerTx, _ := addSale(api, "aborted", true)
+	payment := api.AddTransaction(ton.Transaction{
+		Account: strings.ToUpper(tonBuyer),
+		InMsg:   tonMessage("", tonBuyer, "0", ""),
+		OutMsgs: []ton.Message{*tonMessage(tonBuyer, tonSeller, "1000000000", "")},
+	})
+	head := api.Advance(2)
+
+	tests := []struct {
+		name  string
+		hash  string
+		price string
+		err   error
+	}{
+		// trace 中任意一笔交易都能查到同一笔成交，成交价为买方转出的 2.1 TON 减去 item 退回的 0.05 TON
+		{name: "by buyer tx", hash: buyerTx.Hash, price: "2.05"},
+		{name: "by item tx", hash: itemTx.Hash, price: "2.05"},
+		{name: "gift", hash: gift.Hash, price: "0"},
+		{name: "aborted", hash: abortedBuyerTx.Hash, err: ErrNoTrade},
+		{name: "no item transfer", hash: payment.Hash, err: ErrNoTrade},
+		{name: "unknown", hash: "ffffffff", err: chain.ErrTxNotFound},
+	}
+	for _, tt := range tests {
+		trade, err := decodeTONTrade(ctx, client, tt.hash, tonCollection, "3")
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if trade.Chain != chain.TON || trade.Contract != tonCollection || trade.TokenID != "3" ||
+			trade.Seller != tonSeller || trade.Buyer != tonBuyer || !trade.Price.Equal(money.MustParse(tt.price)) ||
+			trade.BlockNumber != head-2 || trade.Confirmations != 3 {
+			t.Errorf("%s: unexpected trade %+v", tt.name, trade)
+		}
+	}
+
+	// TxHash 统一为 item 所有权变化的交易
+	trade, err := decodeTONTrade(ctx, client, buyerTx.Hash, tonCollection, "3")
+	if err != nil || trade.TxHash != itemTx.Hash {
+		t.Fatalf("trade tx hash: %+v, %v", trade, err)
+	}
+	if _, err = decodeTONTrade(ctx, client, buyerTx.Hash, tonCollection, "4"); !errors.Is(err, chain.ErrNotFound) {
+		t.Fatalf("unknown item: got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+// LocalOptions 本地文件系统存储配置
+type LocalOptions struct {
+	Dir       string // 存放文件的根目录
+	PublicURL string // 对外访问的地址前缀，以 / 开头时由本服务提供静态文件
+}
+
+// Local 把对象保存为 Dir 下的文件，Content-Type 由扩展名推断
+type Local struct {
+	opts LocalOptions
+}
+
+// NewLocal 创建本地存储，根目录不存在时自动创建
+func NewLocal(opts LocalOptions) (*Local, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("storage.local.dir is empty")
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{opts: opts}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.opts.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put 先写临时文件再重命名，读取方不会看到写了一半的文件
+func (l *Local) Put(_ context.Context, key, _ string, data []byte) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (l *Local) Get(_ context.Context, key string) ([]byte, string, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(p))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return data, contentType, nil
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return joinURL(l.opts.PublicURL, key)
+}
+
+// Dir 文件根目录
+func (l *Local) Dir() string {
+	return l.opts.Dir
+}
@@ -0,0 +1,85 @@
+package storage_test
+
+import (
+	"RESTful-API/internal/storage"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads")
+	s, err := storage.NewLocal(storage.LocalOptions{Dir: dir, PublicURL: "/uploads/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err = s.Put(ctx, "images/ab/abcd.png", "image/png", []byte("png")); err != nil {
+		t.Fatal(err)
+	}
+	// 覆盖已有的对象
+	if err = s.Put(ctx, "images/ab/abcd.png", "image/png", []byte("png2")); err != nil {
+		t.Fatal(err)
+	}
+	data, contentType, err := s.Get(ctx, "images/ab/abcd.png")
+	if err != nil || string(data) != "png2" || contentType != "image/png" {
+		t.Fatalf("got %q, %q, %v", data, contentType, err)
+	}
+	// 临时文件已经被重命名，目录中只有目标文件
+	if entries, _ := os.ReadDir(filepath.Join(dir, "images", "ab")); len(entries) != 1 {
+		t.Fatalf("got %d files, want 1", len(entries))
+	}
+	if got := s.URL("images/ab/abcd.png"); got != "/uploads/images/ab/abcd.png" {
+		t.Fatalf("url %s", got)
+	}
+
+	if err = s.Put(ctx, "data", "", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if _, contentType, _ = s.Get(ctx, "data"); contentType != "application/octet-stream" {
+		t.Fatalf("content type %q", contentType)
+	}
+
+	if err = s.Delete(ctx, "images/ab/abcd.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = s.Get(ctx, "images/ab/abcd.png"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+	if err = s.Delete(ctx, "images/ab/abcd.png"); err != nil {
+		t.Fatal(err)
+	}
+	// 不能写到根目录之外
+	if err = s.Put(ctx, "../escape", "", []byte("x")); !errors.Is(err, storage.ErrInvalidKey) {
+		t.Fatalf("got %v, want ErrInvalidKey", err)
+	}
+	if _, err = os.Stat(filepath.Join(filepath.Dir(dir), "escape")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("file written outside the root: %v", err)
+	}
+}
+
+func TestCheckKey(t *testing.T) {
+	tests := []struct {
+		key string
+		ok  bool
+	}{
+		{"a.png", true},
+		{"images/ab/abcd.png", true},
+		{"images/..png", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"images//a.png", false},
+		{"images/", false},
+		{"./a.png", false},
+		{"images/../../a.png", false},
+		{`images\..\a.png`, false},
+	}
+	for _, tt := range tests {
+		if err := storage.CheckKey(tt.key); (err == nil) != tt.ok {
+			t.Errorf("CheckKey(%q) = %v, want ok %v", tt.key, err, tt.ok)
+		}
+	}
+}
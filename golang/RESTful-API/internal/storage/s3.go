@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"RESTful-API/internal/tracing"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sigAlgorithm = "AWS4-HMAC-SHA256"
+	amzDate      = "20060102T150405Z"
+	// maxErrorBody 读取错误响应的最大字节数
+	maxErrorBody = 4 << 10
+)
+
+// S3Options S3 兼容对象存储（AWS S3、MinIO、R2 等）的配置
+type S3Options struct {
+	Endpoint  string // 服务地址，例如 https://s3.us-east-1.amazonaws.com 或 http://127.0.0.1:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle 为 true 时使用 endpoint/bucket/key，否则使用 bucket.endpoint/key，MinIO 一般需要 path style
+	PathStyle bool
+	// PublicURL 对外访问的地址前缀，例如 CDN 地址，为空时使用对象的请求地址
+	PublicURL string
+	Timeout   time.Duration
+}
+
+// S3 使用 Signature V4 签名访问 S3 兼容的对象存储
+type S3 struct {
+	opts   S3Options
+	base   *url.URL
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 创建 S3 存储
+func NewS3(opts S3Options) (*S3, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" || opts.AccessKey == "" || opts.SecretKey == "" {
+		return nil, errors.New("storage.S3 endpoint, bucket, accessKey and secretKey are required")
+	}
+	base, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || base.Host == "" || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("invalid storage.S3.endpoint %q", opts.Endpoint)
+	}
+	if !opts.PathStyle {
+		base.Host = opts.Bucket + "." + base.Host
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	return &S3{
+		opts: opts,
+		base: base,
+		client: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: tracing.NewTransport(http.DefaultTransport, "s3"),
+		},
+		now: time.Now,
+	}, nil
+}
+
+// objectURL 对象的请求地址，key 的每一段按 URI 规则转义
+func (s *S3) objectURL(key string) string {
+	u := *s.base
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = uriEscape(seg)
+	}
+	path := "/" + strings.Join(segments, "/")
+	if s.opts.PathStyle {
+		path = "/" + uriEscape(s.opts.Bucket) + path
+	}
+	u.RawPath = strings.TrimRight(u.Path, "/") + path
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	return u.String()
+}
+
+func (s *S3) Put(ctx context.Context, key, contentType string, data []byte) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, header, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.statusError(resp)
+	}
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) ([]byte, string, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, "", err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, http.Header{}, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", s.statusError(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, http.Header{}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.statusError(resp)
+	}
+	return nil
+}
+
+func (s *S3) URL(key string) string {
+	if s.opts.PublicURL != "" {
+		return joinURL(s.opts.PublicURL, key)
+	}
+	return s.objectURL(key)
+}
+
+func (s *S3) do(ctx context.Context, method, key string, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.ContentLength = int64(len(body))
+	Sign(req, body, s.opts.Region, s.opts.AccessKey, s.opts.SecretKey, s.now())
+	return s.client.Do(req)
+}
+
+func (s *S3) statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return fmt.Errorf("s3 %s %s: status %d: %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, bytes.TrimSpace(body))
+}
+
+// Sign 按 AWS Signature V4 为 S3 请求签名，签名覆盖 Host、Content-Type 和全部 x-amz-* 头
+func Sign(req *http.Request, body []byte, region, accessKey, secretKey string, now time.Time) {
+	now = now.UTC()
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", now.Format(amzDate))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	scope := now.Format("20060102") + "/" + region + "/s3/aws4_request"
+	canonical, signedHeaders := canonicalRequest(req, payloadHash)
+	stringToSign := sigAlgorithm + "\n" + now.Format(amzDate) + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigAlgorithm, accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalRequest 规范请求和参与签名的请求头列表
+func canonicalRequest(req *http.Request, payloadHash string) (string, string) {
+	headers := map[string]string{"host": req.Host}
+	if headers["host"] == "" {
+		headers["host"] = req.URL.Host
+	}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			params = append(params, uriEscape(k)+"="+uriEscape(v))
+		}
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.Join(params, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+// uriEscape 按 SigV4 规则转义：只保留 A-Z a-z 0-9 - _ . ~
+func uriEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,116 @@
+// Package storage 上传文件的对象存储，支持本地文件系统和 S3 兼容的对象存储
+package storage
+
+import (
+	"RESTful-API/utils/config"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage 对象存储，key 使用 / 分隔的相对路径，例如 images/ab/abcd.png
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get 读取对象和它的 Content-Type，不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// URL 对象的公开访问地址
+	URL(key string) string
+}
+
+var (
+	mu      sync.Mutex
+	current Storage
+)
+
+// Register 替换默认存储，测试中用于替换为本地目录或 storagetest.S3
+func Register(s Storage) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = s
+}
+
+// Default 返回默认存储，首次调用时按 storage.ini 创建
+func Default() (Storage, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil {
+		return current, nil
+	}
+	s, err := FromConfig()
+	if err != nil {
+		return nil, err
+	}
+	current = s
+	return s, nil
+}
+
+// FromConfig 按 storage.ini 中的 storage.backend 创建存储
+func FromConfig() (Storage, error) {
+	switch backend := config.GetConfig("storage.backend").MustString("local"); backend {
+	case "local":
+		return NewLocal(LocalConfigFromConfig())
+	case "s3":
+		return NewS3(S3Options{
+			Endpoint:  config.GetConfig("storage.S3.endpoint").String(),
+			Region:    config.GetConfig("storage.S3.region").MustString("us-east-1"),
+			Bucket:    config.GetConfig("storage.S3.bucket").String(),
+			AccessKey: config.GetConfig("storage.S3.accessKey").String(),
+			SecretKey: config.GetConfig("storage.S3.secretKey").String(),
+			PathStyle: config.GetConfig("storage.S3.pathStyle").MustBool(true),
+			PublicURL: config.GetConfig("storage.S3.publicURL").String(),
+			Timeout:   time.Duration(config.GetConfig("storage.timeout").MustInt(30000)) * time.Millisecond,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// LocalConfigFromConfig 读取 storage.ini 中本地存储的配置
+func LocalConfigFromConfig() LocalOptions {
+	return LocalOptions{
+		Dir:       config.GetConfig("storage.local.dir").MustString("./uploads"),
+		PublicURL: config.GetConfig("storage.local.publicURL").MustString("/uploads"),
+	}
+}
+
+// CheckKey 校验 key，拒绝绝对路径、空段和 ..，避免本地存储写到目录之外
+func CheckKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// joinURL 拼接公开地址和 key
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
+
+// LocalStatic 使用本地存储且公开地址是本服务的路径时，返回路由前缀和文件目录
+func LocalStatic() (string, string, bool) {
+	if config.GetConfig("storage.backend").MustString("local") != "local" {
+		return "", "", false
+	}
+	opts := LocalConfigFromConfig()
+	if !strings.HasPrefix(opts.PublicURL, "/") || strings.HasPrefix(opts.PublicURL, "//") {
+		return "", "", false
+	}
+	prefix := strings.TrimRight(opts.PublicURL, "/")
+	return prefix, opts.Dir, prefix != ""
+}
@@ -0,0 +1,186 @@
+// Package storagetest 测试用的本地 S3 替身
+package storagetest
+
+import (
+	"RESTful-API/internal/storage"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// amzDate X-Amz-Date 的时间格式
+const amzDate = "20060102T150405Z"
+
+// authPattern 解析 Signature V4 的 Authorization 头
+var authPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([^,]+), Signature=([0-9a-f]{64})$`)
+
+type object struct {
+	data        []byte
+	contentType string
+}
+
+// S3 本地 S3 替身，在内存中保存对象，只支持 path style 的 PutObject、GetObject、DeleteObject，
+// 会按同样的密钥校验请求签名和 x-amz-content-sha256
+type S3 struct {
+	server    *httptest.Server
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+
+	mu      sync.Mutex
+	objects map[string]object
+}
+
+// NewS3 启动替身服务，只有 bucket 一个存储桶
+func NewS3(bucket string) *S3 {
+	f := &S3{
+		region:    "us-east-1",
+		bucket:    bucket,
+		accessKey: "fake-access-key",
+		secretKey: "fake-secret-key",
+		objects:   make(map[string]object),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+// URL 服务地址
+func (f *S3) URL() string {
+	return f.server.URL
+}
+
+// Options 连接到替身服务的配置
+func (f *S3) Options() storage.S3Options {
+	return storage.S3Options{
+		Endpoint:  f.server.URL,
+		Region:    f.region,
+		Bucket:    f.bucket,
+		AccessKey: f.accessKey,
+		SecretKey: f.secretKey,
+		PathStyle: true,
+	}
+}
+
+// Client 连接到替身服务的存储
+func (f *S3) Client() *storage.S3 {
+	s, _ := storage.NewS3(f.Options())
+	return s
+}
+
+// Object 直接读取保存的对象
+func (f *S3) Object(key string) ([]byte, string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o, ok := f.objects[key]
+	return o.data, o.contentType, ok
+}
+
+// Len 已保存的对象数量
+func (f *S3) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.objects)
+}
+
+// Close 关闭替身服务
+func (f *S3) Close() {
+	f.server.Close()
+}
+
+func (f *S3) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.fail(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+	if code := f.verify(r, body); code != "" {
+		f.fail(w, http.StatusForbidden, code)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket != f.bucket {
+		f.fail(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	if key == "" {
+		f.fail(w, http.StatusBadRequest, "InvalidRequest")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = object{data: body, contentType: r.Header.Get("Content-Type")}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		o, ok := f.objects[key]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if o.contentType != "" {
+			w.Header().Set("Content-Type", o.contentType)
+		}
+		w.Write(o.data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// verify 重新计算签名并比较，返回 S3 的错误码，校验通过时返回空
+func (f *S3) verify(r *http.Request, body []byte) string {
+	m := authPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return "AccessDenied"
+	}
+	if m[1] != f.accessKey || m[3] != f.region {
+		return "InvalidAccessKeyId"
+	}
+	if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+		return "XAmzContentSHA256Mismatch"
+	}
+	date, err := time.Parse(amzDate, r.Header.Get("X-Amz-Date"))
+	if err != nil || date.Format("20060102") != m[2] {
+		return "AccessDenied"
+	}
+
+	// 只用客户端声明签名的请求头重新签名
+	signed := &http.Request{Method: r.Method, Host: r.Host, URL: &url.URL{Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}, Header: http.Header{}}
+	for _, name := range strings.Split(m[4], ";") {
+		if name != "host" {
+			signed.Header[http.CanonicalHeaderKey(name)] = r.Header.Values(name)
+		}
+	}
+	storage.Sign(signed, body, f.region, f.accessKey, f.secretKey, date)
+	want := authPattern.FindStringSubmatch(signed.Header.Get("Authorization"))
+	if want == nil || want[4] != m[4] || !hmac.Equal([]byte(want[5]), []byte(m[5])) {
+		return "SignatureDoesNotMatch"
+	}
+	return ""
+}
+
+func (f *S3) fail(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code></Error>", code)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,89 @@
+// Package tracing OpenTelemetry 链路追踪
+//
+// 链路由三部分组成：HTTP 中间件为每个请求创建 server span，
+// gorm 插件为每次数据库操作创建子 span，调用链上 RPC 时通过 NewTransport 创建 client span。
+// 上下游之间使用 W3C traceparent 传递链路信息。导出方式和采样率见 configs/trace.ini。
+package tracing
+
+import (
+	"RESTful-API/utils/config"
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const instrumentationName = "RESTful-API"
+
+var provider *sdktrace.TracerProvider
+
+// Init 按 trace.ini 初始化全局 TracerProvider。
+// 没有开启时不设置 provider，otel 默认的 noop 实现不会产生任何开销。
+func Init() error {
+	// 无论是否开启都解析 traceparent，保证上游的链路信息可以继续向下游传递
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !config.GetConfig("trace.enable").MustBool(false) {
+		return nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch config.GetConfig("trace.exporter").MustString("stdout") {
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.GetConfig("trace.otlp.endpoint").MustString("127.0.0.1:4318"))}
+		if config.GetConfig("trace.otlp.insecure").MustBool(false) {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	}
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(config.GetConfig("trace.serviceName").MustString(instrumentationName)),
+	))
+	if err != nil {
+		return err
+	}
+
+	// 上游已经决定采样的请求跟随上游，否则按比例采样
+	rate := config.GetConfig("trace.sampleRate").MustFloat64(1)
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(rate))),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// Shutdown 导出剩余的 span，服务退出前调用
+func Shutdown() {
+	if provider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = provider.Shutdown(ctx)
+}
+
+// Tracer 返回本服务使用的 tracer，provider 在 Init 之后设置也能生效
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 创建子 span，供业务代码标记耗时较长的步骤
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
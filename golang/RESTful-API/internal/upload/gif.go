@@ -0,0 +1,82 @@
+package upload
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// GIF 数据块的标识
+const (
+	gifExtension = 0x21
+	gifImage     = 0x2C
+	gifTrailer   = 0x3B
+)
+
+// gifFrames 只扫描 GIF 的块结构统计帧数，不解压 LZW 数据。
+// gif.DecodeAll 会一次性解码全部帧，帧数和每帧的内存都要在解码前确定不超过限制
+func gifFrames(data []byte) (int, error) {
+	// 文件头 6 字节 + 逻辑屏幕描述 7 字节
+	if len(data) < 13 {
+		return 0, fmt.Errorf("%w: gif header truncated", ErrMalformed)
+	}
+	i := 13
+	if flags := data[10]; flags&0x80 != 0 { // 全局颜色表
+		i += 3 << (flags&0x07 + 1)
+	}
+
+	frames := 0
+	for i < len(data) {
+		switch data[i] {
+		case gifTrailer:
+			return frames, nil
+		case gifExtension:
+			if i+2 > len(data) {
+				return 0, fmt.Errorf("%w: gif extension truncated", ErrMalformed)
+			}
+			end, err := skipGIFSubBlocks(data, i+2)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		case gifImage:
+			// 图像描述 10 字节：标识、左上角坐标、宽高、标志位
+			if i+10 > len(data) {
+				return 0, fmt.Errorf("%w: gif image descriptor truncated", ErrMalformed)
+			}
+			if binary.LittleEndian.Uint16(data[i+5:]) == 0 || binary.LittleEndian.Uint16(data[i+7:]) == 0 {
+				return 0, fmt.Errorf("%w: gif frame is empty", ErrMalformed)
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 { // 局部颜色表
+				i += 3 << (flags&0x07 + 1)
+			}
+			i++ // LZW 最小码长
+			end, err := skipGIFSubBlocks(data, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+			frames++
+		default:
+			return 0, fmt.Errorf("%w: unknown gif block 0x%02x", ErrMalformed, data[i])
+		}
+	}
+	// 缺少结束标识，交给 gif.DecodeAll 报错
+	return frames, nil
+}
+
+// skipGIFSubBlocks 跳过从 i 开始的数据子块序列，返回结束块之后的位置
+func skipGIFSubBlocks(data []byte, i int) (int, error) {
+	for {
+		if i >= len(data) {
+			return 0, fmt.Errorf("%w: gif data truncated", ErrMalformed)
+		}
+		size := int(data[i])
+		i++
+		if size == 0 {
+			return i, nil
+		}
+		i += size
+	}
+}
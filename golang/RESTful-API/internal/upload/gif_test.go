@@ -0,0 +1,75 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// newGIF 生成 w×h、frames 帧的 GIF
+func newGIF(t *testing.T, w, h, frames int) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, w, h), palette)
+		frame.SetColorIndex(i%w, 0, 1)
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGIFFrames(t *testing.T) {
+	for _, n := range []int{1, 3, 40} {
+		frames, err := gifFrames(newGIF(t, 16, 8, n))
+		if err != nil {
+			t.Fatalf("%d frames: %v", n, err)
+		}
+		if frames != n {
+			t.Fatalf("got %d frames, want %d", frames, n)
+		}
+	}
+}
+
+func TestGIFFramesMalformed(t *testing.T) {
+	data := newGIF(t, 16, 8, 2)
+	for name, b := range map[string][]byte{
+		"header":    data[:10],
+		"truncated": data[:len(data)-6],
+		"block":     append(append([]byte{}, data[:len(data)-1]...), 0x99),
+	} {
+		if _, err := gifFrames(b); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: got %v, want ErrMalformed", name, err)
+		}
+	}
+}
+
+func TestProcessGIFLimits(t *testing.T) {
+	data := newGIF(t, 20, 10, 5)
+	tests := []struct {
+		name string
+		opts Options
+		want error
+	}{
+		{"ok", Options{MaxFrames: 5, MaxPixels: 1000}, nil},
+		{"frames", Options{MaxFrames: 4}, ErrDimensions},
+		{"pixels", Options{MaxPixels: 999}, ErrDimensions},
+	}
+	for _, tt := range tests {
+		img, err := Process(data, tt.opts)
+		if !errors.Is(err, tt.want) {
+			t.Fatalf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+		if err == nil && (img.ContentType != TypeGIF || img.Width != 20 || img.Height != 10) {
+			t.Fatalf("%s: got %s %dx%d", tt.name, img.ContentType, img.Width, img.Height)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package upload 处理用户上传的图片：按文件头识别类型，限制大小和尺寸，重新编码以去掉 EXIF 等元数据，并生成缩略图
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image too large")
+	ErrDimensions      = errors.New("image dimensions out of range")
+	ErrMalformed       = errors.New("malformed image")
+)
+
+// 支持的图片类型
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+)
+
+// extensions 各类型保存时使用的扩展名
+var extensions = map[string]string{
+	TypeJPEG: ".jpg",
+	TypePNG:  ".png",
+	TypeGIF:  ".gif",
+}
+
+// Options 图片处理配置
+type Options struct {
+	MaxSize    int64 // 文件的最大字节数
+	MaxWidth   int
+	MaxHeight  int
+	MaxFrames  int   // GIF 的最大帧数
+	MaxPixels  int64 // GIF 全部帧的最大像素数（宽 × 高 × 帧数），解码后每个像素占一个字节
+	ThumbSizes []int // 缩略图的最长边，按配置的每个尺寸各生成一张
+	Quality    int   // JPEG 编码质量
+}
+
+// Thumb 缩略图，Size 为配置的最长边；GIF 的缩略图为第一帧的 PNG
+type Thumb struct {
+	Size        int
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Image 处理后的图片，Data 已经重新编码，不包含 EXIF、文本块等元数据
+type Image struct {
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	Data        []byte
+	Thumbs      []Thumb
+}
+
+// Ext 类型对应的扩展名
+func Ext(contentType string) string {
+	return extensions[contentType]
+}
+
+// Sniff 按文件头识别图片类型，不信任客户端提交的 Content-Type 和文件名
+func Sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := extensions[contentType]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+	return contentType, nil
+}
+
+// Process 校验并处理上传的图片：
+// 先只读取文件头中的尺寸，超出限制时不再解码，避免解压炸弹占用内存；
+// 然后解码、按 EXIF 方向旋转、重新编码，生成各尺寸的缩略图
+func Process(data []byte, opts Options) (*Image, error) {
+	if opts.MaxSize > 0 && int64(len(data)) > opts.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, len(data))
+	}
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if "image/"+format != contentType {
+		return nil, fmt.Errorf("%w: %s decoded as %s", ErrMalformed, contentType, format)
+	}
+	if err = checkDimensions(cfg.Width, cfg.Height, opts); err != nil {
+		return nil, err
+	}
+	if opts.Quality <= 0 {
+		opts.Quality = 90
+	}
+
+	if contentType == TypeGIF {
+		return processGIF(data, cfg, opts)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	img := toNRGBA(src)
+	if contentType == TypeJPEG {
+		img = orient(img, jpegOrientation(data))
+	}
+	out := &Image{
+		ContentType: contentType,
+		Ext:         extensions[contentType],
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}
+	if out.Data, err = encode(img, contentType, opts.Quality); err != nil {
+		return nil, err
+	}
+	if out.Thumbs, err = thumbs(img, contentType, opts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// processGIF 逐帧重新编码以保留动画，缩略图取第一帧并保存为 PNG。
+// 解码前先扫描帧数，帧数或总像素数超出限制时不解码
+func processGIF(data []byte, cfg image.Config, opts Options) (*Image, error) {
+	frames, err := gifFrames(data)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxFrames > 0 && frames > opts.MaxFrames {
+		return nil, fmt.Errorf("%w: %d frames", ErrDimensions, frames)
+	}
+	if opts.MaxPixels > 0 && int64(cfg.Width)*int64(cfg.Height)*int64(frames) > opts.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d x %d frames", ErrDimensions, cfg.Width, cfg.Height, frames)
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("%w: gif has no frames", ErrMalformed)
+	}
+	var buf bytes.Buffer
+	if err = gif.EncodeAll(&buf, g); err != nil {
+		return nil, err
+	}
+	out := &Image{
+		ContentType: TypeGIF,
+		Ext:         extensions[TypeGIF],
+		Width:       g.Config.Width,
+		Height:      g.Config.Height,
+		Data:        buf.Bytes(),
+	}
+	first := image.NewNRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	draw.Draw(first, g.Image[0].Bounds(), g.Image[0], g.Image[0].Bounds().Min, draw.Over)
+	if out.Thumbs, err = thumbs(first, TypePNG, opts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func checkDimensions(width, height int, opts Options) error {
+	if width <= 0 || height <= 0 ||
+		(opts.MaxWidth > 0 && width > opts.MaxWidth) ||
+		(opts.MaxHeight > 0 && height > opts.MaxHeight) {
+		return fmt.Errorf("%w: %dx%d", ErrDimensions, width, height)
+	}
+	return nil
+}
+
+// thumbs 按配置的尺寸生成缩略图，图片本身不超过该尺寸时不放大
+func thumbs(img *image.NRGBA, contentType string, opts Options) ([]Thumb, error) {
+	list := make([]Thumb, 0, len(opts.ThumbSizes))
+	for _, size := range opts.ThumbSizes {
+		if size <= 0 {
+			continue
+		}
+		t := resize(img, size)
+		data, err := encode(t, contentType, opts.Quality)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, Thumb{Size: size, ContentType: contentType, Ext: extensions[contentType], Width: t.Bounds().Dx(), Height: t.Bounds().Dy(), Data: data})
+	}
+	return list, nil
+}
+
+func encode(img image.Image, contentType string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case TypeJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case TypePNG:
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toNRGBA 转为从 (0,0) 开始的 NRGBA，后续旋转和缩放直接操作像素数组
+func toNRGBA(src image.Image) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,208 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func newImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 8), G: uint8(y * 8), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func newPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, newImage(w, h)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, newImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIF 在 SOI 之后插入只包含 Orientation 的 EXIF 段，order 为 TIFF 头中的字节序
+func withEXIF(data []byte, order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], orientationTag)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	out := append([]byte{}, data[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(2+len(segment)))
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"png", newPNG(t, 2, 2), TypePNG},
+		{"jpeg", newJPEG(t, 2, 2), TypeJPEG},
+		{"gif", newGIF(t, 2, 2, 1), TypeGIF},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), ""},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), ""},
+		{"html named .png", []byte("<html><script>alert(1)</script>"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		got, err := Sniff(tt.data)
+		if tt.want == "" && !errors.Is(err, ErrUnsupportedType) || got != tt.want {
+			t.Errorf("%s: got %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	data := newPNG(t, 40, 20)
+	tests := []struct {
+		name string
+		data []byte
+		opts Options
+		want error
+	}{
+		{"ok", data, Options{MaxSize: int64(len(data)), MaxWidth: 40, MaxHeight: 20}, nil},
+		{"size", data, Options{MaxSize: int64(len(data)) - 1}, ErrTooLarge},
+		{"width", data, Options{MaxWidth: 39}, ErrDimensions},
+		{"height", data, Options{MaxHeight: 19}, ErrDimensions},
+		{"truncated", data[:40], Options{}, ErrMalformed},
+		{"text", []byte("hello"), Options{}, ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		if _, err := Process(tt.data, tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestProcessThumbs(t *testing.T) {
+	img, err := Process(newPNG(t, 40, 20), Options{ThumbSizes: []int{10, 0, 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.ContentType != TypePNG || img.Ext != ".png" || img.Width != 40 || img.Height != 20 {
+		t.Fatalf("got %s%s %dx%d", img.ContentType, img.Ext, img.Width, img.Height)
+	}
+	// 尺寸 0 跳过，大于原图的尺寸不放大
+	if len(img.Thumbs) != 2 {
+		t.Fatalf("got %d thumbs, want 2", len(img.Thumbs))
+	}
+	for i, want := range [][3]int{{10, 10, 5}, {100, 40, 20}} {
+		th := img.Thumbs[i]
+		cfg, err := png.DecodeConfig(bytes.NewReader(th.Data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if th.Size != want[0] || th.Width != want[1] || th.Height != want[2] || cfg.Width != want[1] || cfg.Height != want[2] {
+			t.Fatalf("thumb %d: %+v, decoded %dx%d", i, th, cfg.Width, cfg.Height)
+		}
+	}
+
+	// GIF 的缩略图为第一帧的 PNG
+	img, err = Process(newGIF(t, 16, 8, 3), Options{ThumbSizes: []int{4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th := img.Thumbs[0]; th.ContentType != TypePNG || th.Width != 4 || th.Height != 2 {
+		t.Fatalf("gif thumb %+v", th)
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	data := newJPEG(t, 4, 2)
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", data, 1},
+		{"big endian", withEXIF(data, binary.BigEndian, 6), 6},
+		{"little endian", withEXIF(data, binary.LittleEndian, 8), 8},
+		{"out of range", withEXIF(data, binary.BigEndian, 9), 1},
+		{"truncated segment", withEXIF(data, binary.BigEndian, 6)[:20], 1},
+		{"png", newPNG(t, 4, 2), 1},
+	}
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 3×2 的图片中 (0,0) 标记为红色，检查变换后红色像素的位置
+	tests := []struct {
+		orientation int
+		w, h        int
+		x, y        int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+	}
+	red := color.NRGBA{R: 255, A: 255}
+	for _, tt := range tests {
+		src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+		src.SetNRGBA(0, 0, red)
+		dst := orient(src, tt.orientation)
+		if dst.Bounds().Dx() != tt.w || dst.Bounds().Dy() != tt.h || dst.NRGBAAt(tt.x, tt.y) != red {
+			t.Errorf("orientation %d: got %v, red at (%d,%d) is %v", tt.orientation, dst.Bounds(), tt.x, tt.y, dst.NRGBAAt(tt.x, tt.y))
+		}
+	}
+}
+
+// TestProcessStripsEXIF 按 EXIF 方向旋转后重新编码，输出中不再带有 EXIF
+func TestProcessStripsEXIF(t *testing.T) {
+	data := withEXIF(newJPEG(t, 40, 20), binary.BigEndian, 6)
+	img, err := Process(data, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 20 || img.Height != 40 {
+		t.Fatalf("got %dx%d, want 20x40", img.Width, img.Height)
+	}
+	if bytes.Contains(img.Data, []byte("Exif\x00\x00")) {
+		t.Fatal("output still contains exif")
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(img.Data))
+	if err != nil || cfg.Width != 20 || cfg.Height != 40 {
+		t.Fatalf("decoded %dx%d, %v", cfg.Width, cfg.Height, err)
+	}
+}
@@ -0,0 +1,71 @@
+package verifier
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+	"strings"
+)
+
+var (
+	ErrEVMSignature = errors.New("evm signature invalid")
+	ErrEVMAddress   = errors.New("evm signer does not match address")
+)
+
+// VerifyEVMPersonalSign 校验 personal_sign（EIP-191）签名是否由指定地址签出
+func VerifyEVMPersonalSign(address, message, signature string) error {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return ErrEVMSignature
+	}
+	signer, err := RecoverEVMAddress(EVMPersonalHash([]byte(message)), sig)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(signer, address) {
+		return ErrEVMAddress
+	}
+	return nil
+}
+
+// EVMPersonalHash 计算 personal_sign 的消息哈希：keccak256("\x19Ethereum Signed Message:\n" + len(message) + message)
+func EVMPersonalHash(message []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return Keccak256([]byte(prefix), message)
+}
+
+// RecoverEVMAddress 从 65 字节的 r||s||v 签名中恢复签名者地址（小写 0x 开头）
+func RecoverEVMAddress(hash, sig []byte) (string, error) {
+	if len(sig) != 65 || len(hash) != 32 {
+		return "", ErrEVMSignature
+	}
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return "", ErrEVMSignature
+	}
+
+	// decred 的紧凑签名格式为 [27+v] || r || s
+	compact := make([]byte, 65)
+	compact[0] = 27 + v
+	copy(compact[1:], sig[:64])
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return "", ErrEVMSignature
+	}
+	addr := Keccak256(pub.SerializeUncompressed()[1:])[12:]
+	return "0x" + hex.EncodeToString(addr), nil
+}
+
+// Keccak256 以太坊使用的 keccak256 哈希
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,65 @@
+package verifier
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/mr-tron/base58"
+	"testing"
+)
+
+// web3.js 文档中 accounts.sign 的示例：私钥 0x4c0883a6...3f362318 对 "Some data" 的签名
+const (
+	evmSigner      = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	evmMessage     = "Some data"
+	evmMessageHash = "1da44b586eb0729ff70a73c326926f6ed5a25f5b056e7f47fbc6e58d86871655"
+	evmSignature   = "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+)
+
+func TestVerifyEVMPersonalSign(t *testing.T) {
+	if got := hex.EncodeToString(EVMPersonalHash([]byte(evmMessage))); got != evmMessageHash {
+		t.Fatalf("hash = %s, want %s", got, evmMessageHash)
+	}
+	tests := []struct {
+		name                 string
+		addr, msg, signature string
+		want                 error
+	}{
+		{"ok", evmSigner, evmMessage, evmSignature, nil},
+		{"lower case address", "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", evmMessage, evmSignature, nil},
+		{"other message", evmSigner, "Other data", evmSignature, ErrEVMAddress},
+		{"other address", "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", evmMessage, evmSignature, ErrEVMAddress},
+		{"bad v", evmSigner, evmMessage, evmSignature[:len(evmSignature)-2] + "1f", ErrEVMSignature},
+		{"short", evmSigner, evmMessage, evmSignature[:20], ErrEVMSignature},
+		{"not hex", evmSigner, evmMessage, "0xzz", ErrEVMSignature},
+	}
+	for _, tt := range tests {
+		if err := VerifyEVMPersonalSign(tt.addr, tt.msg, tt.signature); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifySolanaSignMessage(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	addr := base58.Encode(key.Public().(ed25519.PublicKey))
+	sig := ed25519.Sign(key, []byte("hello"))
+
+	tests := []struct {
+		name                 string
+		addr, msg, signature string
+		want                 error
+	}{
+		{"base58", addr, "hello", base58.Encode(sig), nil},
+		{"base64", addr, "hello", base64.StdEncoding.EncodeToString(sig), nil},
+		{"other message", addr, "hello!", base58.Encode(sig), ErrSolanaSignature},
+		{"bad address", "0xabc", "hello", base58.Encode(sig), ErrSolanaAddress},
+		{"short signature", addr, "hello", base58.Encode(sig[:32]), ErrSolanaSignature},
+	}
+	for _, tt := range tests {
+		if err := VerifySolanaSignMessage(tt.addr, tt.msg, tt.signature); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
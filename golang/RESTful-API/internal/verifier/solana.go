@@ -0,0 +1,33 @@
+package verifier
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"github.com/mr-tron/base58"
+)
+
+var (
+	ErrSolanaAddress   = errors.New("solana address invalid")
+	ErrSolanaSignature = errors.New("solana signature invalid")
+)
+
+// VerifySolanaSignMessage 校验钱包 signMessage 的 ed25519 签名，签名支持 base58 或 base64 编码
+func VerifySolanaSignMessage(address, message, signature string) error {
+	pub, err := base58.Decode(address)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return ErrSolanaAddress
+	}
+
+	sig, err := base58.Decode(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		if sig, err = base64.StdEncoding.DecodeString(signature); err != nil || len(sig) != ed25519.SignatureSize {
+			return ErrSolanaSignature
+		}
+	}
+
+	if !ed25519.Verify(pub, []byte(message), sig) {
+		return ErrSolanaSignature
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+// Package verifier 各条链的钱包签名校验
+package verifier
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"strings"
+	"time"
+)
+
+const (
+	tonProofPrefix   = "ton-proof-item-v2/" // ton_proof 消息前缀
+	tonConnectPrefix = "ton-connect"        // 签名消息前缀
+
+	TonNetworkMainnet = "-239" // TON Connect 中的主网 network 标识
+	TonNetworkTestnet = "-3"   // TON Connect 中的测试网 network 标识
+)
+
+var (
+	ErrTonProofDomain    = errors.New("ton proof domain not allowed")
+	ErrTonProofExpired   = errors.New("ton proof timestamp out of range")
+	ErrTonProofPayload   = errors.New("ton proof payload mismatch")
+	ErrTonProofNetwork   = errors.New("ton proof network mismatch")
+	ErrTonProofAddress   = errors.New("ton proof address does not match state init")
+	ErrTonProofSignature = errors.New("ton proof signature invalid")
+	ErrTonStateInit      = errors.New("ton state init invalid")
+	ErrTonWalletVersion  = errors.New("ton wallet version not supported")
+)
+
+// TonDomain ton_proof 中的应用域名
+type TonDomain struct {
+	LengthBytes uint32 `json:"lengthBytes"`
+	Value       string `json:"value"`
+}
+
+// TonProofItem 钱包返回的 ton_proof 内容
+type TonProofItem struct {
+	Timestamp int64     `json:"timestamp"`
+	Domain    TonDomain `json:"domain"`
+	Signature string    `json:"signature"`  // base64 编码的签名
+	Payload   string    `json:"payload"`    // 服务端下发的 nonce
+	StateInit string    `json:"state_init"` // base64 编码的钱包 state init BOC
+}
+
+// TonProof 前端提交的登录凭证
+type TonProof struct {
+	Address   string       `json:"address"`    // raw 格式地址，例如 0:abcd...
+	Network   string       `json:"network"`    // -239 主网，-3 测试网
+	PublicKey string       `json:"public_key"` // 钱包返回的公钥（hex），可选
+	Proof     TonProofItem `json:"proof"`
+}
+
+// TonProofOptions ton_proof 校验参数
+type TonProofOptions struct {
+	Domains []string         // 允许的应用域名
+	TTL     time.Duration    // 签名时间戳的有效期
+	Payload string           // 期望的 payload，为空时不校验
+	Network string           // 期望的网络，TonNetworkMainnet 或 TonNetworkTestnet，为空时不校验
+	Now     func() time.Time // 当前时间，便于测试时注入
+}
+
+// tonWalletCodes 已知钱包合约代码哈希与版本的映射
+var tonWalletCodes = map[string]wallet.Version{}
+
+func init() {
+	dummyKey := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	versions := map[wallet.Version]wallet.VersionConfig{
+		wallet.V3R1:      wallet.V3R1,
+		wallet.V3R2:      wallet.V3R2,
+		wallet.V4R1:      wallet.V4R1,
+		wallet.V4R2:      wallet.V4R2,
+		wallet.V5R1Final: wallet.ConfigV5R1Final{NetworkGlobalID: wallet.MainnetGlobalID},
+	}
+	for ver, conf := range versions {
+		state, err := wallet.GetStateInit(dummyKey, conf, wallet.DefaultSubwallet)
+		if err != nil {
+			panic(err)
+		}
+		tonWalletCodes[hex.EncodeToString(state.Code.Hash())] = ver
+	}
+}
+
+// VerifyTonProof 校验 TON Connect 的 ton_proof，成功时返回钱包地址和公钥
+func VerifyTonProof(p *TonProof, opts TonProofOptions) (*address.Address, ed25519.PublicKey, error) {
+	if p == nil {
+		return nil, nil, ErrTonStateInit
+	}
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+
+	// 域名必须在白名单中，且长度与声明一致
+	if uint32(len(p.Proof.Domain.Value)) != p.Proof.Domain.LengthBytes || !domainAllowed(p.Proof.Domain.Value, opts.Domains) {
+		return nil, nil, ErrTonProofDomain
+	}
+
+	// 签名时间不能超出有效期，也不能来自未来
+	signedAt := time.Unix(p.Proof.Timestamp, 0)
+	if current := now(); signedAt.After(current.Add(time.Minute)) || current.Sub(signedAt) > opts.TTL {
+		return nil, nil, ErrTonProofExpired
+	}
+
+	if opts.Payload != "" && p.Proof.Payload != opts.Payload {
+		return nil, nil, ErrTonProofPayload
+	}
+	// 测试网钱包的地址和签名在主网同样成立，只能按钱包上报的网络区分
+	if opts.Network != "" && p.Network != opts.Network {
+		return nil, nil, ErrTonProofNetwork
+	}
+
+	addr, err := address.ParseRawAddr(p.Address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse address: %w", err)
+	}
+
+	pubKey, stateHash, err := ParseTonStateInit(p.Proof.StateInit)
+	if err != nil {
+		return nil, nil, err
+	}
+	// state init 的哈希就是合约地址，防止用别人的 state init 冒充
+	if !bytes.Equal(stateHash, addr.Data()) {
+		return nil, nil, ErrTonProofAddress
+	}
+	if p.PublicKey != "" && !strings.EqualFold(p.PublicKey, hex.EncodeToString(pubKey)) {
+		return nil, nil, ErrTonProofAddress
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(p.Proof.Signature)
+	if err != nil {
+		return nil, nil, ErrTonProofSignature
+	}
+	if !ed25519.Verify(pubKey, TonProofSignMessage(addr, &p.Proof), signature) {
+		return nil, nil, ErrTonProofSignature
+	}
+	return addr, pubKey, nil
+}
+
+// TonProofSignMessage 按 TON Connect 规范拼接待签名消息：
+// sha256(0xffff ++ "ton-connect" ++ sha256("ton-proof-item-v2/" ++ workchain ++ hash ++ domainLen ++ domain ++ timestamp ++ payload))
+func TonProofSignMessage(addr *address.Address, item *TonProofItem) []byte {
+	var msg bytes.Buffer
+	msg.WriteString(tonProofPrefix)
+	_ = binary.Write(&msg, binary.BigEndian, addr.Workchain())
+	msg.Write(addr.Data())
+	_ = binary.Write(&msg, binary.LittleEndian, item.Domain.LengthBytes)
+	msg.WriteString(item.Domain.Value)
+	_ = binary.Write(&msg, binary.LittleEndian, uint64(item.Timestamp))
+	msg.WriteString(item.Payload)
+	msgHash := sha256.Sum256(msg.Bytes())
+
+	var full bytes.Buffer
+	full.Write([]byte{0xff, 0xff})
+	full.WriteString(tonConnectPrefix)
+	full.Write(msgHash[:])
+	fullHash := sha256.Sum256(full.Bytes())
+	return fullHash[:]
+}
+
+// ParseTonStateInit 解析钱包的 state init，返回公钥和 state init 哈希
+func ParseTonStateInit(stateInit string) (ed25519.PublicKey, []byte, error) {
+	boc, err := base64.StdEncoding.DecodeString(stateInit)
+	if err != nil {
+		return nil, nil, ErrTonStateInit
+	}
+	root, err := cell.FromBOC(boc)
+	if err != nil {
+		return nil, nil, ErrTonStateInit
+	}
+
+	var state tlb.StateInit
+	if err = tlb.LoadFromCell(&state, root.BeginParse()); err != nil || state.Code == nil || state.Data == nil {
+		return nil, nil, ErrTonStateInit
+	}
+
+	version, ok := tonWalletCodes[hex.EncodeToString(state.Code.Hash())]
+	if !ok {
+		return nil, nil, ErrTonWalletVersion
+	}
+
+	// 不同钱包版本的 data 布局不同，公钥前面的字段需要先跳过
+	data := state.Data.BeginParse()
+	var skipBits uint
+	switch version {
+	case wallet.V3R1, wallet.V3R2, wallet.V4R1, wallet.V4R2:
+		skipBits = 64 // seqno + subwallet_id
+	case wallet.V5R1Final:
+		skipBits = 65 // is_signature_allowed + seqno + wallet_id
+	}
+	if _, err = data.LoadSlice(skipBits); err != nil {
+		return nil, nil, ErrTonStateInit
+	}
+	pubKey, err := data.LoadSlice(256)
+	if err != nil {
+		return nil, nil, ErrTonStateInit
+	}
+	return ed25519.PublicKey(pubKey), root.Hash(), nil
+}
+
+// domainAllowed 判断域名是否在白名单中
+func domainAllowed(domain string, allowed []string) bool {
+	for _, d := range allowed {
+		if strings.EqualFold(strings.TrimSpace(d), domain) {
+			return true
+		}
+	}
+	return false
+}
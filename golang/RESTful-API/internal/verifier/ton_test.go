@@ -0,0 +1,200 @@
+package verifier
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testDomain  = "example.com"
+	testPayload = "nonce-123"
+	testTime    = 1700000000
+)
+
+// TestTonProofSignMessage 期望值按 TON Connect 规范用 Python hashlib 独立计算
+func TestTonProofSignMessage(t *testing.T) {
+	item := &TonProofItem{
+		Timestamp: testTime,
+		Domain:    TonDomain{LengthBytes: uint32(len(testDomain)), Value: testDomain},
+		Payload:   testPayload,
+	}
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"0:" + strings.Repeat("11", 32), "826fc99728f2576a12cbd8bb691cd891f8739aa5a1f215d4537883546adcfa40"},
+		{"-1:" + strings.Repeat("11", 32), "6255326e5a635deba596a9368e6203aaeb3bdc50de6578e7be9912e854f5d1b3"},
+	}
+	for _, tt := range tests {
+		addr := address.MustParseRawAddr(tt.addr)
+		if got := hex.EncodeToString(TonProofSignMessage(addr, item)); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.addr, got, tt.want)
+		}
+	}
+}
+
+// signedTonProof 用固定私钥生成 version 钱包的 ton_proof
+func signedTonProof(t *testing.T, version wallet.VersionConfig) (*TonProof, ed25519.PrivateKey) {
+	t.Helper()
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	state, err := wallet.GetStateInit(key.Public().(ed25519.PublicKey), version, wallet.DefaultSubwallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := tlb.ToCell(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := address.NewAddress(0, 0, root.Hash())
+
+	p := &TonProof{
+		Address: addr.StringRaw(),
+		Network: TonNetworkMainnet,
+		Proof: TonProofItem{
+			Timestamp: testTime,
+			Domain:    TonDomain{LengthBytes: uint32(len(testDomain)), Value: testDomain},
+			Payload:   testPayload,
+			StateInit: base64.StdEncoding.EncodeToString(root.ToBOC()),
+		},
+	}
+	p.Proof.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, TonProofSignMessage(addr, &p.Proof)))
+	return p, key
+}
+
+func testTonOptions() TonProofOptions {
+	return TonProofOptions{
+		Domains: []string{" Example.com "},
+		TTL:     15 * time.Minute,
+		Payload: testPayload,
+		Network: TonNetworkMainnet,
+		Now:     func() time.Time { return time.Unix(testTime+60, 0) },
+	}
+}
+
+func TestVerifyTonProof(t *testing.T) {
+	versions := map[string]wallet.VersionConfig{
+		"v3r2": wallet.V3R2,
+		"v4r2": wallet.V4R2,
+		"v5r1": wallet.ConfigV5R1Final{NetworkGlobalID: wallet.MainnetGlobalID},
+	}
+	for name, version := range versions {
+		p, key := signedTonProof(t, version)
+		p.PublicKey = strings.ToUpper(hex.EncodeToString(key.Public().(ed25519.PublicKey)))
+		addr, pub, err := VerifyTonProof(p, testTonOptions())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if addr.StringRaw() != p.Address || !pub.Equal(key.Public()) {
+			t.Fatalf("%s: got %s %x", name, addr.StringRaw(), pub)
+		}
+	}
+}
+
+func TestVerifyTonProofRejects(t *testing.T) {
+	other, _ := signedTonProof(t, wallet.V3R2)
+	tests := []struct {
+		name   string
+		mutate func(p *TonProof, opts *TonProofOptions)
+		want   error
+	}{
+		{"nil", nil, ErrTonStateInit},
+		{"domain", func(p *TonProof, opts *TonProofOptions) { opts.Domains = []string{"evil.com"} }, ErrTonProofDomain},
+		{"domain length", func(p *TonProof, opts *TonProofOptions) { p.Proof.Domain.LengthBytes++ }, ErrTonProofDomain},
+		{"expired", func(p *TonProof, opts *TonProofOptions) {
+			opts.Now = func() time.Time { return time.Unix(testTime, 0).Add(opts.TTL + time.Second) }
+		}, ErrTonProofExpired},
+		{"future", func(p *TonProof, opts *TonProofOptions) {
+			opts.Now = func() time.Time { return time.Unix(testTime-120, 0) }
+		}, ErrTonProofExpired},
+		{"payload", func(p *TonProof, opts *TonProofOptions) { opts.Payload = "other-nonce" }, ErrTonProofPayload},
+		{"network", func(p *TonProof, opts *TonProofOptions) { p.Network = TonNetworkTestnet }, ErrTonProofNetwork},
+		{"testnet", func(p *TonProof, opts *TonProofOptions) { opts.Network = TonNetworkTestnet }, ErrTonProofNetwork},
+		// 别人的 state init 算出的地址与声明的地址不一致
+		{"state init", func(p *TonProof, opts *TonProofOptions) { p.Proof.StateInit = other.Proof.StateInit }, ErrTonProofAddress},
+		{"public key", func(p *TonProof, opts *TonProofOptions) { p.PublicKey = strings.Repeat("00", 32) }, ErrTonProofAddress},
+		{"signature", func(p *TonProof, opts *TonProofOptions) { p.Proof.Signature = other.Proof.Signature }, ErrTonProofSignature},
+		{"signature encoding", func(p *TonProof, opts *TonProofOptions) { p.Proof.Signature = "!" }, ErrTonProofSignature},
+		// payload 不在签名之外：改了 payload 又放宽校验，签名也对不上
+		{"signed payload", func(p *TonProof, opts *TonProofOptions) { p.Proof.Payload, opts.Payload = "x", "" }, ErrTonProofSignature},
+		{"boc", func(p *TonProof, opts *TonProofOptions) { p.Proof.StateInit = "AAAA" }, ErrTonStateInit},
+	}
+	for _, tt := range tests {
+		p, _ := signedTonProof(t, wallet.V4R2)
+		opts := testTonOptions()
+		if tt.mutate == nil {
+			p = nil
+		} else {
+			tt.mutate(p, &opts)
+		}
+		if _, _, err := VerifyTonProof(p, opts); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// tongoTonProof 由 Tonkeeper 钱包端使用的 tongo v1.16.2 tonconnect.CreateSignedProof 生成，
+// 钱包为 V4R2，与本包的签名实现互相独立，按 TON Connect 返回给 dApp 的 JSON 格式保存
+const tongoTonProof = `{
+	"address": "0:78011a063278279614c9b154bc410f8406fa64279985160ea39083bbfdb570aa",
+	"network": "-239",
+	"public_key": "65b9d95d56d534b9d5bfc21d512fb09ef3ca9bc60715058272c6c17f612ca8d3",
+	"proof": {
+		"timestamp": 1735689600,
+		"domain": {"lengthBytes": 12, "value": "marketdao.io"},
+		"signature": "HfjJebWH8nmjXO759cpVpF95D9FnTMKJ6AWftOaN4rDtmusXDBPsVV7TPI4SyY4ypcpT6E6EH+RscDjpAM0lBQ==",
+		"payload": "a1b2c3d4e5f60718293a4b5c6d7e8f90",
+		"state_init": "te6ccgECFgEAAwQAAgE0AQIBFP8A9KQT9LzyyAsDAFEAAAAAKamjF2W52V1W1TS51b/CHVEvsJ7zypvGBxUFgnLGwX9hLKjTQAIBIAQFAgFIBgcE+PKDCNcYINMf0x/THwL4I7vyZO1E0NMf0x/T//QE0VFDuvKhUVG68qIF+QFUEGT5EPKj+AAkpMjLH1JAyx9SMMv/UhD0AMntVPgPAdMHIcAAn2xRkyDXSpbTB9QC+wDoMOAhwAHjACHAAuMAAcADkTDjDQOkyMsfEssfy/8SExQVAubQAdDTAyFxsJJfBOAi10nBIJJfBOAC0x8hghBwbHVnvSKCEGRzdHK9sJJfBeAD+kAwIPpEAcjKB8v/ydDtRNCBAUDXIfQEMFyBAQj0Cm+hMbOSXwfgBdM/yCWCEHBsdWe6kjgw4w0DghBkc3RyupJfBuMNCAkCASAKCwB4AfoA9AQw+CdvIjBQCqEhvvLgUIIQcGx1Z4MesXCAGFAEywUmzxZY+gIZ9ADLaRfLH1Jgyz8gyYBA+wAGAIpQBIEBCPRZMO1E0IEBQNcgyAHPFvQAye1UAXKwjiOCEGRzdHKDHrFwgBhQBcsFUAPPFiP6AhPLassfyz/JgED7AJJfA+ICASAMDQBZvSQrb2omhAgKBrkPoCGEcNQICEekk30pkQzmkD6f+YN4EoAbeBAUiYcVnzGEAgFYDg8AEbjJftRNDXCx+AA9sp37UTQgQFA1yH0BDACyMoHy//J0AGBAQj0Cm+hMYAIBIBARABmtznaiaEAga5Drhf/AABmvHfaiaEAQa5DrhY/AAG7SB/oA1NQi+QAFyMoHFcv/ydB3dIAYyMsFywIizxZQBfoCFMtrEszMyXP7AMhAFIEBCPRR8qcCAHCBAQjXGPoA0z/IVCBHgQEI9FHyp4IQbm90ZXB0gBjIywXLAlAGzxZQBPoCFMtqEssfyz/Jc/sAAgBsgQEI1xj6ANM/MFIkgQEI9Fnyp4IQZHN0cnB0gBjIywXLAlAFzxZQA/oCE8tqyx8Syz/Jc/sAAAr0AMntVA=="
+	}
+}`
+
+func TestVerifyTonProofTongoVector(t *testing.T) {
+	p := &TonProof{}
+	if err := json.Unmarshal([]byte(tongoTonProof), p); err != nil {
+		t.Fatal(err)
+	}
+	opts := TonProofOptions{
+		Domains: []string{"marketdao.io"},
+		TTL:     15 * time.Minute,
+		Payload: "a1b2c3d4e5f60718293a4b5c6d7e8f90",
+		Network: TonNetworkMainnet,
+		Now:     func() time.Time { return time.Unix(1735689600+60, 0) },
+	}
+	addr, pub, err := VerifyTonProof(p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.StringRaw() != p.Address || hex.EncodeToString(pub) != p.PublicKey {
+		t.Fatalf("got %s %x", addr.StringRaw(), pub)
+	}
+	// 不带 public_key 时从 state init 中取出同一个公钥
+	p.PublicKey = ""
+	if _, pub, err = VerifyTonProof(p, opts); err != nil || hex.EncodeToString(pub) != "65b9d95d56d534b9d5bfc21d512fb09ef3ca9bc60715058272c6c17f612ca8d3" {
+		t.Fatalf("without public_key: %x, %v", pub, err)
+	}
+}
+
+func TestParseTonStateInitUnknownWallet(t *testing.T) {
+	state, err := wallet.GetStateInit(make(ed25519.PublicKey, ed25519.PublicKeySize), wallet.V4R2, wallet.DefaultSubwallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// data 当作 code，代码哈希不属于已知钱包
+	state.Code = state.Data
+	root, err := tlb.ToCell(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = ParseTonStateInit(base64.StdEncoding.EncodeToString(root.ToBOC())); !errors.Is(err, ErrTonWalletVersion) {
+		t.Fatalf("got %v, want ErrTonWalletVersion", err)
+	}
+}
@@ -2,6 +2,15 @@ package main
 
 import (
 	_ "RESTful-API/bootstrap" // 只执行 bootstrap 的 init()，不直接使用包内函数
+	"RESTful-API/cmd"
+	"RESTful-API/internal/diagnostics"
+	"RESTful-API/internal/indexer"
+	"RESTful-API/internal/metadata"
+	"RESTful-API/internal/router"
+	"RESTful-API/utils/logs"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
 )
 
 type User struct {
@@ -11,11 +20,22 @@ type User struct {
 }
 
 func main() {
+	// 管理命令，例如 ./RESTful-API role grant 1 admin
+	if len(os.Args) > 1 && os.Args[1] == "role" {
+		if err := cmd.Role(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	//配置相关
+	defer cmd.Clean()
+	cmd.Start()
+	diagnostics.Start()
+	indexer.Start()
+	metadata.Start()
 
-	////配置相关
-	//defer cmd.Clean()
-	//cmd.Start()
-	//
 	//db, err := utils.ConnectToDatabase()
 	//if err != nil {
 	//	fmt.Println("Error connecting to database:", err)
@@ -99,5 +119,9 @@ func main() {
 
 	*/
 	//r.Run(":8081")
-	//r.Run(viper.GetString("server.addr") + ":" + viper.GetString("server.port"))
+
+	r := router.NewRouter()
+	if err := r.Run(viper.GetString("server.addr") + ":" + viper.GetString("server.port")); err != nil {
+		logs.Error("server run error: %v", err)
+	}
 }
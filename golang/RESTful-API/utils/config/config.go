@@ -29,6 +29,13 @@ func init() {
 		panic(err)
 	}
 	configPath = workPath + configsDirname // 将当前工作目录与 configs 目录拼接，构成配置文件路径。
+	// 当前目录下没有时逐级向上查找，go test 的工作目录是包所在目录
+	for dir := workPath; fileExists(configPath) == false && filepath.Dir(dir) != dir; {
+		dir = filepath.Dir(dir)
+		if fileExists(dir + configsDirname) {
+			configPath = dir + configsDirname
+		}
+	}
 
 	if fileExists(configPath) == false { // 如果当前工作目录下的 configs 目录不存在，尝试使用可执行文件所在的目录作为基准路径。
 		execPath, err := os.Executable() // os.Executable() 获取当前可执行文件的路径
@@ -72,6 +79,20 @@ func fileExists(path string) bool {
 	return true
 }
 
+// RunMode 返回当前运行模式，没有配置时默认为 "prod"
+func RunMode() string {
+	runMode := appConfig.Section("").Key("RunMode").String()
+	if runMode == "" {
+		runMode = "prod"
+	}
+	return runMode
+}
+
+// IsDev 是否为开发模式，开发模式下允许缺少部分密钥配置
+func IsDev() bool {
+	return RunMode() == "dev"
+}
+
 // GetConfig 用于获取配置项的值，支持按 RunMode 分不同的环境（如生产环境、开发环境等）来获取配置。
 // 读取 RunMode 配置
 // appConfig.Section("").Key("RunMode").String() 获取全局 RunMode 配置，如果没有设置，默认值为 "prod"（生产模式）。
@@ -79,13 +100,24 @@ func fileExists(path string) bool {
 // 如果当前 RunMode 下的配置中有指定的 keyName，则返回该配置项。
 // 否则，返回全局配置中 keyName 对应的值。
 func GetConfig(keyName string) *ini.Key {
-	runMode := appConfig.Section("").Key("RunMode").String()
-	if runMode == "" {
-		runMode = "prod"
-	}
+	runMode := RunMode()
 	//如果runMode下不存在，从非runMode取
 	if appConfig.Section(runMode).HasKey(keyName) == false {
 		return appConfig.Section("").Key(keyName)
 	}
 	return appConfig.Section(runMode).Key(keyName)
 }
+
+// Effective 返回当前 RunMode 下生效的全部配置：全局配置被 RunMode 分区中的同名配置覆盖。
+// 返回值中包含密码等敏感信息，对外输出前需要脱敏。
+func Effective() map[string]string {
+	runMode := RunMode()
+	result := make(map[string]string)
+	for _, key := range appConfig.Section("").Keys() {
+		result[key.Name()] = key.String()
+	}
+	for _, key := range appConfig.Section(runMode).Keys() {
+		result[key.Name()] = key.String()
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package logs
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// 查找调用位置时需要跳过的包：logrus、logs 包自身，以及 gorm（SQL 日志定位到调用 gorm 的业务代码）
+var skipPackages = []string{
+	"github.com/sirupsen/logrus",
+	packageName(runtime.FuncForPC(reflect.ValueOf(Info).Pointer()).Name()),
+	"gorm.io/",
+}
+
+// caller 返回第一个不属于 skipPackages 的调用位置，不依赖固定的调用层级
+func caller() (file string, line int, ok bool) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !skipFrame(frame.Function) {
+			return frame.File, frame.Line, true
+		}
+		if !more {
+			return "", 0, false
+		}
+	}
+}
+
+// skipFrame 判断函数是否属于需要跳过的包
+func skipFrame(function string) bool {
+	pkg := packageName(function)
+	for _, skip := range skipPackages {
+		if pkg == skip || (strings.HasSuffix(skip, "/") && strings.HasPrefix(pkg, skip)) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageName 从完整函数名中取出包路径，例如
+// RESTful-API/utils/logs.(*Logger).Info => RESTful-API/utils/logs
+func packageName(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
@@ -0,0 +1,90 @@
+package logs_test
+
+import (
+	"RESTful-API/utils/logs"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// nextLine 返回调用 nextLine 的下一行行号
+func nextLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line + 1
+}
+
+func TestCallerText(t *testing.T) {
+	mem := logs.CaptureLogs(t, &logs.MyTextFormatter{NoColor: true})
+	ctx := logs.WithRequestID(context.Background(), "req-1")
+	gorm := logs.NewGormLogger(true, time.Second)
+
+	var want []int
+	want = append(want, nextLine())
+	logs.Info("plain")
+	want = append(want, nextLine())
+	logs.With(logs.String("k", "v")).Warn("fields")
+	want = append(want, nextLine())
+	logs.InfoCtx(ctx, "ctx")
+	want = append(want, nextLine())
+	logs.FromContext(ctx).Error("from ctx")
+	want = append(want, nextLine())
+	gorm.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	lines := mem.Tail(0)
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines: %q", len(lines), lines)
+	}
+	for i, line := range lines {
+		if loc := fmt.Sprintf("[caller_test.go:%d]", want[i]); !strings.Contains(line, loc) {
+			t.Errorf("line %d: %q does not contain %s", i, line, loc)
+		}
+	}
+	if !strings.Contains(lines[2], "[request_id=req-1] ctx") || !strings.Contains(lines[1], "fields k=v") {
+		t.Errorf("unexpected fields: %q", lines[1:3])
+	}
+}
+
+func TestCallerJSON(t *testing.T) {
+	mem := logs.CaptureLogs(t, &logs.MyJsonTextFormatter{})
+	line := nextLine()
+	logs.With(logs.Int("msg", 1), logs.Err(io.EOF)).Info("json")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(mem.Tail(1)[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["file"] != fmt.Sprintf("caller_test.go:%d", line) || entry["msg"] != "json" ||
+		entry["fields.msg"] != float64(1) || entry["error"] != "EOF" {
+		t.Fatalf("unexpected entry %v", entry)
+	}
+}
+
+func TestSkipFrame(t *testing.T) {
+	tests := []struct {
+		function string
+		pkg      string
+		skip     bool
+	}{
+		{"RESTful-API/utils/logs.(*Logger).Info", "RESTful-API/utils/logs", true},
+		{"RESTful-API/utils/logs.Info", "RESTful-API/utils/logs", true},
+		{"github.com/sirupsen/logrus.(*Entry).Log", "github.com/sirupsen/logrus", true},
+		{"gorm.io/gorm.(*DB).Find", "gorm.io/gorm", true},
+		{"gorm.io/driver/mysql.Dialector.Initialize", "gorm.io/driver/mysql", true},
+		{"RESTful-API/internal/service.(*User).Login.func1", "RESTful-API/internal/service", false},
+		{"RESTful-API/utils/logs_test.TestCallerText", "RESTful-API/utils/logs_test", false}, // 外部测试包不是 logs 包
+		{"main.main", "main", false},
+	}
+	for _, tt := range tests {
+		if pkg := logs.PackageName(tt.function); pkg != tt.pkg {
+			t.Errorf("packageName(%q) = %q, want %q", tt.function, pkg, tt.pkg)
+		}
+		if skip := logs.SkipFrame(tt.function); skip != tt.skip {
+			t.Errorf("skipFrame(%q) = %v, want %v", tt.function, skip, tt.skip)
+		}
+	}
+}
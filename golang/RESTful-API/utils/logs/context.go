@@ -0,0 +1,67 @@
+package logs
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+const RequestIDField = "request_id" // 日志中 request id 的字段名
+
+type requestIDKey struct{}
+
+// WithRequestID 将 request id 写入 context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 从 context 中取出 request id，没有时返回空串
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// InfoCtx 带上 context 中 request id 的 Info 日志
+func InfoCtx(ctx context.Context, f interface{}, v ...any) {
+	logEntry(ctxEntry(ctx), logrus.InfoLevel, formatLog(f, v...))
+}
+
+// WarnCtx 带上 context 中 request id 的 Warn 日志
+func WarnCtx(ctx context.Context, f interface{}, v ...any) {
+	logEntry(ctxEntry(ctx), logrus.WarnLevel, formatLog(f, v...))
+}
+
+// ErrorCtx 带上 context 中 request id 的 Error 日志
+func ErrorCtx(ctx context.Context, f interface{}, v ...any) {
+	logEntry(ctxEntry(ctx), logrus.ErrorLevel, formatLog(f, v...))
+}
+
+// ctxEntry 根据 context 生成带字段的日志条目
+func ctxEntry(ctx context.Context) *logrus.Entry {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return log.WithField(RequestIDField, id)
+	}
+	return logrus.NewEntry(log)
+}
+
+// logEntry 按级别输出日志条目
+func logEntry(entry *logrus.Entry, level logrus.Level, msg string) {
+	switch level {
+	case logrus.PanicLevel:
+		entry.Panic(msg)
+	case logrus.FatalLevel:
+		entry.Fatal(msg)
+	case logrus.ErrorLevel:
+		entry.Error(msg)
+	case logrus.WarnLevel:
+		entry.Warn(msg)
+	case logrus.DebugLevel:
+		entry.Debug(msg)
+	case logrus.TraceLevel:
+		entry.Trace(msg)
+	default:
+		entry.Info(msg)
+	}
+}
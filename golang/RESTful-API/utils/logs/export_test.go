@@ -0,0 +1,33 @@
+package logs
+
+import (
+	"github.com/sirupsen/logrus"
+	"io"
+	"testing"
+)
+
+// 导出给 logs_test 包使用。调用位置的测试必须放在 logs 包之外，否则测试函数本身也会被当作 logs 包的帧跳过
+var (
+	PackageName = packageName
+	SkipFrame   = skipFrame
+)
+
+// CaptureLogs 把 log 的输出换成内存缓冲区，测试结束后恢复
+func CaptureLogs(t *testing.T, formatter logrus.Formatter) *MemorySink {
+	t.Helper()
+	mem := NewMemorySink(100)
+	hooks := make(logrus.LevelHooks)
+	hooks.Add(&sinkHook{sink: mem, levels: logrus.AllLevels, formatter: formatter})
+	old := log.ReplaceHooks(hooks)
+	level, out, reportCaller := log.GetLevel(), log.Out, log.ReportCaller
+	log.SetOutput(io.Discard)
+	log.SetLevel(logrus.TraceLevel)
+	log.SetReportCaller(true)
+	t.Cleanup(func() {
+		log.ReplaceHooks(old)
+		log.SetOutput(out)
+		log.SetLevel(level)
+		log.SetReportCaller(reportCaller)
+	})
+	return mem
+}
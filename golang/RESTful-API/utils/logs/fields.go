@@ -0,0 +1,96 @@
+package logs
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// Field 结构化日志字段，json 格式输出时保留原始类型
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Field { return Field{Key: key, Value: value} }
+
+func Int(key string, value int) Field { return Field{Key: key, Value: value} }
+
+func Int64(key string, value int64) Field { return Field{Key: key, Value: value} }
+
+func Uint64(key string, value uint64) Field { return Field{Key: key, Value: value} }
+
+func Float64(key string, value float64) Field { return Field{Key: key, Value: value} }
+
+func Bool(key string, value bool) Field { return Field{Key: key, Value: value} }
+
+// Duration 耗时字段，输出为毫秒数
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: float64(value.Nanoseconds()) / 1e6}
+}
+
+// Err 错误字段，key 固定为 error，err 为 nil 时输出 null
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: logrus.ErrorKey, Value: nil}
+	}
+	return Field{Key: logrus.ErrorKey, Value: err.Error()}
+}
+
+// Any 任意类型的字段，json 格式输出时按 json.Marshal 的结果展示
+func Any(key string, value interface{}) Field { return Field{Key: key, Value: value} }
+
+// Logger 带字段的日志记录器，每次 With 都会返回新的 Logger，不影响原来的字段
+type Logger struct {
+	entry *logrus.Entry
+}
+
+// With 返回带上指定字段的 Logger
+//
+//	logs.With(logs.Int64("user_id", id), logs.String("chain", chain)).Info("wallet linked")
+func With(fields ...Field) *Logger {
+	return (&Logger{entry: logrus.NewEntry(log)}).With(fields...)
+}
+
+// FromContext 返回带上 context 中 request id 的 Logger
+func FromContext(ctx context.Context) *Logger {
+	return &Logger{entry: ctxEntry(ctx)}
+}
+
+// With 在当前字段的基础上追加字段
+func (l *Logger) With(fields ...Field) *Logger {
+	data := make(logrus.Fields, len(fields))
+	for _, f := range fields {
+		data[f.Key] = f.Value
+	}
+	return &Logger{entry: l.entry.WithFields(data)}
+}
+
+func (l *Logger) Debug(f interface{}, v ...any) {
+	logEntry(l.entry, logrus.DebugLevel, formatLog(f, v...))
+}
+
+func (l *Logger) Info(f interface{}, v ...any) {
+	logEntry(l.entry, logrus.InfoLevel, formatLog(f, v...))
+}
+
+func (l *Logger) Warn(f interface{}, v ...any) {
+	logEntry(l.entry, logrus.WarnLevel, formatLog(f, v...))
+}
+
+func (l *Logger) Error(f interface{}, v ...any) {
+	logEntry(l.entry, logrus.ErrorLevel, formatLog(f, v...))
+}
+
+func (l *Logger) Trace(f interface{}, v ...any) {
+	logEntry(l.entry, logrus.TraceLevel, formatLog(f, v...))
+}
+
+// Fatal 输出日志后退出进程
+func (l *Logger) Fatal(f interface{}, v ...any) {
+	logEntry(l.entry, logrus.FatalLevel, formatLog(f, v...))
+}
+
+func (l *Logger) Panic(f interface{}, v ...any) {
+	logEntry(l.entry, logrus.PanicLevel, formatLog(f, v...))
+}
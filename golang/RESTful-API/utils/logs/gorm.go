@@ -0,0 +1,70 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+// GormLogger 将 gorm 的 SQL 日志写入 logs，并带上 context 中的 request id
+type GormLogger struct {
+	LogLevel      logger.LogLevel
+	SlowThreshold time.Duration
+}
+
+// NewGormLogger 创建 gorm 日志，debug 时输出全部 SQL，否则只输出慢查询和错误
+func NewGormLogger(debug bool, slowThreshold time.Duration) *GormLogger {
+	level := logger.Warn
+	if debug {
+		level = logger.Info
+	}
+	return &GormLogger{LogLevel: level, SlowThreshold: slowThreshold}
+}
+
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *l
+	newLogger.LogLevel = level
+	return &newLogger
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Info {
+		logEntry(ctxEntry(ctx), logrus.InfoLevel, formatLog(msg, data...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Warn {
+		logEntry(ctxEntry(ctx), logrus.WarnLevel, formatLog(msg, data...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Error {
+		logEntry(ctxEntry(ctx), logrus.ErrorLevel, formatLog(msg, data...))
+	}
+}
+
+// Trace 每条 SQL 执行后调用，记录耗时和影响行数，调用位置由格式化器定位到调用 gorm 的代码
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.LogLevel <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	ms := float64(elapsed.Nanoseconds()) / 1e6
+	switch {
+	case err != nil && l.LogLevel >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		logEntry(ctxEntry(ctx), logrus.ErrorLevel, formatLog("[gorm] [%.3fms] [rows:%d] %s error: %v", ms, rows, sql, err))
+	case l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.LogLevel >= logger.Warn:
+		sql, rows := fc()
+		logEntry(ctxEntry(ctx), logrus.WarnLevel, formatLog("[gorm] [%.3fms] [rows:%d] SLOW SQL >= %v %s", ms, rows, l.SlowThreshold, sql))
+	case l.LogLevel >= logger.Info:
+		sql, rows := fc()
+		logEntry(ctxEntry(ctx), logrus.InfoLevel, formatLog("[gorm] [%.3fms] [rows:%d] %s", ms, rows, sql))
+	}
+}
@@ -5,58 +5,36 @@ import (
 	"RESTful-API/utils/json"
 	"bytes"
 	"fmt"
-	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	"github.com/sirupsen/logrus"
-	"io"
-	"os"
 	"path/filepath"
-	"runtime"
+	"sort"
 	"strings"
-	"time"
+	"sync"
 )
 
 var (
-	log = logrus.New()
+	log          = logrus.New()
+	exitHookOnce sync.Once
 )
 
+// InitLog 按 log.ini 初始化日志，输出目标见 initSinks
 func InitLog() {
-	//日志路径
-	logPath := config.GetConfig("log.filePath").String()
-	logName := fmt.Sprintf("%s.", logPath)
-	//日志最大生存时间
-	logAgeDays, _ := config.GetConfig("log.maxAge").Int()
 	//日志级别
 	logLevel, _ := logrus.ParseLevel(config.GetConfig("log.level").String())
 	//日志模式
 	logMode := config.GetConfig("log.mode").String()
 	//日志格式
 	logFormat := config.GetConfig("log.format").MustString("text")
-	log.SetLevel(logLevel)
-	r, _ := rotatelogs.New(logName + "%Y-%m-%d" + ".log")
-
-	var mw io.Writer
-	switch logMode {
-	case "console":
-		mw = io.MultiWriter(os.Stdout)
-	case "file":
-		mw = io.MultiWriter(r)
-	case "file|console":
-		fallthrough
-	case "console|file":
-		mw = io.MultiWriter(os.Stdout, r)
-	default:
-		mw = io.MultiWriter(os.Stdout)
-	}
-	//日志保存时间
-	logAge := time.Duration(logAgeDays) * time.Second * 86400
-	rotatelogs.WithMaxAge(logAge)
-	log.SetOutput(mw)
 	log.SetReportCaller(true)
-	if logFormat == "json" {
-		log.SetFormatter(&MyJsonTextFormatter{})
-	} else {
-		log.SetFormatter(&MyTextFormatter{})
-	}
+	initSinks(logMode, logLevel, logFormat)
+	// Fatal 会直接退出进程，退出前刷新并关闭 Sink，避免远程日志中丢失最后的错误
+	exitHookOnce.Do(func() {
+		logrus.RegisterExitHandler(Close)
+	})
+}
+
+func Debug(f interface{}, v ...any) {
+	log.Debug(formatLog(f, v...))
 }
 
 func Info(f interface{}, v ...any) {
@@ -79,7 +57,14 @@ func Panic(f interface{}, v ...any) {
 	log.Panic(formatLog(f, v...))
 }
 
-type MyTextFormatter struct{}
+// Fatal 输出日志后退出进程，退出前刷新所有 Sink
+func Fatal(f interface{}, v ...any) {
+	log.Fatal(formatLog(f, v...))
+}
+
+type MyTextFormatter struct {
+	NoColor bool // 输出到文件、syslog 等非终端时不带颜色
+}
 
 func (m *MyTextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	var b *bytes.Buffer
@@ -94,13 +79,34 @@ func (m *MyTextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 		logContent string
 	)
 
+	level := entry.Level.String()
+	if !m.NoColor {
+		level = ColorLevel(level)
+	}
+
+	message := entry.Message
+	if requestID, ok := entry.Data[RequestIDField]; ok {
+		message = fmt.Sprintf("[%s=%v] %s", RequestIDField, requestID, message)
+	}
+	// 其余字段按 key 排序追加在消息后面
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		if k != RequestIDField {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		message += fmt.Sprintf(" %s=%v", k, entry.Data[k])
+	}
+
 	//HasCaller()为true才会有调用信息
 	if entry.HasCaller() {
-		_, fileName, line, _ := runtime.Caller(7)
+		fileName, line, _ := caller()
 		fName := filepath.Base(fileName)
-		logContent = fmt.Sprintf("[%s] [%s] [%s:%d] %s\n", timestamp, ColorLevel(entry.Level.String()), fName, line, entry.Message)
+		logContent = fmt.Sprintf("[%s] [%s] [%s:%d] %s\n", timestamp, level, fName, line, message)
 	} else {
-		logContent = fmt.Sprintf("[%s] [%s] %s\n", timestamp, ColorLevel(entry.Level.String()), entry.Message)
+		logContent = fmt.Sprintf("[%s] [%s] %s\n", timestamp, level, message)
 	}
 
 	b.WriteString(logContent)
@@ -120,12 +126,24 @@ func (m *MyJsonTextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 
 	timestamp := entry.Time.Format("2006-01-02 15:04:05.000")
 	var (
-		logContent = make(map[string]string)
+		logContent = make(map[string]interface{}, len(entry.Data)+4)
 	)
 
+	// 先写入字段，保留原始类型；与固定字段重名时加上 fields. 前缀
+	for k, v := range entry.Data {
+		switch k {
+		case "time", "level", "file", "msg":
+			k = "fields." + k
+		}
+		if err, ok := v.(error); ok { // error 直接 json.Marshal 会变成 {}
+			v = err.Error()
+		}
+		logContent[k] = v
+	}
+
 	//HasCaller()为true才会有调用信息
 	if entry.HasCaller() {
-		_, fileName, line, _ := runtime.Caller(7)
+		fileName, line, _ := caller()
 		fName := filepath.Base(fileName)
 		logContent["time"] = timestamp
 		logContent["level"] = entry.Level.String()
@@ -0,0 +1,102 @@
+package logs
+
+import (
+	"compress/gzip"
+	"fmt"
+	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RotateOptions 日志文件切割参数
+type RotateOptions struct {
+	MaxAge   time.Duration // 旧日志保留时长
+	MaxSize  int64         // 单个文件最大字节数，0 表示只按天切割
+	Compress bool          // 是否 gzip 压缩切割下来的旧日志
+}
+
+// newRotateWriter 按天切割日志，超过 MaxSize 时同一天内再按大小切割为 xxx.log.1、xxx.log.2 ...
+func newRotateWriter(pattern string, opts RotateOptions) (*rotatelogs.RotateLogs, error) {
+	options := []rotatelogs.Option{rotatelogs.WithRotationTime(24 * time.Hour)}
+	if opts.MaxAge > 0 {
+		options = append(options, rotatelogs.WithMaxAge(opts.MaxAge))
+	}
+	if opts.MaxSize > 0 {
+		options = append(options, rotatelogs.WithRotationSize(opts.MaxSize))
+	}
+	if opts.Compress {
+		options = append(options, rotatelogs.WithHandler(&compressHandler{pattern: pattern, maxAge: opts.MaxAge}))
+	}
+	return rotatelogs.New(pattern, options...)
+}
+
+// compressHandler 切割后压缩上一个日志文件，并清理过期的压缩文件。
+// rotatelogs 只会清理与 pattern 完全匹配的文件，.gz 文件需要自己清理。
+type compressHandler struct {
+	pattern string
+	maxAge  time.Duration
+}
+
+func (h *compressHandler) Handle(e rotatelogs.Event) {
+	event, ok := e.(*rotatelogs.FileRotatedEvent)
+	if !ok || event.PreviousFile() == "" {
+		return
+	}
+	if err := gzipFile(event.PreviousFile()); err != nil {
+		fmt.Fprintf(os.Stderr, "compress log file %s error: %v\n", event.PreviousFile(), err)
+	}
+	h.cleanup()
+}
+
+// cleanup 删除超过保留时长的压缩文件
+func (h *compressHandler) cleanup() {
+	if h.maxAge <= 0 {
+		return
+	}
+	dir := filepath.Dir(h.pattern)
+	prefix := filepath.Base(h.pattern)
+	if i := strings.Index(prefix, "%"); i >= 0 {
+		prefix = prefix[:i]
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, prefix+"*.gz"))
+	cutoff := time.Now().Add(-h.maxAge)
+	for _, path := range matches {
+		if fi, err := os.Stat(path); err == nil && fi.ModTime().Before(cutoff) {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// gzipFile 将文件压缩为 .gz 并删除原文件，压缩文件保留原文件的修改时间，方便按时间清理
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	_ = os.Chtimes(path+".gz", fi.ModTime(), fi.ModTime())
+	return os.Remove(path)
+}
@@ -0,0 +1,108 @@
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFor 轮询等待 cond 成立，rotatelogs 的清理和压缩都在后台协程中执行
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestRotateBySizeAndCompress(t *testing.T) {
+	dir := t.TempDir()
+	w, err := newRotateWriter(filepath.Join(dir, "app.log.%Y-%m-%d.log"), RotateOptions{
+		MaxAge:   24 * time.Hour,
+		MaxSize:  100,
+		Compress: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	first := bytes.Repeat([]byte("a"), 150)
+	if _, err = w.Write(first); err != nil {
+		t.Fatal(err)
+	}
+	base := w.CurrentFileName()
+	// 超过 MaxSize 后同一天切割出 .1，上一个文件被压缩
+	if _, err = w.Write([]byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if w.CurrentFileName() != base+".1" {
+		t.Fatalf("current file %s, want %s.1", w.CurrentFileName(), base)
+	}
+	waitFor(t, "compressed log", func() bool { return exists(base+".gz") && !exists(base) })
+
+	f, err := os.Open(base + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(zr); err != nil || !bytes.Equal(data, first) {
+		t.Fatalf("decompressed %d bytes, %v", len(data), err)
+	}
+}
+
+func TestRotateMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	expired := filepath.Join(dir, "app.log.2000-01-01.log")
+	expiredGz := filepath.Join(dir, "app.log.2000-01-02.log.gz")
+	recentGz := filepath.Join(dir, "app.log.2000-01-03.log.gz")
+	for _, path := range []string{expired, expiredGz, recentGz} {
+		if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if path != recentGz {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	w, err := newRotateWriter(filepath.Join(dir, "app.log.%Y-%m-%d.log"), RotateOptions{
+		MaxAge:   24 * time.Hour,
+		MaxSize:  10,
+		Compress: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	for i := 0; i < 2; i++ {
+		if _, err = w.Write([]byte(strings.Repeat("x", 20))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 过期的日志和压缩文件都被删除，未过期的保留
+	waitFor(t, "expired logs removed", func() bool { return !exists(expired) && !exists(expiredGz) })
+	if !exists(recentGz) {
+		t.Fatal("recent compressed log should be kept")
+	}
+}
@@ -0,0 +1,202 @@
+package logs
+
+import (
+	"RESTful-API/utils/config"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink 日志输出目标，每个 Sink 可以单独配置级别和格式
+type Sink interface {
+	// Write 写入一条已格式化的日志
+	Write(level logrus.Level, p []byte) error
+	// Close 刷新缓冲并释放资源
+	Close() error
+}
+
+var (
+	sinksMu    sync.Mutex
+	sinks      []Sink
+	memorySink *MemorySink
+)
+
+// sinkHook 将 Sink 包装成 logrus hook，按自己的级别和格式输出
+type sinkHook struct {
+	sink      Sink
+	levels    []logrus.Level
+	formatter logrus.Formatter
+}
+
+func (h *sinkHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+func (h *sinkHook) Fire(entry *logrus.Entry) error {
+	e := *entry
+	e.Buffer = nil // entry.Buffer 是 logrus 主输出使用的缓冲区，各个 Sink 不能共用
+	p, err := h.formatter.Format(&e)
+	if err != nil {
+		return err
+	}
+	return h.sink.Write(entry.Level, p)
+}
+
+// discardFormatter 日志全部由 Sink 输出，logrus 自身的输出不再需要格式化
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+// writerSink 输出到 io.Writer，用于控制台和文件
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *writerSink) Write(_ logrus.Level, p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(p)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok && s.w != os.Stdout && s.w != os.Stderr {
+		return c.Close()
+	}
+	return nil
+}
+
+// Memory 返回 log.sinks 中配置的内存环形缓冲区，没有配置时返回 nil
+func Memory() *MemorySink {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	return memorySink
+}
+
+// Close 刷新并关闭所有 Sink，服务退出前调用，避免远程日志丢失
+func Close() {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "close log sink error: %v\n", err)
+		}
+	}
+	sinks = nil
+	memorySink = nil
+}
+
+// initSinks 按 log.sinks 创建输出，没有配置时兼容旧的 log.mode
+func initSinks(logMode string, defaultLevel logrus.Level, defaultFormat string) {
+	names := config.GetConfig("log.sinks").Strings(",")
+	if len(names) == 0 {
+		names = []string{"console"}
+		switch logMode {
+		case "file":
+			names = []string{"file"}
+		case "file|console", "console|file":
+			names = []string{"console", "file"}
+		}
+	}
+
+	Close()
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+
+	hooks := make(logrus.LevelHooks)
+	maxLevel := logrus.PanicLevel
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := "log.sink." + name + "."
+		level, err := logrus.ParseLevel(config.GetConfig(key + "level").MustString(defaultLevel.String()))
+		if err != nil {
+			level = defaultLevel
+		}
+		format := config.GetConfig(key + "format").MustString(defaultFormat)
+
+		sink, err := newSink(name, key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "init log sink %s error: %v\n", name, err)
+			continue
+		}
+		if name == "http" {
+			format = "json" // 远程收集端统一按 json 解析
+		}
+		hooks.Add(&sinkHook{sink: sink, levels: logrus.AllLevels[:level+1], formatter: newFormatter(format, name == "console")})
+		sinks = append(sinks, sink)
+		if level > maxLevel {
+			maxLevel = level
+		}
+	}
+
+	// 所有输出都创建失败时退回到控制台，保证日志不丢
+	if len(sinks) == 0 {
+		sink := &writerSink{w: os.Stdout}
+		hooks.Add(&sinkHook{sink: sink, levels: logrus.AllLevels[:defaultLevel+1], formatter: newFormatter(defaultFormat, true)})
+		sinks = append(sinks, sink)
+		maxLevel = defaultLevel
+	}
+
+	log.ReplaceHooks(hooks)
+	log.SetOutput(io.Discard)
+	log.SetFormatter(discardFormatter{})
+	log.SetLevel(maxLevel) // logrus 按最详细的级别放行，再由各个 Sink 自己过滤
+}
+
+// newSink 按名称创建 Sink，key 为该 Sink 的配置前缀
+func newSink(name, key string) (Sink, error) {
+	switch name {
+	case "console":
+		return &writerSink{w: os.Stdout}, nil
+	case "file":
+		logPath := config.GetConfig("log.filePath").String()
+		w, err := newRotateWriter(logPath+".%Y-%m-%d.log", RotateOptions{
+			MaxAge:   time.Duration(config.GetConfig("log.maxAge").MustInt(7)) * 24 * time.Hour,
+			MaxSize:  config.GetConfig("log.maxSize").MustInt64(0) * 1024 * 1024,
+			Compress: config.GetConfig("log.compress").MustBool(false),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &writerSink{w: w}, nil
+	case "syslog":
+		return NewSyslogSink(
+			config.GetConfig(key+"network").String(),
+			config.GetConfig(key+"addr").String(),
+			config.GetConfig(key+"tag").MustString("RESTful-API"),
+		)
+	case "http":
+		headers := make(map[string]string)
+		if token := config.GetConfig(key + "token").String(); token != "" {
+			headers["Authorization"] = "Bearer " + token
+		}
+		return NewHTTPSink(HTTPSinkOptions{
+			URL:           config.GetConfig(key + "url").String(),
+			BatchSize:     config.GetConfig(key + "batchSize").MustInt(100),
+			FlushInterval: time.Duration(config.GetConfig(key+"flushInterval").MustInt(2000)) * time.Millisecond,
+			QueueSize:     config.GetConfig(key + "queueSize").MustInt(10000),
+			MaxRetry:      config.GetConfig(key + "maxRetry").MustInt(3),
+			Timeout:       time.Duration(config.GetConfig(key+"timeout").MustInt(5000)) * time.Millisecond,
+			Headers:       headers,
+		})
+	case "memory":
+		memorySink = NewMemorySink(config.GetConfig(key + "size").MustInt(1000))
+		return memorySink, nil
+	}
+	return nil, fmt.Errorf("unknown log sink %q", name)
+}
+
+// newFormatter 按格式名创建格式化器，只有控制台输出带颜色
+func newFormatter(format string, color bool) logrus.Formatter {
+	if format == "json" {
+		return &MyJsonTextFormatter{}
+	}
+	return &MyTextFormatter{NoColor: !color}
+}
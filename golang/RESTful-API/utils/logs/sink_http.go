@@ -0,0 +1,191 @@
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPSinkOptions 远程日志收集参数
+type HTTPSinkOptions struct {
+	URL           string            // 收集端地址，日志以 json 数组 POST
+	BatchSize     int               // 每批最多条数
+	FlushInterval time.Duration     // 不满一批时的最长等待时间
+	QueueSize     int               // 待发送队列长度，队列满时丢弃新日志，不阻塞业务
+	MaxRetry      int               // 发送失败的重试次数
+	RetryBackoff  time.Duration     // 首次重试间隔，之后每次翻倍
+	Timeout       time.Duration     // 单次请求超时
+	Headers       map[string]string // 额外的请求头，例如鉴权
+	Client        *http.Client
+}
+
+// HTTPSink 将 json 格式的日志批量发送到远程收集端
+type HTTPSink struct {
+	opts    HTTPSinkOptions
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan []byte
+	wg      sync.WaitGroup
+	dropped atomic.Uint64 // 因队列满或重试失败丢弃的条数
+}
+
+// NewHTTPSink 创建并启动发送协程
+func NewHTTPSink(opts HTTPSinkOptions) (*HTTPSink, error) {
+	if opts.URL == "" {
+		return nil, errors.New("http log sink url is empty")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 2 * time.Second
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10000
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 500 * time.Millisecond
+	}
+	if opts.Client == nil {
+		timeout := opts.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		opts.Client = &http.Client{Timeout: timeout}
+	}
+
+	s := &HTTPSink{opts: opts, queue: make(chan []byte, opts.QueueSize)}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Write 放入发送队列，队列满时直接丢弃
+func (s *HTTPSink) Write(_ logrus.Level, p []byte) error {
+	line := bytes.TrimRight(p, "\n")
+	if len(line) == 0 {
+		return nil
+	}
+	buf := make([]byte, len(line))
+	copy(buf, line)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil
+	}
+	select {
+	case s.queue <- buf:
+	default:
+		s.dropped.Add(1)
+	}
+	return nil
+}
+
+// Close 停止接收新日志，发送完队列中剩余的日志后返回
+func (s *HTTPSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// Dropped 返回累计丢弃的日志条数
+func (s *HTTPSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// run 攒批发送：满 BatchSize 或到 FlushInterval 时发送一次
+func (s *HTTPSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.opts.BatchSize)
+	for {
+		select {
+		case line, ok := <-s.queue:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, line)
+			if len(batch) >= s.opts.BatchSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush 发送一批日志，失败时按指数退避重试，仍然失败则丢弃。
+// 重试期间不再消费队列，队列写满后新日志被丢弃，形成背压。
+func (s *HTTPSink) flush(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+	body := make([]byte, 0, 2+len(batch)*256)
+	body = append(body, '[')
+	body = append(body, bytes.Join(batch, []byte{','})...)
+	body = append(body, ']')
+
+	backoff := s.opts.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= s.opts.MaxRetry; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var retry bool
+		if retry, err = s.send(body); err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		total := s.dropped.Add(uint64(len(batch)))
+		// 日志系统自身的错误只能写到 stderr，避免递归写日志
+		fmt.Fprintf(os.Stderr, "ship %d logs to %s error: %v, dropped %d in total\n", len(batch), s.opts.URL, err, total)
+	}
+}
+
+// send 发送一次请求，返回是否值得重试
+func (s *HTTPSink) send(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// 4xx 说明请求本身有问题，重试也不会成功；429 除外
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("collector responded %s", resp.Status)
+}
@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+)
+
+// MemorySink 固定容量的内存环形缓冲区，保留最近的日志供管理接口查看
+type MemorySink struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+// NewMemorySink 创建容量为 size 条的环形缓冲区
+func NewMemorySink(size int) *MemorySink {
+	if size <= 0 {
+		size = 1000
+	}
+	return &MemorySink{lines: make([]string, size)}
+}
+
+func (s *MemorySink) Write(_ logrus.Level, p []byte) error {
+	line := strings.TrimRight(string(p), "\n")
+	s.mu.Lock()
+	s.lines[s.next] = line
+	s.next = (s.next + 1) % len(s.lines)
+	if s.next == 0 {
+		s.full = true
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemorySink) Close() error {
+	return nil
+}
+
+// Tail 返回最近的 n 条日志，按时间从旧到新排列
+func (s *MemorySink) Tail(n int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := s.next
+	if s.full {
+		size = len(s.lines)
+	}
+	if n <= 0 || n > size {
+		n = size
+	}
+	result := make([]string, 0, n)
+	for i := n; i > 0; i-- {
+		result = append(result, s.lines[(s.next-i+len(s.lines))%len(s.lines)])
+	}
+	return result
+}
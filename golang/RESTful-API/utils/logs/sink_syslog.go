@@ -0,0 +1,42 @@
+//go:build !windows && !plan9
+
+package logs
+
+import (
+	"github.com/sirupsen/logrus"
+	"log/syslog"
+)
+
+// syslogSink 输出到本机或远程 syslog，按日志级别映射 syslog 优先级
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSink 连接 syslog，network 和 addr 为空时使用本机 syslog
+func NewSyslogSink(network, addr, tag string) (Sink, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) Write(level logrus.Level, p []byte) error {
+	msg := string(p)
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return s.w.Crit(msg)
+	case logrus.ErrorLevel:
+		return s.w.Err(msg)
+	case logrus.WarnLevel:
+		return s.w.Warning(msg)
+	case logrus.InfoLevel:
+		return s.w.Info(msg)
+	default:
+		return s.w.Debug(msg)
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package logs
+
+import "errors"
+
+// NewSyslogSink 当前平台不支持 syslog
+func NewSyslogSink(network, addr, tag string) (Sink, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,180 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector 本地日志收集端，按 statuses 依次返回状态码，用完后返回 200
+type collector struct {
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	batches  [][]map[string]interface{}
+	auth     string
+	release  chan struct{} // 非 nil 时请求阻塞到 release 关闭
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.release != nil {
+		<-c.release
+	}
+	var batch []map[string]interface{}
+	err := json.NewDecoder(r.Body).Decode(&batch)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	c.auth = r.Header.Get("Authorization")
+	if len(c.statuses) > 0 {
+		status := c.statuses[0]
+		c.statuses = c.statuses[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.batches = append(c.batches, batch)
+}
+
+func (c *collector) snapshot() (requests int, batches [][]map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests, append([][]map[string]interface{}(nil), c.batches...)
+}
+
+func newTestHTTPSink(t *testing.T, c *collector, opts HTTPSinkOptions) *HTTPSink {
+	t.Helper()
+	server := httptest.NewServer(c)
+	t.Cleanup(server.Close)
+	opts.URL = server.URL
+	opts.RetryBackoff = time.Millisecond
+	sink, err := NewHTTPSink(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink
+}
+
+func writeLines(t *testing.T, sink Sink, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := sink.Write(logrus.InfoLevel, []byte(fmt.Sprintf(`{"msg":"line %d"}`+"\n", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHTTPSinkBatches(t *testing.T) {
+	c := &collector{}
+	sink := newTestHTTPSink(t, c, HTTPSinkOptions{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Headers:       map[string]string{"Authorization": "Bearer token"},
+	})
+	writeLines(t, sink, 5)
+	// Close 发送完队列中不满一批的日志
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	requests, batches := c.snapshot()
+	if requests != 3 || len(batches) != 3 || len(batches[0]) != 2 || len(batches[2]) != 1 {
+		t.Fatalf("got %d requests, batches %v", requests, batches)
+	}
+	if batches[2][0]["msg"] != "line 4" || c.auth != "Bearer token" {
+		t.Fatalf("last batch %v, auth %q", batches[2], c.auth)
+	}
+	// 关闭后写入直接忽略
+	writeLines(t, sink, 1)
+	if sink.Dropped() != 0 {
+		t.Fatalf("dropped %d", sink.Dropped())
+	}
+}
+
+func TestHTTPSinkFlushInterval(t *testing.T) {
+	c := &collector{}
+	sink := newTestHTTPSink(t, c, HTTPSinkOptions{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer sink.Close()
+	writeLines(t, sink, 1)
+	waitFor(t, "flush by interval", func() bool {
+		_, batches := c.snapshot()
+		return len(batches) == 1
+	})
+}
+
+func TestHTTPSinkRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+		dropped  uint64
+	}{
+		{"server error then ok", []int{500, 503}, 3, 0},
+		{"rate limited", []int{429}, 2, 0},
+		{"retries exhausted", []int{500, 500, 500}, 3, 1},
+		{"client error not retried", []int{400}, 1, 1},
+	}
+	for _, tt := range tests {
+		c := &collector{statuses: tt.statuses}
+		sink := newTestHTTPSink(t, c, HTTPSinkOptions{MaxRetry: 2, FlushInterval: time.Hour})
+		writeLines(t, sink, 1)
+		_ = sink.Close()
+		if requests, _ := c.snapshot(); requests != tt.requests || sink.Dropped() != tt.dropped {
+			t.Errorf("%s: %d requests, %d dropped; want %d, %d", tt.name, requests, sink.Dropped(), tt.requests, tt.dropped)
+		}
+	}
+}
+
+// TestHTTPSinkBackpressure 收集端卡住时队列写满，新日志被丢弃而不是阻塞调用方
+func TestHTTPSinkBackpressure(t *testing.T) {
+	c := &collector{release: make(chan struct{})}
+	sink := newTestHTTPSink(t, c, HTTPSinkOptions{BatchSize: 1, QueueSize: 2, FlushInterval: time.Hour})
+
+	writeLines(t, sink, 1)
+	// 等第一条被取出发送，之后只有 2 条能进入队列
+	waitFor(t, "first batch in flight", func() bool { return len(sink.queue) == 0 })
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			_ = sink.Write(logrus.InfoLevel, []byte(`{"msg":"blocked"}`))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Write blocked")
+	}
+	close(c.release)
+	_ = sink.Close()
+
+	if requests, batches := c.snapshot(); requests != 3 || len(batches) != 3 || sink.Dropped() != 3 {
+		t.Fatalf("%d requests, %d batches, %d dropped", requests, len(batches), sink.Dropped())
+	}
+}
+
+func TestMemorySinkTail(t *testing.T) {
+	mem := NewMemorySink(3)
+	if got := mem.Tail(10); len(got) != 0 {
+		t.Fatalf("empty sink returned %q", got)
+	}
+	writeLines(t, mem, 5)
+	got := mem.Tail(0)
+	if len(got) != 3 || got[0] != `{"msg":"line 2"}` || got[2] != `{"msg":"line 4"}` {
+		t.Fatalf("Tail(0) = %q", got)
+	}
+	if got = mem.Tail(1); len(got) != 1 || got[0] != `{"msg":"line 4"}` {
+		t.Fatalf("Tail(1) = %q", got)
+	}
+}
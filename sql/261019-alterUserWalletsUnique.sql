@@ -0,0 +1,4 @@
+-- 同一个链上地址全局只能绑定一个用户
+ALTER TABLE user_wallets
+    ADD UNIQUE uniq_chain_wallet_address (chain, wallet_address),
+    ADD COLUMN created_at DATETIME DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,2 @@
+-- 没有邮箱的用户统一存为 NULL：email 有唯一索引，空字符串只能出现一次，多个 NULL 不冲突
+UPDATE users SET email = NULL WHERE email = '';
@@ -0,0 +1,4 @@
+-- 用户表增加密码错误次数和锁定时间，用于登录失败后的渐进式锁定
+ALTER TABLE users
+    ADD COLUMN password_try INT UNSIGNED NOT NULL DEFAULT 0 AFTER password_hash,  -- 连续密码错误次数
+    ADD COLUMN locked_until DATETIME NULL AFTER password_try;  -- 锁定截止时间
@@ -0,0 +1,4 @@
+-- 用户表增加角色和状态，用于权限控制
+ALTER TABLE users
+    ADD COLUMN auth_group VARCHAR(20) NOT NULL DEFAULT 'user',  -- 角色：user/creator/moderator/admin
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active';  -- 状态：active/disabled
@@ -0,0 +1,13 @@
+-- 8. 链上索引进度表：每个合约已处理到的高度
+CREATE TABLE indexer_checkpoints (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    chain ENUM('EVM', 'TON', 'Solana') NOT NULL,  -- 所属区块链
+    contract_address VARCHAR(255) NOT NULL,  -- 合约地址
+    block_number BIGINT UNSIGNED NOT NULL DEFAULT 0,  -- 已处理到的区块（含）
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE uniq_chain_contract (chain, contract_address)
+);
+
+-- 同一条链上的同一个 NFT 只有一条记录，索引器按该键更新拥有者
+ALTER TABLE nfts
+    ADD UNIQUE uniq_chain_contract_token (chain, contract_address, token_id);
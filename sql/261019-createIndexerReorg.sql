@@ -0,0 +1,37 @@
+-- 链重组处理：记录已索引区块的哈希和 NFT 转移历史，交易记录带上所在区块
+ALTER TABLE indexer_checkpoints
+    ADD COLUMN block_hash VARCHAR(100) NOT NULL DEFAULT '' AFTER block_number;  -- 已处理到的区块哈希
+
+-- 9. 已索引区块的哈希，只保留最近一段，用于查找分叉点
+CREATE TABLE indexer_blocks (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    chain ENUM('EVM', 'TON', 'Solana') NOT NULL,  -- 所属区块链
+    block_number BIGINT UNSIGNED NOT NULL,  -- 区块号
+    block_hash VARCHAR(100) NOT NULL,  -- 区块哈希
+    UNIQUE uniq_chain_block (chain, block_number)
+);
+
+-- 10. NFT 转移记录：回滚后按剩余的最后一次转移恢复拥有者
+CREATE TABLE nft_transfers (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    chain ENUM('EVM', 'TON', 'Solana') NOT NULL,  -- 所属区块链
+    contract_address VARCHAR(255) NOT NULL,  -- 合约地址
+    token_id VARCHAR(255) NOT NULL,  -- NFT唯一ID
+    from_address VARCHAR(255),  -- 转出地址，为空表示铸造
+    to_address VARCHAR(255),  -- 转入地址，为空表示销毁
+    block_number BIGINT UNSIGNED NOT NULL,  -- 所在区块
+    block_hash VARCHAR(100) NOT NULL,  -- 所在区块哈希
+    tx_hash VARCHAR(255) NOT NULL,  -- 链上交易哈希
+    log_index INT UNSIGNED NOT NULL,  -- 日志序号
+    UNIQUE uniq_chain_tx_log_token (chain, tx_hash, log_index, token_id),
+    INDEX idx_chain_block (chain, block_number),
+    INDEX idx_token (chain, contract_address, token_id)
+);
+
+ALTER TABLE nft_transactions
+    ADD COLUMN block_number BIGINT UNSIGNED AFTER chain,  -- 交易所在区块，链重组时按区块回滚
+    ADD INDEX idx_chain_block (chain, block_number);
+
+ALTER TABLE swap_transactions
+    ADD COLUMN block_number BIGINT UNSIGNED AFTER chain,  -- 交易所在区块，链重组时按区块回滚
+    ADD INDEX idx_chain_block (chain, block_number);
@@ -0,0 +1,15 @@
+-- 11. NFT 元数据表：metadata_uri 抓取并校验后的规范化副本，每个 NFT 一条记录
+CREATE TABLE nft_metadata (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    nft_id BIGINT UNIQUE NOT NULL,  -- 关联 nfts.id
+    metadata_uri VARCHAR(500) NOT NULL,  -- 抓取时的 metadata_uri，变化后重新抓取
+    content_hash CHAR(64),  -- content 的 sha256（hex）
+    name VARCHAR(255),
+    description TEXT,
+    image VARCHAR(2048),
+    content MEDIUMTEXT,  -- 规范化后的元数据 JSON
+    fetch_error VARCHAR(500),  -- 最近一次抓取失败的原因，成功时为空
+    fetched_at DATETIME NOT NULL,  -- 最近一次抓取的时间
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+    -- FOREIGN KEY (nft_id) REFERENCES nfts(id) ON DELETE CASCADE
+);
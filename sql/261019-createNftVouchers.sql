@@ -0,0 +1,22 @@
+-- 12. 懒铸造：草稿 NFT 在铸造之前没有 token_id，签发的铸造凭证记录预留的 tokenId
+ALTER TABLE nfts
+    MODIFY token_id VARCHAR(255) NULL;  -- 草稿为 NULL，链上铸造后由索引器写入
+
+CREATE TABLE nft_vouchers (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    nft_id BIGINT NOT NULL,  -- 草稿 NFT
+    chain ENUM('EVM', 'TON', 'Solana') NOT NULL,  -- 所属区块链
+    contract_address VARCHAR(255) NOT NULL,  -- 懒铸造合约，即 EIP-712 的 verifyingContract
+    token_id VARCHAR(255) NOT NULL,  -- 预留的 tokenId：高 160 位为创作者地址，低 96 位为草稿 id
+    creator_user_id BIGINT NOT NULL,  -- 创作者
+    creator_wallet_address VARCHAR(255) NOT NULL,  -- 创作者钱包，接收铸造款
+    metadata_uri VARCHAR(500) NOT NULL,  -- 已固定的元数据地址
+    min_price VARCHAR(78) NOT NULL,  -- 最低铸造价格，单位 wei
+    signer_address VARCHAR(255) NOT NULL,  -- 签发凭证的地址
+    signature VARCHAR(132) NOT NULL,  -- EIP-712 签名 r||s||v
+    status ENUM('pending', 'minted') NOT NULL DEFAULT 'pending',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE uniq_nft (nft_id),
+    UNIQUE uniq_chain_contract_token (chain, contract_address, token_id)
+);
@@ -0,0 +1,14 @@
+-- 7. 刷新令牌表：记录签发过的 refresh token，用于轮换和重放检测
+CREATE TABLE user_refresh_tokens (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,  -- 关联用户
+    token_id VARCHAR(64) UNIQUE NOT NULL,  -- refresh token 的 jti
+    family_id VARCHAR(64) NOT NULL,  -- 同一次登录轮换出来的 token 属于同一个 family
+    replaced_by VARCHAR(64),  -- 轮换后新 token 的 jti
+    expires_at DATETIME NOT NULL,  -- 过期时间
+    session_expires_at DATETIME NOT NULL,  -- 所在 family 的最长有效期
+    revoked_at DATETIME,  -- 吊销时间，为空表示仍然有效
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_family_id (family_id)
+    -- FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);